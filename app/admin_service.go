@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	infra "user-backend/infra"
+)
+
+// AdminService - モデレーター向けの管理API
+// ルートは管理者ロールを持つリクエストのみ通す（openapi.AdminAPIController.Middleware）
+type AdminService struct {
+	openapi.AdminAPIService
+	db *infra.DynamoDBClient
+	// 削除した意見の添付ファイルを削除するストレージ（nilの場合は削除しない）
+	storage *infra.S3Client
+}
+
+func NewAdminService(db *infra.DynamoDBClient, storage *infra.S3Client) *AdminService {
+	return &AdminService{db: db, storage: storage}
+}
+
+// errUntilRequired - 期限を指定せずに利用停止にしようとした
+var errUntilRequired = openapi.NewValidationError(
+	"until_required",
+	"A future end time is required to suspend a user.",
+	openapi.FieldError{Field: "until", Code: "required", Message: "must be a future date-time for suspended"},
+)
+
+// errOpinionOnlyAction - 意見にのみできる操作（承認・却下）をコメントに指定した
+var errOpinionOnlyAction = openapi.NewValidationError(
+	"opinion_only_action",
+	"Only opinions can be approved or rejected.",
+	openapi.FieldError{Field: "action", Code: "enum", Message: "must be hide, unhide or delete for comments"},
+)
+
+// GetAdminReports - 通報キュー取得API
+// 対応待ちの通報された意見・コメントを最終通報日時の新しい順に返す
+func (s *AdminService) GetAdminReports(ctx context.Context, limit int32, cursor string) (openapi.ImplResponse, error) {
+	page, err := s.db.ListReportedItems(ctx, limit, cursor)
+	if errors.Is(err, infra.ErrInvalidCursor) {
+		return openapi.Response(400, nil), &openapi.ParsingError{Err: errors.New("cursor is invalid")}
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	response := openapi.Response(200, page.Items)
+	if page.NextCursor != "" {
+		response.Headers = http.Header{openapi.NextCursorHeader: []string{page.NextCursor}}
+	}
+	return response, nil
+}
+
+// PostAdminOpinionModeration - 意見モデレーションAPI
+func (s *AdminService) PostAdminOpinionModeration(ctx context.Context, opinionId string, moderationRequest openapi.ModerationRequest) (openapi.ImplResponse, error) {
+	return s.moderate(ctx, infra.OpinionReportTarget(opinionId), moderationRequest)
+}
+
+// PostAdminCommentModeration - コメントモデレーションAPI
+func (s *AdminService) PostAdminCommentModeration(ctx context.Context, opinionId string, commentId string, moderationRequest openapi.ModerationRequest) (openapi.ImplResponse, error) {
+	return s.moderate(ctx, infra.CommentReportTarget(opinionId, commentId), moderationRequest)
+}
+
+// moderate - 意見・コメントを非表示・表示・削除し、操作履歴を残す
+// 削除した場合は削除前の本文を履歴に残す
+func (s *AdminService) moderate(ctx context.Context, target infra.ReportTarget, moderationRequest openapi.ModerationRequest) (openapi.ImplResponse, error) {
+	action := infra.ModerationAction{
+		TargetType:    target.Type,
+		OpinionID:     target.OpinionID,
+		CommentID:     target.CommentID,
+		Action:        infra.ModerationActionType(moderationRequest.Action),
+		Actor:         openapi.ClaimsFromContext(ctx).Actor(),
+		Note:          moderationRequest.Note,
+		ActedDateTime: time.Now(),
+	}
+
+	var err error
+	switch action.Action {
+	case infra.ModerationHide, infra.ModerationUnhide:
+		err = s.db.SetHidden(ctx, target, action.Action == infra.ModerationHide)
+	case infra.ModerationDelete:
+		action.Content, err = s.delete(ctx, target)
+	case infra.ModerationApprove, infra.ModerationReject:
+		if target.Type != infra.ReportTargetOpinion {
+			return openapi.Response(422, nil), errOpinionOnlyAction
+		}
+		status := infra.OpinionPublished
+		if action.Action == infra.ModerationReject {
+			status = infra.OpinionRejected
+		}
+		err = s.db.SetOpinionStatus(ctx, target.OpinionID, status)
+	}
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
+	if errors.Is(err, infra.ErrCommentNotFound) {
+		return openapi.Response(404, nil), errCommentNotFound
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	if err := s.db.SaveModerationAction(ctx, action); err != nil {
+		return openapi.Response(500, nil), err
+	}
+	return openapi.Response(200, action), nil
+}
+
+// delete - 意見・コメントを削除し、削除前の本文を返す
+func (s *AdminService) delete(ctx context.Context, target infra.ReportTarget) (string, error) {
+	if target.Type == infra.ReportTargetComment {
+		comment, err := s.db.DeleteComment(ctx, target.OpinionID, target.CommentID)
+		return comment.Comment, err
+	}
+
+	opinion, err := s.db.DeleteOpinion(ctx, target.OpinionID)
+	if err != nil {
+		return "", err
+	}
+	// 添付ファイルは削除した意見とともに配信しない（削除に失敗しても意見の削除は取り消さない）
+	if s.storage != nil {
+		for _, attachment := range opinion.Attachments {
+			keys := []string{attachment.ObjectKey}
+			for _, key := range attachment.Variants {
+				keys = append(keys, key)
+			}
+			for _, key := range keys {
+				if err := s.storage.DeleteObject(ctx, key); err != nil {
+					log.Printf("failed to delete attachment object %s: %v", key, err)
+				}
+			}
+		}
+	}
+	return opinion.Opinion, nil
+}
+
+// GetAdminOpinionHistory - 意見モデレーション履歴取得API
+func (s *AdminService) GetAdminOpinionHistory(ctx context.Context, opinionId string) (openapi.ImplResponse, error) {
+	return s.history(ctx, infra.OpinionReportTarget(opinionId))
+}
+
+// GetAdminCommentHistory - コメントモデレーション履歴取得API
+func (s *AdminService) GetAdminCommentHistory(ctx context.Context, opinionId string, commentId string) (openapi.ImplResponse, error) {
+	return s.history(ctx, infra.CommentReportTarget(opinionId, commentId))
+}
+
+func (s *AdminService) history(ctx context.Context, target infra.ReportTarget) (openapi.ImplResponse, error) {
+	history, err := s.db.GetModerationHistory(ctx, target)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	return openapi.Response(200, history), nil
+}
+
+// PutAdminUserStatus - ユーザー利用状態変更API
+// 利用停止中のユーザーは意見・コメントの投稿、リアクション、通報、添付ファイルのアップロードができない
+func (s *AdminService) PutAdminUserStatus(ctx context.Context, userStatusRequest openapi.UserStatusRequest) (openapi.ImplResponse, error) {
+	now := time.Now()
+	status := infra.UserStatus{
+		MailAddress:     userStatusRequest.MailAddress,
+		Status:          infra.UserStatusType(userStatusRequest.Status),
+		Note:            userStatusRequest.Note,
+		UpdatedBy:       openapi.ClaimsFromContext(ctx).Actor(),
+		UpdatedDateTime: now,
+	}
+	switch status.Status {
+	case infra.UserSuspended:
+		if !userStatusRequest.Until.After(now) {
+			return openapi.Response(422, nil), errUntilRequired
+		}
+		status.Until = userStatusRequest.Until
+	case infra.UserActive:
+		// 利用中に戻す場合は記録を残さない（履歴には残す）
+		status = infra.UserStatus{MailAddress: status.MailAddress, Status: infra.UserActive}
+	}
+
+	if err := s.db.SetUserStatus(ctx, status); err != nil {
+		return openapi.Response(500, nil), err
+	}
+	err := s.db.SaveModerationAction(ctx, infra.ModerationAction{
+		TargetType:    infra.ReportTargetUser,
+		MailAddress:   userStatusRequest.MailAddress,
+		Action:        infra.ModerationStatus,
+		Status:        infra.UserStatusType(userStatusRequest.Status),
+		Actor:         openapi.ClaimsFromContext(ctx).Actor(),
+		Note:          userStatusRequest.Note,
+		ActedDateTime: now,
+	})
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	return openapi.Response(200, status), nil
+}
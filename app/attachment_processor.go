@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	imaging "user-backend/imaging"
+	infra "user-backend/infra"
+)
+
+// AttachmentProcessor - アップロードされた画像から配信用の画像を生成する
+// 向きを補正し、位置情報などのメタデータを除いた画像とサムネイルを保存して添付ファイルに記録する
+type AttachmentProcessor struct {
+	db      *infra.DynamoDBClient
+	storage *infra.S3Client
+}
+
+func NewAttachmentProcessor(db *infra.DynamoDBClient, storage *infra.S3Client) *AttachmentProcessor {
+	return &AttachmentProcessor{db: db, storage: storage}
+}
+
+// Process - 添付ファイルの画像を変換するメソッド
+// 検証に失敗した添付ファイルや画像として読み込めないファイルは変換せずに終了する
+// （アップロード完了通知APIが検証してファイルを削除する）
+func (p *AttachmentProcessor) Process(ctx context.Context, opinionId string, attachmentId string) error {
+	attachment, err := p.db.GetAttachment(ctx, opinionId, attachmentId)
+	if errors.Is(err, infra.ErrAttachmentNotFound) {
+		log.Printf("attachment %s/%s: not found", opinionId, attachmentId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if attachment.Status == infra.AttachmentRejected {
+		log.Printf("attachment %s/%s: rejected", opinionId, attachmentId)
+		return nil
+	}
+
+	data, err := p.storage.GetObject(ctx, attachment.ObjectKey, maxAttachmentSize+1)
+	if errors.Is(err, infra.ErrObjectNotFound) {
+		log.Printf("attachment %s/%s: object %s not found", opinionId, attachmentId, attachment.ObjectKey)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(data) > maxAttachmentSize {
+		log.Printf("attachment %s/%s: too large", opinionId, attachmentId)
+		return nil
+	}
+
+	variants, err := imaging.Process(data)
+	if err != nil {
+		log.Printf("attachment %s/%s: failed to process image: %v", opinionId, attachmentId, err)
+		return nil
+	}
+
+	keys := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		key := attachmentVariantKey(opinionId, attachmentId, variant.Name, variant.Extension)
+		if err := p.storage.PutObject(ctx, key, variant.ContentType, variant.Data); err != nil {
+			return err
+		}
+		keys[variant.Name] = key
+	}
+
+	err = p.db.SetAttachmentVariants(ctx, opinionId, attachmentId, keys)
+	if errors.Is(err, infra.ErrAttachmentNotFound) {
+		// 変換中に検証に失敗した場合は変換後の画像を残さない
+		for _, key := range keys {
+			if err := p.storage.DeleteObject(ctx, key); err != nil {
+				return err
+			}
+		}
+		log.Printf("attachment %s/%s: rejected while processing", opinionId, attachmentId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("attachment %s/%s: processed %d variants", opinionId, attachmentId, len(keys))
+	return nil
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	infra "user-backend/infra"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// maxAttachmentsPerOpinion - 1件の意見に添付できるファイル数
+	maxAttachmentsPerOpinion = 4
+	// maxAttachmentSize - 添付ファイルの最大サイズ（openapi.yamlのAttachmentRequest.sizeと同じ）
+	maxAttachmentSize = 10 << 20
+	// attachmentUploadExpires - アップロードURLの有効期間
+	attachmentUploadExpires = 15 * time.Minute
+	// attachmentURLExpires - 公開URLがない場合に発行する表示用URLの有効期間
+	attachmentURLExpires = time.Hour
+	// attachmentSniffLength - ファイル形式の判定に読み込む先頭のバイト数（http.DetectContentTypeが参照する長さ）
+	attachmentSniffLength = 512
+	// displayVariant - 表示用の画像として配信する変換後の画像の名前（imaging.Sizesの名前）
+	displayVariant = "large"
+)
+
+// WithAttachmentStorage - 添付ファイルを保存するストレージを設定する（nilの場合は添付ファイルAPIを無効にする）
+func WithAttachmentStorage(storage *infra.S3Client) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.storage = storage
+	}
+}
+
+// errAttachmentStorageNotConfigured - 添付ファイルのストレージが設定されていない
+var errAttachmentStorageNotConfigured = errors.New("attachment storage is not configured")
+
+var (
+	errNotOpinionAuthor       = openapi.NewForbiddenError("not_opinion_author", "Only the author of the opinion can attach files.")
+	errAttachmentNotFound     = openapi.NewNotFoundError("attachment_not_found", "The attachment does not exist.")
+	errTooManyAttachments     = openapi.NewConflictError("too_many_attachments", "The opinion already has the maximum number of attachments.")
+	errAttachmentNotUploaded  = openapi.NewConflictError("attachment_not_uploaded", "The file has not been uploaded yet.")
+	errAttachmentRejected     = openapi.NewConflictError("attachment_rejected", "The attachment was rejected. Request a new upload URL.")
+	errAttachmentSizeMismatch = openapi.NewValidationError(
+		"attachment_size_mismatch",
+		"The uploaded file size does not match the declared size.",
+		openapi.FieldError{Field: "size", Code: "mismatch", Message: "does not match the uploaded file"},
+	)
+	errAttachmentTypeMismatch = openapi.NewValidationError(
+		"attachment_type_mismatch",
+		"The uploaded file is not of the declared content type.",
+		openapi.FieldError{Field: "contentType", Code: "mismatch", Message: "does not match the uploaded file"},
+	)
+)
+
+// attachmentUploadPrefix - アップロードされたファイルを保存するキーの接頭辞
+// 位置情報などのメタデータを含むため配信せず、変換後の画像（attachmentVariantKey）を配信する
+const attachmentUploadPrefix = "uploads/"
+
+// attachmentObjectKey - アップロードされたファイルを保存するオブジェクトのキー
+func attachmentObjectKey(opinionId string, attachmentId string) string {
+	return attachmentUploadPrefix + opinionId + "/" + attachmentId
+}
+
+// ParseAttachmentObjectKey - アップロードされたファイルのキーから意見IDと添付ファイルIDを取得する
+func ParseAttachmentObjectKey(key string) (opinionId string, attachmentId string, ok bool) {
+	rest, ok := strings.CutPrefix(key, attachmentUploadPrefix)
+	if !ok {
+		return "", "", false
+	}
+	opinionId, attachmentId, ok = strings.Cut(rest, "/")
+	if !ok || opinionId == "" || attachmentId == "" || strings.Contains(attachmentId, "/") {
+		return "", "", false
+	}
+	return opinionId, attachmentId, true
+}
+
+// attachmentVariantKey - 変換後の画像を保存するオブジェクトのキー
+func attachmentVariantKey(opinionId string, attachmentId string, name string, extension string) string {
+	return "opinions/" + opinionId + "/" + attachmentId + "/" + name + "." + extension
+}
+
+// PostOpinionAttachments - 添付ファイルアップロードURL発行API
+func (s *OpinionService) PostOpinionAttachments(ctx context.Context, opinionId string, attachmentRequest openapi.AttachmentRequest) (openapi.ImplResponse, error) {
+	if s.storage == nil {
+		return openapi.Response(500, nil), errAttachmentStorageNotConfigured
+	}
+
+	// 投稿者本人のみ添付できる
+	actor, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+	opinion, err := s.db.GetOpinion(ctx, opinionId)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	if opinion.MailAddress != actor {
+		return openapi.Response(403, nil), errNotOpinionAuthor
+	}
+
+	// アップロード中（有効期限内）のものを含めて上限を超えないようにする
+	now := time.Now()
+	count, err := s.db.CountAttachments(ctx, opinionId, now.Add(-attachmentUploadExpires))
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	if count >= maxAttachmentsPerOpinion {
+		return openapi.Response(409, nil), errTooManyAttachments
+	}
+
+	attachment := infra.AttachmentItem{
+		OpinionID:       opinionId,
+		AttachmentID:    uuid.New().String(),
+		MailAddress:     actor,
+		ContentType:     attachmentRequest.ContentType,
+		Size:            attachmentRequest.Size,
+		CreatedDateTime: now,
+	}
+	attachment.ObjectKey = attachmentObjectKey(opinionId, attachment.AttachmentID)
+
+	upload, err := s.storage.PresignPut(ctx, attachment.ObjectKey, attachment.ContentType, attachment.Size, attachmentUploadExpires)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	if err := s.db.SaveAttachment(ctx, attachment); err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	return openapi.Response(201, openapi.AttachmentUpload{
+		AttachmentId: attachment.AttachmentID,
+		UploadUrl:    upload.URL,
+		Method:       upload.Method,
+		Headers:      upload.Headers,
+		ExpiresAt:    upload.ExpiresAt,
+	}), nil
+}
+
+// PostOpinionAttachmentComplete - 添付ファイルアップロード完了通知API
+// アップロードされたファイルのサイズと形式を検証し、問題なければ意見に紐づける
+func (s *OpinionService) PostOpinionAttachmentComplete(ctx context.Context, opinionId string, attachmentId string, completeRequest openapi.AttachmentCompleteRequest) (openapi.ImplResponse, error) {
+	if s.storage == nil {
+		return openapi.Response(500, nil), errAttachmentStorageNotConfigured
+	}
+
+	actor, err := requestActor(ctx)
+	if err != nil {
+		return openapi.Response(401, nil), err
+	}
+	attachment, err := s.db.GetAttachment(ctx, opinionId, attachmentId)
+	if errors.Is(err, infra.ErrAttachmentNotFound) {
+		return openapi.Response(404, nil), errAttachmentNotFound
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	if attachment.MailAddress != actor {
+		return openapi.Response(403, nil), errNotOpinionAuthor
+	}
+
+	switch attachment.Status {
+	case infra.AttachmentUploaded:
+		// 紐づけ済み（完了通知の再送）
+		return s.attachmentResponse(ctx, attachment)
+	case infra.AttachmentRejected:
+		return openapi.Response(409, nil), errAttachmentRejected
+	}
+
+	info, err := s.storage.HeadObject(ctx, attachment.ObjectKey)
+	if errors.Is(err, infra.ErrObjectNotFound) {
+		return openapi.Response(409, nil), errAttachmentNotUploaded
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	// サイズは署名に含めているが、念のためサーバー側でも確認する
+	if info.Size != attachment.Size || info.Size > maxAttachmentSize {
+		return s.rejectAttachment(ctx, attachment, errAttachmentSizeMismatch)
+	}
+	// Content-Typeの申告だけでなく、ファイルの先頭から実際の形式を判定する
+	head, err := s.storage.ReadObjectHead(ctx, attachment.ObjectKey, attachmentSniffLength)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	if info.ContentType != attachment.ContentType || http.DetectContentType(head) != attachment.ContentType {
+		return s.rejectAttachment(ctx, attachment, errAttachmentTypeMismatch)
+	}
+
+	attachment, err = s.db.CompleteAttachment(ctx, attachment)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	return s.attachmentResponse(ctx, attachment)
+}
+
+// rejectAttachment - 検証に失敗したファイル（変換済みの場合は変換後の画像も）を削除し、422を返す
+func (s *OpinionService) rejectAttachment(ctx context.Context, attachment infra.AttachmentItem, reason *openapi.APIError) (openapi.ImplResponse, error) {
+	rejected, err := s.db.RejectAttachment(ctx, attachment.OpinionID, attachment.AttachmentID)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	keys := []string{attachment.ObjectKey}
+	for _, key := range rejected.Variants {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		if err := s.storage.DeleteObject(ctx, key); err != nil {
+			return openapi.Response(500, nil), err
+		}
+	}
+	return openapi.Response(422, nil), reason
+}
+
+func (s *OpinionService) attachmentResponse(ctx context.Context, attachment infra.AttachmentItem) (openapi.ImplResponse, error) {
+	response := openapi.Attachment{
+		AttachmentId: attachment.AttachmentID,
+		ContentType:  attachment.ContentType,
+		Processing:   len(attachment.Variants) == 0,
+	}
+	if !response.Processing {
+		display, err := s.attachmentURLs(ctx, attachment.Variants)
+		if err != nil {
+			return openapi.Response(500, nil), err
+		}
+		response.ContentType = display.ContentType
+		response.Url = display.URL
+		response.Thumbnails = display.Thumbnails
+	}
+	return openapi.Response(200, response), nil
+}
+
+// setAttachmentURLs - 意見の添付ファイルに表示用のURLを設定する
+// 画像の変換が完了していない添付ファイルは返さない
+func (s *OpinionService) setAttachmentURLs(ctx context.Context, opinion *infra.OpinionItem) error {
+	attachments := make([]infra.OpinionAttachment, 0, len(opinion.Attachments))
+	if s.storage != nil {
+		for _, attachment := range opinion.Attachments {
+			if len(attachment.Variants) == 0 {
+				continue
+			}
+			display, err := s.attachmentURLs(ctx, attachment.Variants)
+			if err != nil {
+				return err
+			}
+			display.ID = attachment.ID
+			attachments = append(attachments, display)
+		}
+	}
+	opinion.Attachments = attachments
+	return nil
+}
+
+// attachmentURLs - 変換後の画像の表示用URLを発行する
+// largeの画像をURL、それ以外をサムネイルとして返す
+func (s *OpinionService) attachmentURLs(ctx context.Context, variants map[string]string) (infra.OpinionAttachment, error) {
+	display := infra.OpinionAttachment{Thumbnails: make(map[string]string, len(variants))}
+	for name, key := range variants {
+		url, err := s.storage.ObjectURL(ctx, key, attachmentURLExpires)
+		if err != nil {
+			return infra.OpinionAttachment{}, err
+		}
+		if name == displayVariant {
+			display.URL = url
+			display.ContentType = mime.TypeByExtension(path.Ext(key))
+		} else {
+			display.Thumbnails[name] = url
+		}
+	}
+	return display, nil
+}
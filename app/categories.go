@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	openapi "user-backend/docs/gen/go"
+)
+
+// Categories - 意見のカテゴリー（コードはopenapi.yamlのCategoryのenumと一致させる）
+var Categories = []openapi.CategoryCount{
+	{Code: "parks", Name: "公園・緑地"},
+	{Code: "roads", Name: "道路・歩道"},
+	{Code: "safety", Name: "防犯・防災"},
+	{Code: "scenery", Name: "景観"},
+	{Code: "other", Name: "その他"},
+}
+
+// GetCategories - カテゴリー一覧取得API
+func (s *OpinionService) GetCategories(ctx context.Context) (openapi.ImplResponse, error) {
+	// DynamoDBからカテゴリーごとの意見数を取得する処理
+	counts, err := s.db.GetCategoryCounts(ctx)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	categories := make([]openapi.CategoryCount, 0, len(Categories))
+	for _, category := range Categories {
+		category.OpinionCount = counts[category.Code]
+		categories = append(categories, category)
+	}
+	return openapi.Response(200, categories), nil
+}
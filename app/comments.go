@@ -0,0 +1,46 @@
+package app
+
+import (
+	infra "user-backend/infra"
+)
+
+// BuildCommentTree - 投稿日時順のコメントを返信のツリーにする
+// 返信はdepth階層まで、各コメントにつき古い順にreplyLimit件まで含める（残りは返信数と返信一覧の取得で確認する）。
+// 返信先が見つからないコメント（非表示になったコメントへの返信など）は、その返信も含めてツリーに含めない
+func BuildCommentTree(comments []infra.CommentItem, depth int, replyLimit int) []infra.CommentItem {
+	ids := make(map[string]bool, len(comments))
+	for _, comment := range comments {
+		ids[comment.CommentID] = true
+	}
+	children := make(map[string][]infra.CommentItem)
+	roots := []infra.CommentItem{}
+	for _, comment := range comments {
+		if comment.ParentCommentID == "" {
+			roots = append(roots, comment)
+			continue
+		}
+		if !ids[comment.ParentCommentID] {
+			continue
+		}
+		children[comment.ParentCommentID] = append(children[comment.ParentCommentID], comment)
+	}
+
+	var attach func(comment infra.CommentItem, level int) infra.CommentItem
+	attach = func(comment infra.CommentItem, level int) infra.CommentItem {
+		if level >= depth {
+			return comment
+		}
+		replies := children[comment.CommentID]
+		if len(replies) > replyLimit {
+			replies = replies[:replyLimit]
+		}
+		for _, reply := range replies {
+			comment.Replies = append(comment.Replies, attach(reply, level+1))
+		}
+		return comment
+	}
+	for i := range roots {
+		roots[i] = attach(roots[i], 0)
+	}
+	return roots
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	infra "user-backend/infra"
+)
+
+// commentTree - ツリーをコメントIDの入れ子で表す（比較用）
+type commentTree struct {
+	ID      string
+	Replies []commentTree
+}
+
+func toCommentTree(comments []infra.CommentItem) []commentTree {
+	trees := []commentTree{}
+	for _, comment := range comments {
+		tree := commentTree{ID: comment.CommentID}
+		if len(comment.Replies) > 0 {
+			tree.Replies = toCommentTree(comment.Replies)
+		}
+		trees = append(trees, tree)
+	}
+	return trees
+}
+
+func TestBuildCommentTree(t *testing.T) {
+	comment := func(id, parent string) infra.CommentItem {
+		return infra.CommentItem{CommentID: id, ParentCommentID: parent}
+	}
+	// 投稿日時順: 1 ─ 2 ─ 3 ─ 4、1 ─ 5、6、「削除済みへの返信」7 ─ 8
+	comments := []infra.CommentItem{
+		comment("1", ""),
+		comment("2", "1"),
+		comment("3", "2"),
+		comment("4", "3"),
+		comment("5", "1"),
+		comment("6", ""),
+		comment("7", "deleted"),
+		comment("8", "7"),
+	}
+
+	tests := []struct {
+		name       string
+		depth      int
+		replyLimit int
+		want       []commentTree
+	}{
+		{
+			name: "roots only", depth: 0, replyLimit: 10,
+			want: []commentTree{{ID: "1"}, {ID: "6"}},
+		},
+		{
+			name: "one level", depth: 1, replyLimit: 10,
+			want: []commentTree{{ID: "1", Replies: []commentTree{{ID: "2"}, {ID: "5"}}}, {ID: "6"}},
+		},
+		{
+			name: "all levels", depth: 3, replyLimit: 10,
+			want: []commentTree{
+				{ID: "1", Replies: []commentTree{
+					{ID: "2", Replies: []commentTree{{ID: "3", Replies: []commentTree{{ID: "4"}}}}},
+					{ID: "5"},
+				}},
+				{ID: "6"},
+			},
+		},
+		{
+			name: "reply limit keeps the oldest replies", depth: 1, replyLimit: 1,
+			want: []commentTree{{ID: "1", Replies: []commentTree{{ID: "2"}}}, {ID: "6"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toCommentTree(BuildCommentTree(comments, tt.depth, tt.replyLimit))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildCommentTree() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCommentTreeEmpty(t *testing.T) {
+	got := BuildCommentTree(nil, 2, 3)
+	if got == nil || len(got) != 0 {
+		t.Errorf("BuildCommentTree(nil) = %#v, want an empty slice", got)
+	}
+}
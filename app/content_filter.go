@@ -0,0 +1,58 @@
+package app
+
+import (
+	"slices"
+	openapi "user-backend/docs/gen/go"
+	"user-backend/textfilter"
+)
+
+// WithContentFilter - 意見・コメントの本文を保存前に検査する（設定がない場合は検査しない）
+func WithContentFilter(filter *textfilter.Filter) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.contentFilter = filter
+	}
+}
+
+// contentFilterMessages - 検出した内容の種類ごとのメッセージ
+var contentFilterMessages = map[textfilter.Kind]string{
+	textfilter.KindNGWord:      "contains a prohibited word",
+	textfilter.KindPhoneNumber: "contains a phone number",
+	textfilter.KindMailAddress: "contains a mail address",
+}
+
+// errContentRejected - 禁止語・個人情報を含む本文（検出した箇所をFieldErrorのspanで返す）
+func errContentRejected(field string, matches []textfilter.Match) error {
+	fieldErrors := make([]openapi.FieldError, 0, len(matches))
+	for _, m := range matches {
+		fieldErrors = append(fieldErrors, openapi.FieldError{
+			Field:   field,
+			Code:    string(m.Kind),
+			Message: contentFilterMessages[m.Kind],
+			Span:    &openapi.TextSpan{Start: m.Start, End: m.End},
+		})
+	}
+	return openapi.NewValidationError(
+		"content_rejected",
+		"The text contains prohibited words or personal information.",
+		fieldErrors...,
+	)
+}
+
+// filterContent - 本文を検査し、保存する本文とモデレーターの確認を待つ場合に検出した内容の種類を返す
+// 拒否する場合は検出した箇所を含むエラーを返す
+func (s *OpinionService) filterContent(field string, text string) (string, []string, error) {
+	if s.contentFilter == nil {
+		return text, nil, nil
+	}
+	result := s.contentFilter.Check(text)
+	if len(result.Rejected) > 0 {
+		return "", nil, errContentRejected(field, result.Rejected)
+	}
+	var flags []string
+	for _, m := range result.Moderated {
+		if !slices.Contains(flags, string(m.Kind)) {
+			flags = append(flags, string(m.Kind))
+		}
+	}
+	return result.Text, flags, nil
+}
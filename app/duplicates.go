@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	geo "user-backend/geo"
+	infra "user-backend/infra"
+	"user-backend/textfilter"
+)
+
+// DuplicatePolicy - 重複・類似した意見の検出の設定（期間が0の規則は検出しない）
+type DuplicatePolicy struct {
+	// 同じユーザーがこの期間内に投稿した、類似度がSameUserSimilarity以上の意見は重複として拒否する
+	SameUserWindow     time.Duration
+	SameUserSimilarity float64
+	// 他のユーザーがこの期間内にNearbyRadius（メートル）以内で投稿した、
+	// 類似度がNearbySimilarity以上の公開中の意見があればリアクションを勧める
+	NearbyWindow     time.Duration
+	NearbyRadius     float64
+	NearbySimilarity float64
+}
+
+// DefaultDuplicatePolicy - 設定がない場合の重複・類似した意見の検出の設定
+var DefaultDuplicatePolicy = DuplicatePolicy{
+	SameUserWindow:     24 * time.Hour,
+	SameUserSimilarity: 0.9,
+	NearbyWindow:       6 * time.Hour,
+	NearbyRadius:       100,
+	NearbySimilarity:   0.6,
+}
+
+// WithDuplicatePolicy - 重複・類似した意見の検出の設定を変更する
+func WithDuplicatePolicy(policy DuplicatePolicy) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.duplicatePolicy = policy
+	}
+}
+
+// opinionGeohashPrecision - 意見に保存する投稿位置のジオハッシュの文字数（セルは約1.2km×0.6km）
+// 近くで投稿された意見は、NearbyRadiusの範囲を覆うセルごとに取得する
+const opinionGeohashPrecision = 6
+
+// errDuplicateOpinion - 同じユーザーがほぼ同じ本文の意見を既に投稿している（既存の意見のidを返す）
+func errDuplicateOpinion(existingOpinionId string) error {
+	err := openapi.NewConflictError("duplicate_opinion", "You have already posted the same opinion.")
+	err.Extensions = map[string]interface{}{"existingOpinionId": existingOpinionId}
+	return err
+}
+
+// errSimilarOpinion - 近くで似た本文の意見が投稿されている（既存の意見へのリアクションを勧める）
+func errSimilarOpinion(existingOpinionId string) error {
+	err := openapi.NewConflictError(
+		"similar_opinion_exists",
+		"A similar opinion has been posted nearby. Consider reacting to it instead, or post again with ignoreSimilar.",
+	)
+	err.Extensions = map[string]interface{}{"existingOpinionId": existingOpinionId, "suggestedAction": "react"}
+	return err
+}
+
+// checkDuplicate - 重複・類似した意見を検出した場合はエラーを返す
+// ignoreSimilarがtrueの場合は他のユーザーの類似した意見を無視する（同じユーザーの重複は常に拒否する）
+// 同じユーザーの意見は投稿者ごとのGSI、他のユーザーの意見は投稿位置の周囲のジオハッシュのセルごとのGSIから期間内のもののみ取得する
+func (s *OpinionService) checkDuplicate(ctx context.Context, mailAddress string, point geo.Point, text string, ignoreSimilar bool) (int, error) {
+	policy := s.duplicatePolicy
+	now := time.Now()
+
+	if policy.SameUserWindow > 0 {
+		// 自分の意見は承認待ち・非表示でも重複とみなす
+		own, err := s.db.ListRecentOpinionsByUser(ctx, mailAddress, now.Add(-policy.SameUserWindow))
+		if err != nil {
+			return 500, err
+		}
+		for _, opinion := range own {
+			if textfilter.Similarity(text, opinion.Opinion) >= policy.SameUserSimilarity {
+				return 409, errDuplicateOpinion(opinion.ID)
+			}
+		}
+	}
+
+	if policy.NearbyWindow <= 0 || ignoreSimilar {
+		return 0, nil
+	}
+	geohashes := geo.GeohashesWithin(point, policy.NearbyRadius, opinionGeohashPrecision)
+	nearby, err := s.db.ListRecentOpinionsNear(ctx, geohashes, now.Add(-policy.NearbyWindow))
+	if err != nil {
+		return 500, err
+	}
+	var similar *infra.OpinionItem
+	similarity := 0.0
+	for i, opinion := range nearby {
+		// 他のユーザーの意見は閲覧できるもののみ勧める
+		if opinion.MailAddress == mailAddress || !opinion.IsPublic() {
+			continue
+		}
+		existing := geo.Point{Longitude: opinion.Coordinate.Longitude, Latitude: opinion.Coordinate.Latitude}
+		if geo.Distance(point, existing) > policy.NearbyRadius {
+			continue
+		}
+		if sim := textfilter.Similarity(text, opinion.Opinion); sim >= policy.NearbySimilarity && sim > similarity {
+			similar, similarity = &nearby[i], sim
+		}
+	}
+	if similar != nil {
+		return 409, errSimilarOpinion(similar.ID)
+	}
+	return 0, nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"io"
+	openapi "user-backend/docs/gen/go"
+	export "user-backend/export"
+	infra "user-backend/infra"
+)
+
+type ExportService struct {
+	openapi.ExportAPIService
+	db *infra.DynamoDBClient
+}
+
+func NewExportService(db *infra.DynamoDBClient) *ExportService {
+	return &ExportService{db: db}
+}
+
+// GetOpinionsGeoJSON - 意見GeoJSONエクスポートAPI
+func (s *ExportService) GetOpinionsGeoJSON(ctx context.Context, area string, category string, tag string) (openapi.ImplResponse, error) {
+	return s.streamResponse(ctx, export.FormatGeoJSON, NewOpinionFilter(area, category, tag), false)
+}
+
+// GetOpinionsCSV - 意見CSVエクスポートAPI
+func (s *ExportService) GetOpinionsCSV(ctx context.Context, area string, category string, tag string, lang string) (openapi.ImplResponse, error) {
+	return s.streamResponse(ctx, export.FormatCSV, NewOpinionFilter(area, category, tag), lang == "ja")
+}
+
+// GetOpinionsKML - 意見KMLエクスポートAPI
+func (s *ExportService) GetOpinionsKML(ctx context.Context, area string, category string, tag string) (openapi.ImplResponse, error) {
+	return s.streamResponse(ctx, export.FormatKML, NewOpinionFilter(area, category, tag), false)
+}
+
+// streamResponse - 意見をレスポンスへ逐次書き出すStreamBodyを返す
+// コメント数・リアクション数は意見に保持しているカウントを使う（コメント・リアクションのテーブルは読まない）
+func (s *ExportService) streamResponse(ctx context.Context, format export.Format, filter infra.OpinionFilter, japaneseHeader bool) (openapi.ImplResponse, error) {
+	body := openapi.StreamBody{
+		ContentType: format.ContentType(),
+		Filename:    "opinions." + string(format),
+		WriteTo: func(w io.Writer) error {
+			return s.WriteOpinions(ctx, export.NewEncoder(format, w, japaneseHeader), filter)
+		},
+	}
+	return openapi.Response(200, body), nil
+}
+
+// WriteOpinions - 絞り込み条件に合う意見一覧をエンコーダーへ逐次書き出す（CLIからも利用）
+func (s *ExportService) WriteOpinions(ctx context.Context, enc export.Encoder, filter infra.OpinionFilter) error {
+	err := s.db.ScanOpinions(ctx, filter, func(opinion infra.OpinionItem) error {
+		return enc.WriteOpinion(opinion, opinion.Counts())
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+	infra "user-backend/infra"
+)
+
+// maxHashtags - 1件の意見から抽出するハッシュタグの上限
+const maxHashtags = 10
+
+// maxHashtagLength - ハッシュタグの最大文字数（#を除く）
+const maxHashtagLength = 30
+
+// hashtagPattern - 本文中のハッシュタグ（#または全角＃で始まり、文字・数字・_・長音符が続く）
+// URLのフラグメント（example.com/#top）などを拾わないよう、直前が文字・数字・記号でない場合のみ一致する
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])[#＃]([\p{L}\p{N}_ー]+)`)
+
+// ExtractHashtags - 意見の本文からハッシュタグを抽出する（正規化・重複除去済み、出現順）
+func ExtractHashtags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := NormalizeHashtag(m[1])
+		if tag == "" || utf8.RuneCountInString(tag) > maxHashtagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxHashtags {
+			break
+		}
+	}
+	return tags
+}
+
+// NormalizeHashtag - ハッシュタグを比較用に正規化する
+// 先頭の#を除き、全角英数字を半角に、英字を小文字にそろえる（#Parkと＃ｐａｒｋを同じタグとして扱う）
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimLeft(strings.TrimSpace(tag), "#＃")
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		// 全角英数字・記号（！〜～）を半角にする
+		if r >= '！' && r <= '～' {
+			return r - '！' + '!'
+		}
+		return r
+	}, tag))
+}
+
+// NewOpinionFilter - 一覧・エクスポートのクエリパラメーターから絞り込み条件を作る
+// ハッシュタグは保存時と同じ規則で正規化して比較する
+func NewOpinionFilter(area string, category string, tag string) infra.OpinionFilter {
+	return infra.OpinionFilter{
+		AreaCode: area,
+		Category: category,
+		Tag:      NormalizeHashtag(tag),
+	}
+}
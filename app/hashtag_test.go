@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	// 上限を超える数のタグ（#t1 #t2 ... #t12）
+	var many []string
+	for i := 1; i <= maxHashtags+2; i++ {
+		many = append(many, fmt.Sprintf("#t%d", i))
+	}
+	var manyWant []string
+	for i := 1; i <= maxHashtags; i++ {
+		manyWant = append(manyWant, fmt.Sprintf("t%d", i))
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"no hashtags", "公園のベンチを増やしてほしい", nil},
+		{"hashtags in order of appearance", "#公園 のベンチを増やしてほしい #桜", []string{"公園", "桜"}},
+		{"full-width hash", "＃公園のベンチ", []string{"公園のベンチ"}},
+		{"long vowel mark is part of the tag", "#スーパー が遠い", []string{"スーパー"}},
+		{"punctuation ends the tag", "#桜、#公園。", []string{"桜", "公園"}},
+		{"url fragment is not a hashtag", "詳しくは https://example.com/#top を参照", nil},
+		{"fragment without a scheme", "example.com/#top", nil},
+		{"html entity is not a hashtag", "&#123; の表記", nil},
+		{"hash inside a word is not a hashtag", "abc#def", nil},
+		{"hash alone is not a hashtag", "# 公園", nil},
+		{"normalized before dedup", "#Park #park ＃ＰＡＲＫ #ｐａｒｋ", []string{"park"}},
+		{"at most maxHashtags tags", strings.Join(many, " "), manyWant},
+		{"duplicates do not use up the cap", strings.Repeat("#dup ", maxHashtags+1) + "#last", []string{"dup", "last"}},
+		{"maxHashtagLength runes are kept", "#" + strings.Repeat("あ", maxHashtagLength), []string{strings.Repeat("あ", maxHashtagLength)}},
+		{"longer tags are skipped", "#" + strings.Repeat("あ", maxHashtagLength+1) + " #短い", []string{"短い"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractHashtags(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractHashtags(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"park", "park"},
+		{"#Park", "park"},
+		{"＃ｐａｒｋ", "park"},
+		{"ＰＡＲＫ１２３", "park123"},
+		{"  #桜  ", "桜"},
+		{"##double", "double"},
+		{"スーパー", "スーパー"},
+		{"", ""},
+		{"#", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := NormalizeHashtag(tt.tag); got != tt.want {
+				t.Errorf("NormalizeHashtag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
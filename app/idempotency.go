@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	infra "user-backend/infra"
+)
+
+// IdempotencyStore - Idempotency-KeyごとのレスポンスをDynamoDBに保存する（openapi.Idempotencyに渡す）
+type IdempotencyStore struct {
+	db *infra.DynamoDBClient
+}
+
+func NewIdempotencyStore(db *infra.DynamoDBClient) *IdempotencyStore {
+	return &IdempotencyStore{db: db}
+}
+
+// Reserve - キーを予約して予約のトークンを返し、既に使われている場合は保存されている内容を返す
+func (s *IdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (string, *openapi.IdempotencyRecord, error) {
+	reservation, record, err := s.db.ReserveIdempotencyKey(ctx, key, fingerprint, ttl)
+	if err != nil || record == nil {
+		return reservation, nil, err
+	}
+	return "", &openapi.IdempotencyRecord{
+		Fingerprint: record.Fingerprint,
+		Completed:   record.ResponseStatus != 0,
+		StatusCode:  record.ResponseStatus,
+		Header:      record.Headers,
+		Body:        record.Body,
+	}, nil
+}
+
+// Complete - 予約したキーにレスポンスを保存する（予約が期限切れで奪われていた場合は保存しない）
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, reservation string, record openapi.IdempotencyRecord, ttl time.Duration) error {
+	return s.db.CompleteIdempotencyKey(ctx, key, reservation, infra.IdempotencyRecord{
+		Fingerprint:    record.Fingerprint,
+		ResponseStatus: record.StatusCode,
+		Headers:        record.Header,
+		Body:           record.Body,
+	}, ttl)
+}
+
+// Release - キーの予約を取り消し、再試行できるようにする
+func (s *IdempotencyStore) Release(ctx context.Context, key string, reservation string) error {
+	return s.db.ReleaseIdempotencyKey(ctx, key, reservation)
+}
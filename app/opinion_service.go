@@ -2,28 +2,220 @@ package app
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 	openapi "user-backend/docs/gen/go"
+	geo "user-backend/geo"
 	infra "user-backend/infra"
+	"user-backend/textfilter"
+)
+
+// ErrOutsideServiceArea - 投稿位置がサービス提供エリア外
+var ErrOutsideServiceArea = openapi.NewValidationError(
+	"outside_service_area",
+	"The coordinate is outside of the service area.",
+	openapi.FieldError{Field: "coordinate", Code: "outsideServiceArea", Message: "is outside of the service area"},
 )
 
 type OpinionService struct {
 	openapi.OpinionAPIService
 	db *infra.DynamoDBClient
+	// サービス提供エリア（nilの場合は制限しない）
+	serviceArea geo.MultiPolygon
+	// 区市町村の判定に使う境界データ（nilの場合は判定しない）
+	areas *geo.AreaIndex
+	// 受け付けるリアクションの種類（先頭はデフォルトの種類）
+	reactionTypes []string
+	// 添付ファイルのストレージ（nilの場合は添付ファイルAPIを無効にする）
+	storage *infra.S3Client
+	// 意見・コメントを自動で非表示にする通報数
+	reportHideThreshold int32
+	// 意見・コメントの本文の禁止語・個人情報の検査（nilの場合は検査しない）
+	contentFilter *textfilter.Filter
+	// 意見を公開するタイミング（事前モデレーションの場合はモデレーターが承認するまで公開しない）
+	moderationMode ModerationMode
+	// 重複・類似した意見の検出の設定
+	duplicatePolicy DuplicatePolicy
+}
+
+// ModerationMode - 意見のモデレーションの方式
+type ModerationMode string
+
+const (
+	PostModeration ModerationMode = "post" // 投稿をすぐに公開し、通報などをもとに後からモデレーションする
+	PreModeration  ModerationMode = "pre"  // モデレーターが承認した意見のみ公開する
+)
+
+// ParseModerationMode - 文字列をModerationModeに変換する
+func ParseModerationMode(value string) (ModerationMode, error) {
+	switch mode := ModerationMode(value); mode {
+	case PostModeration, PreModeration:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown moderation mode %q (must be post or pre)", value)
+}
+
+// WithModerationMode - 意見のモデレーションの方式を設定する（既定はPostModeration）
+func WithModerationMode(mode ModerationMode) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.moderationMode = mode
+	}
+}
+
+// DefaultReactionTypes - 設定がない場合に受け付けるリアクションの種類
+var DefaultReactionTypes = []string{infra.DefaultReactionType, "agree", "fixit", "beautiful", "dangerous"}
+
+// OpinionServiceOption - OpinionServiceの設定
+type OpinionServiceOption func(*OpinionService)
+
+// WithServiceArea - 意見を投稿できるエリアを制限する
+func WithServiceArea(area geo.MultiPolygon) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.serviceArea = area
+	}
+}
+
+// WithAreaIndex - 投稿位置から区市町村を判定して意見に付与する
+func WithAreaIndex(areas *geo.AreaIndex) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.areas = areas
+	}
+}
+
+// WithReactionTypes - 受け付けるリアクションの種類を設定する
+// デフォルトの種類（真偽値のみのリアクション）は常に受け付ける
+func WithReactionTypes(reactionTypes []string) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.reactionTypes = []string{infra.DefaultReactionType}
+		for _, reactionType := range reactionTypes {
+			if !slices.Contains(s.reactionTypes, reactionType) {
+				s.reactionTypes = append(s.reactionTypes, reactionType)
+			}
+		}
+	}
+}
+
+// errOpinionNotFound - 存在しない（または削除された）意見が指定された
+var errOpinionNotFound = openapi.NewNotFoundError("opinion_not_found", "The opinion does not exist.")
+
+// errCommentNotFound - 存在しない（または削除された）コメントが指定された
+var errCommentNotFound = openapi.NewNotFoundError("comment_not_found", "The comment does not exist.")
+
+// errCommentDepthExceeded - 返信できる深さを超えたコメントへの返信
+var errCommentDepthExceeded = openapi.NewValidationError(
+	"comment_depth_exceeded",
+	"Replies cannot be nested any deeper.",
+	openapi.FieldError{Field: "parentCommentId", Code: "depthExceeded", Message: "cannot be replied to"},
+)
+
+// errUserSuspended - 期限付きで利用停止中のユーザーからの投稿・リアクション・通報
+var errUserSuspended = openapi.NewForbiddenError("user_suspended", "Your account is suspended.")
+
+// errUserBanned - 無期限に利用停止中のユーザーからの投稿・リアクション・通報
+var errUserBanned = openapi.NewForbiddenError("user_banned", "Your account is banned.")
+
+// errAuthenticationRequired - 本人確認が必要な操作へのアクセストークンのないリクエスト
+var errAuthenticationRequired = openapi.NewUnauthorizedError("authentication_required", "A valid access token is required.")
+
+// requestActor - API Gatewayのオーソライザーが検証したアクセストークンのクレームから、操作するユーザーを取得する
+// リクエスト本文のmailAddressは送信者が自由に指定できるため、本人確認には使わない
+func requestActor(ctx context.Context) (string, error) {
+	actor := openapi.ClaimsFromContext(ctx).Actor()
+	if actor == "" {
+		return "", errAuthenticationRequired
+	}
+	return actor, nil
 }
 
-func NewOpinionService(db *infra.DynamoDBClient) *OpinionService {
-	return &OpinionService{db: db}
+// checkUserStatus - アクセストークンから書き込むユーザーを取得し、利用停止中のユーザーの書き込みを拒否する
+// 拒否する場合はステータスコードとエラーを返す。リクエスト本文のmailAddressは送信者が自由に指定できるため使わない
+func (s *OpinionService) checkUserStatus(ctx context.Context) (string, int, error) {
+	actor, err := requestActor(ctx)
+	if err != nil {
+		return "", 401, err
+	}
+	status, err := s.db.GetUserStatus(ctx, actor)
+	if err != nil {
+		return "", 500, err
+	}
+	switch status.Status {
+	case infra.UserSuspended:
+		suspended := *errUserSuspended
+		suspended.Extensions = map[string]interface{}{"until": status.Until}
+		return "", 403, &suspended
+	case infra.UserBanned:
+		return "", 403, errUserBanned
+	}
+	return actor, 0, nil
+}
+
+func NewOpinionService(db *infra.DynamoDBClient, opts ...OpinionServiceOption) *OpinionService {
+	s := &OpinionService{db: db, reactionTypes: DefaultReactionTypes, reportHideThreshold: DefaultReportHideThreshold, moderationMode: PostModeration, duplicatePolicy: DefaultDuplicatePolicy}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // PostUserOpinions - 意見投稿API
 func (s *OpinionService) PostUserOpinions(ctx context.Context, opinion openapi.OpinionRequest) (openapi.ImplResponse, error) {
+	latitude := *opinion.Coordinate.Latitude
+	longitude := *opinion.Coordinate.Longitude
+
+	point := geo.Point{Longitude: longitude, Latitude: latitude}
+
+	actor, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	// サービス提供エリア外からの投稿は422を返す
+	if s.serviceArea != nil && !s.serviceArea.Contains(point) {
+		return openapi.Response(422, nil), ErrOutsideServiceArea
+	}
+
+	// 禁止語・個人情報を含む本文は422を返す（設定によっては伏せ字にする、またはモデレーターの確認待ちにする）
+	text, moderationFlags, err := s.filterContent("opinion", opinion.Opinion)
+	if err != nil {
+		return openapi.Response(422, nil), err
+	}
+
+	// 同じユーザーの重複投稿、近くで投稿された似た意見がある場合は409を返す（既存の意見のidを含める）
+	if code, err := s.checkDuplicate(ctx, actor, point, text, opinion.IgnoreSimilar); err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	// 投稿位置の区市町村を判定（どの区市町村にも属さない場合は付与しない）
+	var area infra.Area
+	if s.areas != nil {
+		if a, ok := s.areas.Resolve(point); ok {
+			area = infra.Area{Code: a.Code, Name: a.Name}
+		}
+	}
+
+	// 事前モデレーションの場合は承認待ちとして保存する（投稿者のみ閲覧できる）
+	status := infra.OpinionPublished
+	if s.moderationMode == PreModeration {
+		status = infra.OpinionPending
+	}
+
 	// DynamoDBに保存する処理
-	_, err := s.db.SaveOpinion(
+	_, err = s.db.SaveOpinion(
 		ctx,
-		opinion.MailAddress,
-		opinion.Coordinate.Latitude,
-		opinion.Coordinate.Longitude,
-		opinion.Opinion,
+		actor,
+		latitude,
+		longitude,
+		geo.Geohash(point, opinionGeohashPrecision),
+		text,
+		area,
+		opinion.Category,
+		ExtractHashtags(text),
+		status,
+		moderationFlags,
 	)
 	if err != nil {
 		return openapi.Response(500, nil), err
@@ -34,24 +226,108 @@ func (s *OpinionService) PostUserOpinions(ctx context.Context, opinion openapi.O
 }
 
 // GetUserOpinions - ユーザー意見取得API
-func (s *OpinionService) GetUserOpinions(ctx context.Context) (openapi.ImplResponse, error) {
-	opinions, err := s.db.GetOpinions(ctx) // DynamoDBから意見を取得する処理
+// 公開中の意見を返す。viewer（認証済みの閲覧ユーザー）を指定した場合はそのユーザーが投稿した承認待ち・却下の意見も含める
+func (s *OpinionService) GetUserOpinions(ctx context.Context, viewer string, area string, category string, tag string, sort string, limit int32, cursor string) (openapi.ImplResponse, error) {
+	filter := NewOpinionFilter(area, category, tag)
+	filter.ViewerMailAddress = viewer
+
+	// 並び順・ページングの指定がなければ従来どおり全件を返す
+	if sort == "" && limit == 0 && cursor == "" {
+		opinions, err := s.db.GetOpinions(ctx, filter) // DynamoDBから意見を取得する処理
+		if err != nil {
+			return openapi.Response(500, nil), err
+		}
+		for i := range opinions {
+			if err := s.setAttachmentURLs(ctx, &opinions[i]); err != nil {
+				return openapi.Response(500, nil), err
+			}
+		}
+		return openapi.Response(200, opinions), nil // 正常時は200と意見を返す
+	}
+
+	if sort == "" {
+		sort = string(infra.SortNew)
+	}
+	if limit == 0 {
+		limit = defaultOpinionPageSize
+	}
+	opinionSort, _ := infra.ParseOpinionSort(sort)
+	page, err := s.db.ListOpinions(ctx, filter, opinionSort, limit, cursor)
+	if errors.Is(err, infra.ErrInvalidCursor) {
+		return openapi.Response(400, nil), &openapi.ParsingError{Err: errors.New("cursor is invalid for this sort")}
+	}
 	if err != nil {
 		return openapi.Response(500, nil), err
 	}
+	for i := range page.Opinions {
+		if err := s.setAttachmentURLs(ctx, &page.Opinions[i]); err != nil {
+			return openapi.Response(500, nil), err
+		}
+	}
 
-	return openapi.Response(200, opinions), nil // 正常時は200と意見を返す
+	// 次のページのカーソルはヘッダーで返す（レスポンスボディは従来どおり意見の配列）
+	response := openapi.Response(200, page.Opinions)
+	if page.NextCursor != "" {
+		response.Headers = http.Header{openapi.NextCursorHeader: []string{page.NextCursor}}
+	}
+	return response, nil
 }
 
+// GetTrendingOpinions - 急上昇の意見取得API
+func (s *OpinionService) GetTrendingOpinions(ctx context.Context, window string, limit int32) (openapi.ImplResponse, error) {
+	trendWindow := infra.TrendWindow24h
+	if window != "" {
+		trendWindow, _ = infra.ParseTrendWindow(window)
+	}
+
+	// DynamoDBから集計期間内の反応数が多い意見を取得する処理
+	opinions, err := s.db.GetTrendingOpinions(ctx, trendWindow, int(limit), time.Now())
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	for i := range opinions {
+		if err := s.setAttachmentURLs(ctx, &opinions[i].OpinionItem); err != nil {
+			return openapi.Response(500, nil), err
+		}
+	}
+
+	return openapi.Response(200, opinions), nil
+}
+
+// defaultOpinionPageSize - 並び順を指定した場合の1ページの件数（limit未指定時）
+const defaultOpinionPageSize = 50
+
 // PostUserComments - コメント投稿API
 func (s *OpinionService) PostUserComments(ctx context.Context, opinionId string, commentRequest openapi.CommentRequest) (openapi.ImplResponse, error) {
+	actor, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	// 禁止語・個人情報を含む本文は422を返す（設定によっては伏せ字にする、またはモデレーターの確認待ちにする）
+	text, moderationFlags, err := s.filterContent("comment", commentRequest.Comment)
+	if err != nil {
+		return openapi.Response(422, nil), err
+	}
+
 	// DynamoDBにコメントを保存する処理
-	_, err := s.db.SaveComment(
+	_, err = s.db.SaveComment(
 		ctx,
 		opinionId,
-		commentRequest.MailAddress,
-		commentRequest.Comment,
+		actor,
+		text,
+		commentRequest.ParentCommentId,
+		moderationFlags,
 	)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
+	if errors.Is(err, infra.ErrCommentNotFound) {
+		return openapi.Response(404, nil), errCommentNotFound
+	}
+	if errors.Is(err, infra.ErrCommentDepthExceeded) {
+		return openapi.Response(422, nil), errCommentDepthExceeded
+	}
 	if err != nil {
 		return openapi.Response(500, nil), err
 	}
@@ -60,29 +336,117 @@ func (s *OpinionService) PostUserComments(ctx context.Context, opinionId string,
 	return openapi.Response(201, nil), nil
 }
 
-// PostUserComments - コメント取得API
-func (s *OpinionService) GetUserComments(ctx context.Context, opinionId string) (openapi.ImplResponse, error) {
-	// DynamoDBにコメントを保存する処理
+// GetUserComments - コメント取得API
+// parentCommentIdを指定した場合はそのコメントへの返信を1ページ返す。
+// 指定しない場合は全てのコメントを、viewがtreeの場合は返信をツリーにして返す。
+// mailAddressを指定した場合は各コメントに自分のリアクション状態を設定する
+func (s *OpinionService) GetUserComments(ctx context.Context, opinionId string, mailAddress string, view string, depth int32, replyLimit int32, parentCommentId string, limit int32, cursor string) (openapi.ImplResponse, error) {
+	if parentCommentId != "" {
+		page, err := s.db.GetCommentReplies(ctx, opinionId, parentCommentId, limit, cursor)
+		if errors.Is(err, infra.ErrCommentNotFound) {
+			return openapi.Response(404, nil), errCommentNotFound
+		}
+		if errors.Is(err, infra.ErrInvalidCursor) {
+			return openapi.Response(400, nil), &openapi.ParsingError{Err: errors.New("cursor is invalid for this comment")}
+		}
+		if err != nil {
+			return openapi.Response(500, nil), err
+		}
+		if err := s.setCommentReactionStates(ctx, page.Comments, mailAddress); err != nil {
+			return openapi.Response(500, nil), err
+		}
+		response := openapi.Response(200, page.Comments)
+		if page.NextCursor != "" {
+			response.Headers = http.Header{openapi.NextCursorHeader: []string{page.NextCursor}}
+		}
+		return response, nil
+	}
+
+	// DynamoDBからコメントを取得する処理
 	comments, err := s.db.GetComment(
 		ctx,
 		opinionId,
 	)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
 	if err != nil {
 		return openapi.Response(500, nil), err
 	}
 
+	if view == "tree" {
+		comments = BuildCommentTree(comments, int(depth), int(replyLimit))
+	}
+	if err := s.setCommentReactionStates(ctx, comments, mailAddress); err != nil {
+		return openapi.Response(500, nil), err
+	}
 	return openapi.Response(200, comments), nil
 }
 
+// setCommentReactionStates - mailAddressが指定された場合のみ、コメント（返信を含む）に自分のリアクション状態を設定する
+func (s *OpinionService) setCommentReactionStates(ctx context.Context, comments []infra.CommentItem, mailAddress string) error {
+	if mailAddress == "" || len(comments) == 0 {
+		return nil
+	}
+	return s.db.SetCommentReactionStates(ctx, comments, mailAddress)
+}
+
+// PutCommentReactions - コメントリアクションAPI
+func (s *OpinionService) PutCommentReactions(ctx context.Context, opinionId string, commentId string, commentReactionRequest openapi.CommentReactionRequest) (openapi.ImplResponse, error) {
+	actor, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	// DynamoDBにリアクションを保存する処理
+	reaction, err := s.db.SaveCommentReaction(
+		ctx,
+		opinionId,
+		commentId,
+		actor,
+		*commentReactionRequest.Reaction,
+	)
+	if errors.Is(err, infra.ErrCommentNotFound) {
+		return openapi.Response(404, nil), errCommentNotFound
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	return openapi.Response(201, reaction), nil
+}
+
 // PutOpinionReactions - リアクション更新API
 func (s *OpinionService) PutOpinionReactions(ctx context.Context, opinionId string, reactionRequestParam openapi.ReactionRequest) (openapi.ImplResponse, error) {
-	// DynamoDBにコメントを保存する処理
+	// 種類の指定がなければデフォルトの種類として扱う（従来の真偽値のみのリクエスト）
+	reactionType := reactionRequestParam.Type
+	if reactionType == "" {
+		reactionType = infra.DefaultReactionType
+	}
+	if !slices.Contains(s.reactionTypes, reactionType) {
+		return openapi.Response(422, nil), openapi.NewValidationError(
+			"unknown_reaction_type",
+			"The reaction type is not supported.",
+			openapi.FieldError{Field: "type", Code: "enum", Message: "must be one of " + strings.Join(s.reactionTypes, ", ")},
+		)
+	}
+
+	actor, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	// DynamoDBにリアクションを保存する処理
 	isReactioned, err := s.db.SaveReaction(
 		ctx,
 		opinionId,
-		reactionRequestParam.MailAddress,
-		reactionRequestParam.Reaction,
+		actor,
+		reactionType,
+		*reactionRequestParam.Reaction,
 	)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
 	if err != nil {
 		return openapi.Response(500, nil), err
 	}
@@ -93,14 +457,53 @@ func (s *OpinionService) PutOpinionReactions(ctx context.Context, opinionId stri
 // GetOpinionReactionsInfo - リアクション情報取得API
 func (s *OpinionService) GetOpinionReactionsInfo(ctx context.Context, opinionId string, reactionInfoRequestHeader openapi.ReactionInfoRequest) (openapi.ImplResponse, error) {
 	// DynamoDBからリアクション情報を取得する処理
-	isReactioned, err := s.db.GetReactionInfo(
+	reactionInfo, err := s.db.GetReactionInfo(
 		ctx,
 		opinionId,
 		reactionInfoRequestHeader.MailAddress,
 	)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
 	if err != nil {
 		return openapi.Response(500, nil), err
 	}
 
-	return openapi.Response(200, isReactioned), nil
+	return openapi.Response(200, s.filterReactionInfo(reactionInfo)), nil
+}
+
+// GetReactionsInfoBatch - リアクション情報一括取得API
+func (s *OpinionService) GetReactionsInfoBatch(ctx context.Context, reactionInfoBatchRequest openapi.ReactionInfoBatchRequest) (openapi.ImplResponse, error) {
+	// DynamoDBから複数の意見のリアクション情報をまとめて取得する処理
+	reactionInfos, err := s.db.GetReactionInfos(
+		ctx,
+		reactionInfoBatchRequest.OpinionIds,
+		reactionInfoBatchRequest.MailAddress,
+	)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	// 指定された順に返す（存在しない意見は含めない）
+	result := make([]infra.ReactionInfo, 0, len(reactionInfos))
+	for _, opinionId := range reactionInfoBatchRequest.OpinionIds {
+		if info, ok := reactionInfos[opinionId]; ok {
+			result = append(result, s.filterReactionInfo(info))
+		}
+	}
+	return openapi.Response(200, result), nil
+}
+
+// filterReactionInfo - 受け付けている種類のみ、リアクションがない種類も0件として返す
+// （設定から外した種類のリアクションは返さない）
+func (s *OpinionService) filterReactionInfo(info infra.ReactionInfo) infra.ReactionInfo {
+	counts := make(map[string]int32, len(s.reactionTypes))
+	for _, reactionType := range s.reactionTypes {
+		counts[reactionType] = info.Counts[reactionType]
+	}
+	info.Counts = counts
+	info.Reactions = slices.DeleteFunc(info.Reactions, func(reactionType string) bool {
+		return !slices.Contains(s.reactionTypes, reactionType)
+	})
+	return info
 }
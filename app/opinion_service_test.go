@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	openapi "user-backend/docs/gen/go"
+)
+
+func TestRequestActor(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  openapi.Claims
+		want    string
+		wantErr error
+	}{
+		{"email", openapi.Claims{"email": "a@example.com", "sub": "user-1"}, "a@example.com", nil},
+		{"sub without email", openapi.Claims{"sub": "user-1"}, "user-1", nil},
+		{"no access token", nil, "", errAuthenticationRequired},
+		{"no identity in the claims", openapi.Claims{"scope": "openid"}, "", errAuthenticationRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = openapi.WithClaims(ctx, tt.claims)
+			}
+			got, err := requestActor(ctx)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("requestActor() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
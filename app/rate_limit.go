@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	infra "user-backend/infra"
+)
+
+// RateLimitStore - レート制限のトークンバケットをDynamoDBに保存する（openapi.RateLimiterに渡す）
+type RateLimitStore struct {
+	db *infra.DynamoDBClient
+}
+
+func NewRateLimitStore(db *infra.DynamoDBClient) *RateLimitStore {
+	return &RateLimitStore{db: db}
+}
+
+// TakeTokens - 全てのバケットからトークンを1つずつ取り出し、取り出せない場合はどのバケットも消費せずに待つ時間を返す
+func (s *RateLimitStore) TakeTokens(ctx context.Context, buckets []openapi.RateLimitBucket) (time.Duration, error) {
+	tokenBuckets := make([]infra.TokenBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		tokenBuckets = append(tokenBuckets, infra.TokenBucket{Key: bucket.Key, Capacity: bucket.Limit.Requests, Per: bucket.Limit.Per})
+	}
+	return s.db.TakeTokens(ctx, tokenBuckets)
+}
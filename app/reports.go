@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	openapi "user-backend/docs/gen/go"
+	infra "user-backend/infra"
+)
+
+// DefaultReportHideThreshold - 設定がない場合に意見・コメントを自動で非表示にする通報数
+const DefaultReportHideThreshold = 5
+
+// WithReportHideThreshold - 意見・コメントを自動で非表示にする通報数を設定する
+func WithReportHideThreshold(threshold int32) OpinionServiceOption {
+	return func(s *OpinionService) {
+		s.reportHideThreshold = threshold
+	}
+}
+
+// errAlreadyReported - 同じユーザーが既に通報している
+var errAlreadyReported = openapi.NewConflictError("already_reported", "You have already reported this item.")
+
+// PostOpinionReports - 意見通報API
+func (s *OpinionService) PostOpinionReports(ctx context.Context, opinionId string, reportRequest openapi.ReportRequest) (openapi.ImplResponse, error) {
+	return s.saveReport(ctx, infra.OpinionReportTarget(opinionId), reportRequest)
+}
+
+// PostCommentReports - コメント通報API
+func (s *OpinionService) PostCommentReports(ctx context.Context, opinionId string, commentId string, reportRequest openapi.ReportRequest) (openapi.ImplResponse, error) {
+	return s.saveReport(ctx, infra.CommentReportTarget(opinionId, commentId), reportRequest)
+}
+
+// saveReport - 通報を保存し、通報数が閾値に達した場合は対象を非表示にする
+// 通報者に対象の通報数や非表示になったかどうかは返さない。
+// 通報者はアクセストークンから取得する（本文のmailAddressを使うと、値を変えるだけで同じ対象を何度でも通報できてしまう）
+func (s *OpinionService) saveReport(ctx context.Context, target infra.ReportTarget, reportRequest openapi.ReportRequest) (openapi.ImplResponse, error) {
+	reporter, code, err := s.checkUserStatus(ctx)
+	if err != nil {
+		return openapi.Response(code, nil), err
+	}
+
+	_, err = s.db.SaveReport(
+		ctx,
+		target,
+		reporter,
+		reportRequest.Reason,
+		reportRequest.Detail,
+		s.reportHideThreshold,
+	)
+	if errors.Is(err, infra.ErrOpinionNotFound) {
+		return openapi.Response(404, nil), errOpinionNotFound
+	}
+	if errors.Is(err, infra.ErrCommentNotFound) {
+		return openapi.Response(404, nil), errCommentNotFound
+	}
+	if errors.Is(err, infra.ErrAlreadyReported) {
+		return openapi.Response(409, nil), errAlreadyReported
+	}
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+
+	// 正常時は201を返す
+	return openapi.Response(201, nil), nil
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"sync"
+	"time"
+	openapi "user-backend/docs/gen/go"
+)
+
+// DefaultStatsCacheTTL - 区市町村別統計を再集計するまでの時間
+const DefaultStatsCacheTTL = 5 * time.Minute
+
+// maxStatsCacheEntries - 保持する集計期間の数（任意の期間を指定できるため上限を設ける）
+const maxStatsCacheEntries = 32
+
+// StatsCache - 区市町村別統計の集計結果を集計期間ごとに保持するキャッシュ
+// 集計は意見・コメント・リアクションの各テーブルを全件読むため、同じ期間の集計はttlの間再利用する。
+// Lambdaのインスタンス内で共有するため、コールドスタート時に作成してStatsServiceに渡す
+type StatsCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	response  openapi.AreaStatsResponse
+	expiresAt time.Time
+}
+
+// NewStatsCache - ttlが0以下の場合はキャッシュしない
+func NewStatsCache(ttl time.Duration) *StatsCache {
+	return &StatsCache{ttl: ttl, entries: make(map[string]statsCacheEntry)}
+}
+
+// key - 集計期間をキャッシュのキーにする（タイムゾーンが異なる同じ時刻は同じキー）
+func (w statsWindow) key() string {
+	return w.from.UTC().Format(time.RFC3339Nano) + "/" + w.to.UTC().Format(time.RFC3339Nano)
+}
+
+func (c *StatsCache) get(window statsWindow, now time.Time) (openapi.AreaStatsResponse, bool) {
+	if c == nil || c.ttl <= 0 {
+		return openapi.AreaStatsResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[window.key()]
+	if !ok || !now.Before(entry.expiresAt) {
+		return openapi.AreaStatsResponse{}, false
+	}
+	return entry.response, true
+}
+
+func (c *StatsCache) put(window statsWindow, response openapi.AreaStatsResponse, now time.Time) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxStatsCacheEntries {
+		// 期限切れを削除し、それでも多い場合は最も早く期限が切れるものを削除する
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+				continue
+			}
+			if oldestKey == "" || entry.expiresAt.Before(oldest) {
+				oldestKey, oldest = key, entry.expiresAt
+			}
+		}
+		if len(c.entries) >= maxStatsCacheEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[window.key()] = statsCacheEntry{response: response, expiresAt: now.Add(c.ttl)}
+}
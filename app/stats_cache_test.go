@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+	"time"
+	openapi "user-backend/docs/gen/go"
+)
+
+func TestStatsCache(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	jst := time.FixedZone("JST", 9*60*60)
+	april := statsWindow{from: now, to: now.Add(24 * time.Hour)}
+	response := openapi.AreaStatsResponse{Areas: []openapi.AreaStats{{AreaCode: "13104", OpinionCount: 3}}}
+
+	tests := []struct {
+		name   string
+		cache  *StatsCache
+		window statsWindow
+		at     time.Time
+		wantOK bool
+	}{
+		{"same window within ttl", NewStatsCache(time.Minute), april, now.Add(59 * time.Second), true},
+		{"same instant in another zone", NewStatsCache(time.Minute), statsWindow{from: now.In(jst), to: now.Add(24 * time.Hour).In(jst)}, now, true},
+		{"expired", NewStatsCache(time.Minute), april, now.Add(time.Minute), false},
+		{"another window", NewStatsCache(time.Minute), statsWindow{from: now}, now, false},
+		{"whole period is a separate window", NewStatsCache(time.Minute), statsWindow{}, now, false},
+		{"disabled", NewStatsCache(0), april, now, false},
+		{"nil cache", nil, april, now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cache.put(april, response, now)
+			got, ok := tt.cache.get(tt.window, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("get() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && (len(got.Areas) != 1 || got.Areas[0].OpinionCount != 3) {
+				t.Errorf("get() = %+v, want the stored response", got)
+			}
+		})
+	}
+}
+
+func TestStatsCacheEvictsWhenFull(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	cache := NewStatsCache(time.Hour)
+	window := func(i int) statsWindow { return statsWindow{from: now.Add(time.Duration(i) * time.Hour)} }
+
+	for i := 0; i < maxStatsCacheEntries+5; i++ {
+		cache.put(window(i), openapi.AreaStatsResponse{}, now.Add(time.Duration(i)*time.Second))
+	}
+	if len(cache.entries) != maxStatsCacheEntries {
+		t.Errorf("cache has %d entries, want %d", len(cache.entries), maxStatsCacheEntries)
+	}
+	// 最も古いものから削除される
+	at := now.Add(time.Minute)
+	if _, ok := cache.get(window(0), at); ok {
+		t.Error("oldest window was not evicted")
+	}
+	if _, ok := cache.get(window(maxStatsCacheEntries+4), at); !ok {
+		t.Error("latest window was evicted")
+	}
+}
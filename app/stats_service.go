@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+	openapi "user-backend/docs/gen/go"
+	geo "user-backend/geo"
+	infra "user-backend/infra"
+)
+
+type StatsService struct {
+	openapi.StatsAPIService
+	db *infra.DynamoDBClient
+	// 意見のない区市町村も0件として返すための境界データ（nilの場合は意見のある区市町村のみ返す）
+	areas *geo.AreaIndex
+	// 集計結果のキャッシュ（nilの場合は毎回集計する）
+	cache *StatsCache
+}
+
+// StatsServiceOption - StatsServiceの設定を変更するオプション
+type StatsServiceOption func(*StatsService)
+
+// WithStatsCache - 集計結果をキャッシュする
+func WithStatsCache(cache *StatsCache) StatsServiceOption {
+	return func(s *StatsService) {
+		s.cache = cache
+	}
+}
+
+func NewStatsService(db *infra.DynamoDBClient, areas *geo.AreaIndex, opts ...StatsServiceOption) *StatsService {
+	s := &StatsService{db: db, areas: areas}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// statsWindow - 集計期間（ゼロ値の端は制限しない）
+type statsWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+// GetAreaStats - 区市町村別統計取得API
+// 同じ期間の集計結果がキャッシュにあればテーブルを読まずに返す
+func (s *StatsService) GetAreaStats(ctx context.Context, from time.Time, to time.Time) (openapi.ImplResponse, error) {
+	window := statsWindow{from: from, to: to}
+	now := time.Now()
+	if response, ok := s.cache.get(window, now); ok {
+		return openapi.Response(200, response), nil
+	}
+
+	response, err := s.aggregateAreaStats(ctx, window)
+	if err != nil {
+		return openapi.Response(500, nil), err
+	}
+	s.cache.put(window, response, now)
+	return openapi.Response(200, response), nil
+}
+
+// aggregateAreaStats - 書き込み時に増減している区市町村ごとの件数を読み、期間内の件数を集計する
+// 件数は1時間単位で記録しているため、期間の端は1時間単位に広がる
+func (s *StatsService) aggregateAreaStats(ctx context.Context, window statsWindow) (openapi.AreaStatsResponse, error) {
+	from, to := window.from, window.to
+
+	stats := make(map[string]*openapi.AreaStats)
+	var areaCodes []string
+	if s.areas != nil {
+		for _, area := range s.areas.Areas() {
+			stats[area.Code] = &openapi.AreaStats{AreaCode: area.Code, AreaName: area.Name}
+			areaCodes = append(areaCodes, area.Code)
+		}
+	}
+
+	counts, err := s.db.GetAreaCounts(ctx, areaCodes, from, to)
+	if err != nil {
+		return openapi.AreaStatsResponse{}, err
+	}
+	for code, c := range counts {
+		area, ok := stats[code]
+		if !ok {
+			area = &openapi.AreaStats{AreaCode: code, AreaName: c.AreaName}
+			stats[code] = area
+		}
+		area.OpinionCount = c.OpinionCount
+		area.CommentCount = c.CommentCount
+		area.ReactionCount = c.ReactionCount
+	}
+
+	// 区市町村ごとに、期間内に投稿された公開中の意見のうち最もリアクションされたものを求める
+	for code, area := range stats {
+		if area.OpinionCount <= 0 {
+			continue
+		}
+		opinion, err := s.db.GetMostReactedOpinion(ctx, code, from, to)
+		if err != nil {
+			return openapi.AreaStatsResponse{}, err
+		}
+		if opinion != nil {
+			area.MostReactedOpinion = &openapi.MostReactedOpinion{
+				OpinionId:     opinion.ID,
+				Opinion:       opinion.Opinion,
+				ReactionCount: opinion.ReactionCount,
+			}
+		}
+	}
+
+	response := openapi.AreaStatsResponse{Areas: make([]openapi.AreaStats, 0, len(stats))}
+	if !from.IsZero() {
+		response.From = &from
+	}
+	if !to.IsZero() {
+		response.To = &to
+	}
+	for _, area := range stats {
+		response.Areas = append(response.Areas, *area)
+	}
+	sort.Slice(response.Areas, func(i, j int) bool {
+		return response.Areas[i].AreaCode < response.Areas[j].AreaCode
+	})
+
+	return response, nil
+}
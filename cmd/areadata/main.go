@@ -0,0 +1,83 @@
+// areadataコマンドは国土数値情報（行政区域データ N03）のGeoJSONから、
+// geoパッケージに同梱する区市町村境界データ（geo/data/tokyo_areas.geojson）を生成するCLIです。
+//
+//	go run ./cmd/areadata -i N03-20240101_13.geojson -o geo/data/tokyo_areas.geojson
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"math"
+	"os"
+
+	geo "user-backend/geo"
+)
+
+type feature struct {
+	Type       string            `json:"type"`
+	Properties map[string]string `json:"properties"`
+	Geometry   geometry          `json:"geometry"`
+}
+
+type geometry struct {
+	Type        string           `json:"type"`
+	Coordinates [][][][2]float64 `json:"coordinates"`
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+func main() {
+	input := flag.String("i", "", "入力するN03形式のGeoJSONファイル")
+	output := flag.String("o", "geo/data/tokyo_areas.geojson", "出力先ファイル")
+	precision := flag.Int("precision", 5, "座標の小数点以下の桁数（5桁で約1m）")
+	flag.Parse()
+
+	if *input == "" {
+		log.Fatal("-i is required")
+	}
+
+	idx, err := geo.LoadAreaIndexFile(*input)
+	if err != nil {
+		log.Fatalf("failed to load area data: %v", err)
+	}
+
+	scale := math.Pow10(*precision)
+	round := func(v float64) float64 { return math.Round(v*scale) / scale }
+
+	// 同じコードの区域を1つのMultiPolygonにまとめ、属性はcode・nameのみにする
+	collection := featureCollection{Type: "FeatureCollection", Features: []feature{}}
+	for _, area := range idx.Areas() {
+		var coordinates [][][][2]float64
+		for _, polygon := range area.Geometry {
+			var rings [][][2]float64
+			for _, ring := range polygon {
+				points := make([][2]float64, 0, len(ring))
+				for _, pt := range ring {
+					points = append(points, [2]float64{round(pt.Longitude), round(pt.Latitude)})
+				}
+				rings = append(rings, points)
+			}
+			coordinates = append(coordinates, rings)
+		}
+		collection.Features = append(collection.Features, feature{
+			Type:       "Feature",
+			Properties: map[string]string{"code": area.Code, "name": area.Name},
+			Geometry:   geometry{Type: "MultiPolygon", Coordinates: coordinates},
+		})
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(collection); err != nil {
+		log.Fatalf("failed to write area data: %v", err)
+	}
+	log.Printf("wrote %d areas to %s", len(collection.Features), *output)
+}
@@ -0,0 +1,53 @@
+// areatagコマンドは区市町村が付与されていない既存の意見に、投稿位置から判定した区市町村を付与するCLIです。
+//
+//	go run ./cmd/areatag
+//	go run ./cmd/areatag -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	geo "user-backend/geo"
+	infra "user-backend/infra"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "更新せずに判定結果のみ出力する")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	// 環境変数AREA_GEOJSONが指定されていればそのファイル、なければ同梱データを使う
+	areas, err := geo.LoadAreaIndex(os.Getenv("AREA_GEOJSON"))
+	if err != nil {
+		log.Fatalf("failed to load area data: %v", err)
+	}
+
+	// DynamoDB接続
+	dbClient := infra.ConnectDynamoDBService()
+
+	var tagged, skipped int
+	err = dbClient.ScanOpinions(ctx, infra.OpinionFilter{IncludeHidden: true}, func(opinion infra.OpinionItem) error {
+		if opinion.AreaCode != "" {
+			return nil
+		}
+		area, ok := areas.Resolve(geo.Point{Longitude: opinion.Coordinate.Longitude, Latitude: opinion.Coordinate.Latitude})
+		if !ok {
+			skipped++
+			return nil
+		}
+		log.Printf("%s -> %s %s", opinion.ID, area.Code, area.Name)
+		tagged++
+		if *dryRun {
+			return nil
+		}
+		return dbClient.UpdateOpinionArea(ctx, opinion.ID, infra.Area{Code: area.Code, Name: area.Name})
+	})
+	if err != nil {
+		log.Fatalf("failed to tag opinions: %v", err)
+	}
+	log.Printf("tagged: %d, outside of all areas: %d", tagged, skipped)
+}
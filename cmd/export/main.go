@@ -0,0 +1,60 @@
+// exportコマンドは意見データをファイルに書き出すCLIです。
+//
+//	go run ./cmd/export -format geojson -o opinions.geojson
+//	go run ./cmd/export -format csv -lang ja -o opinions.csv
+//	go run ./cmd/export -format kml -area 13101 -o chiyoda.kml
+//	go run ./cmd/export -format csv -category parks -tag 桜 -o parks.csv
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	app "user-backend/app"
+	export "user-backend/export"
+	infra "user-backend/infra"
+)
+
+func main() {
+	formatFlag := flag.String("format", "geojson", "出力形式 (geojson, csv, kml)")
+	lang := flag.String("lang", "en", "CSVのヘッダー言語 (ja, en)")
+	area := flag.String("area", "", "区市町村コードで絞り込む（例: 13101）")
+	category := flag.String("category", "", "カテゴリーで絞り込む（例: parks）")
+	tag := flag.String("tag", "", "ハッシュタグで絞り込む（#は省略可）")
+	output := flag.String("o", "", "出力先ファイル（省略時は標準出力）")
+	flag.Parse()
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	// DynamoDB接続
+	dbClient := infra.ConnectDynamoDBService()
+	exportService := app.NewExportService(dbClient)
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("failed to create output file: %v", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	enc := export.NewEncoder(format, w, *lang == "ja")
+	if err := exportService.WriteOpinions(ctx, enc, app.NewOpinionFilter(*area, *category, *tag)); err != nil {
+		log.Fatalf("failed to export opinions: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("failed to write output: %v", err)
+	}
+}
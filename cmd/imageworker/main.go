@@ -0,0 +1,81 @@
+// imageworkerコマンドはアップロードされた添付画像から配信用の画像（向きの補正、位置情報などのメタデータの除去、
+// サムネイル）を生成するワーカーです。
+// Lambdaとして実行した場合は、添付ファイルバケットのuploads/へのS3イベント（s3:ObjectCreated:*）を処理します。
+// ローカルでは添付ファイルを指定して実行できます（変換済みの添付ファイルの再変換にも使えます）。
+//
+//	ATTACHMENT_BUCKET=attachments go run ./cmd/imageworker -opinion <opinionId> -attachment <attachmentId>
+//	ATTACHMENT_BUCKET=attachments go run ./cmd/imageworker -key uploads/<opinionId>/<attachmentId>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	app "user-backend/app"
+	infra "user-backend/infra"
+)
+
+func main() {
+	storage := infra.ConnectS3Service()
+	if storage == nil {
+		log.Fatal("ATTACHMENT_BUCKET is not set")
+	}
+	processor := app.NewAttachmentProcessor(infra.ConnectDynamoDBService(), storage)
+
+	// Lambdaのランタイムから起動された場合
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambda.Start(func(ctx context.Context, event events.S3Event) error {
+			return handleS3Event(ctx, processor, event)
+		})
+		return
+	}
+
+	opinionId := flag.String("opinion", "", "意見ID")
+	attachmentId := flag.String("attachment", "", "添付ファイルID")
+	key := flag.String("key", "", "アップロードされたファイルのオブジェクトキー（-opinion, -attachmentの代わりに指定）")
+	flag.Parse()
+
+	if *key != "" {
+		var ok bool
+		*opinionId, *attachmentId, ok = app.ParseAttachmentObjectKey(*key)
+		if !ok {
+			log.Fatalf("invalid object key: %s", *key)
+		}
+	}
+	if *opinionId == "" || *attachmentId == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := processor.Process(context.Background(), *opinionId, *attachmentId); err != nil {
+		log.Fatalf("failed to process attachment: %v", err)
+	}
+}
+
+// handleS3Event - S3イベントのオブジェクトを順に変換する
+// 変換後の画像の保存によるイベントなど、アップロードされたファイル以外のキーは無視する
+func handleS3Event(ctx context.Context, processor *app.AttachmentProcessor, event events.S3Event) error {
+	for _, record := range event.Records {
+		// イベントのキーはURLエンコードされている
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			return fmt.Errorf("invalid object key %q: %w", record.S3.Object.Key, err)
+		}
+		opinionId, attachmentId, ok := app.ParseAttachmentObjectKey(key)
+		if !ok {
+			log.Printf("skip object %s", key)
+			continue
+		}
+		if err := processor.Process(ctx, opinionId, attachmentId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// recountコマンドはコメント・リアクションテーブルを集計し、意見に保持しているコメント数・種類ごとのリアクション数と
+// 並び替え用の属性（listKey, hotScore）を再計算するCLIです。
+// カウント導入前のデータの移行や、カウントの補正に使います。
+// -areasを指定した場合は、統計APIが読む区市町村ごとの件数（areaStatsテーブル）も集計し直します。
+//
+//	go run ./cmd/recount
+//	go run ./cmd/recount -dry-run
+//	go run ./cmd/recount -areas
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	infra "user-backend/infra"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "更新せずに差分のみ出力する")
+	areas := flag.Bool("areas", false, "区市町村ごとの件数も集計し直す")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	// DynamoDB接続
+	dbClient := infra.ConnectDynamoDBService()
+
+	counts, err := dbClient.GetOpinionCounts(ctx)
+	if err != nil {
+		log.Fatalf("failed to count comments and reactions: %v", err)
+	}
+
+	// hotScoreなどの属性を持たない古い意見もあるため、差分がなくても全件を更新する
+	var changedCount int
+	opinionAreas := make(map[string]infra.Area)
+	areaCounts := make(map[infra.AreaCountsKey]infra.AreaCounts)
+	err = dbClient.ScanOpinions(ctx, infra.OpinionFilter{IncludeHidden: true}, func(opinion infra.OpinionItem) error {
+		area := infra.Area{Code: opinion.AreaCode, Name: opinion.AreaName}
+		opinionAreas[opinion.ID] = area
+		infra.AddAreaCounts(areaCounts, area, opinion.CreatedDateTime, func(c *infra.AreaCounts) { c.OpinionCount++ })
+
+		count := counts[opinion.ID]
+		if changed(opinion, count) {
+			log.Printf("%s: comments %d -> %d, reactions %v -> %d %v", opinion.ID,
+				opinion.CommentCount, count.CommentCount, opinion.ReactionCounts, count.ReactionCount, count.TypeCounts)
+			changedCount++
+		}
+		if *dryRun {
+			return nil
+		}
+		// リアクションがなくなった種類は0に戻す
+		for reactionType := range opinion.ReactionCounts {
+			if reactionType == infra.DefaultReactionType {
+				continue
+			}
+			if _, ok := count.TypeCounts[reactionType]; !ok {
+				if count.TypeCounts == nil {
+					count.TypeCounts = make(map[string]int32)
+				}
+				count.TypeCounts[reactionType] = 0
+			}
+		}
+		return dbClient.SetOpinionCounts(ctx, opinion.ID, count, opinion.CreatedDateTime)
+	})
+	if err != nil {
+		log.Fatalf("failed to recount opinions: %v", err)
+	}
+	log.Printf("changed: %d", changedCount)
+
+	if *areas {
+		recountAreas(ctx, dbClient, opinionAreas, areaCounts, *dryRun)
+	}
+}
+
+// recountAreas - 意見の件数に、コメント・デフォルトの種類のリアクションの件数を意見の区市町村ごとに加えて保存する
+func recountAreas(ctx context.Context, dbClient *infra.DynamoDBClient, opinionAreas map[string]infra.Area, areaCounts map[infra.AreaCountsKey]infra.AreaCounts, dryRun bool) {
+	err := dbClient.ScanComments(ctx, func(comment infra.CommentItem) error {
+		infra.AddAreaCounts(areaCounts, opinionAreas[comment.ID], comment.CreatedDateTime, func(c *infra.AreaCounts) { c.CommentCount++ })
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("failed to count comments by area: %v", err)
+	}
+	err = dbClient.ScanReactions(ctx, func(reaction infra.ReactionItem) error {
+		if reaction.IsReactioned {
+			infra.AddAreaCounts(areaCounts, opinionAreas[reaction.OpinionID], reaction.ReactedDateTime, func(c *infra.AreaCounts) { c.ReactionCount++ })
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("failed to count reactions by area: %v", err)
+	}
+
+	for key, c := range areaCounts {
+		if key.Bucket == infra.AreaStatsTotalBucket {
+			log.Printf("%s %s: opinions %d, comments %d, reactions %d", c.AreaCode, c.AreaName, c.OpinionCount, c.CommentCount, c.ReactionCount)
+		}
+	}
+	if dryRun {
+		return
+	}
+	if err := dbClient.ReplaceAreaCounts(ctx, areaCounts); err != nil {
+		log.Fatalf("failed to save area counts: %v", err)
+	}
+	log.Printf("areas: %d buckets", len(areaCounts))
+}
+
+// changed - 意見に保持しているカウントが集計値と異なるかどうか
+func changed(opinion infra.OpinionItem, count infra.OpinionCounts) bool {
+	if opinion.CommentCount != count.CommentCount || opinion.ReactionCount != count.ReactionCount {
+		return true
+	}
+	for reactionType, n := range opinion.ReactionCounts {
+		if reactionType != infra.DefaultReactionType && n != count.TypeCounts[reactionType] {
+			return true
+		}
+	}
+	for reactionType, n := range count.TypeCounts {
+		if opinion.ReactionCounts[reactionType] != n {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,7 @@ package openapi
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // OpinionAPIRouter defines the required methods for binding the api requests to a responses for the OpinionAPI
@@ -24,6 +25,14 @@ type OpinionAPIRouter interface {
 	PostUserOpinions(http.ResponseWriter, *http.Request)
 	PutOpinionReactions(http.ResponseWriter, *http.Request)
 	GetOpinionReactionsInfo(http.ResponseWriter, *http.Request)
+	GetReactionsInfoBatch(http.ResponseWriter, *http.Request)
+	GetTrendingOpinions(http.ResponseWriter, *http.Request)
+	GetCategories(http.ResponseWriter, *http.Request)
+	PostOpinionAttachments(http.ResponseWriter, *http.Request)
+	PostOpinionAttachmentComplete(http.ResponseWriter, *http.Request)
+	PutCommentReactions(http.ResponseWriter, *http.Request)
+	PostOpinionReports(http.ResponseWriter, *http.Request)
+	PostCommentReports(http.ResponseWriter, *http.Request)
 }
 
 // OpinionAPIServicer defines the api actions for the OpinionAPI service
@@ -31,10 +40,77 @@ type OpinionAPIRouter interface {
 // while the service implementation can be ignored with the .openapi-generator-ignore file
 // and updated with the logic required for the API.
 type OpinionAPIServicer interface {
-	GetUserOpinions(context.Context) (ImplResponse, error)
+	GetUserOpinions(context.Context, string, string, string, string, string, int32, string) (ImplResponse, error)
 	PostUserComments(context.Context, string, CommentRequest) (ImplResponse, error)
-	GetUserComments(context.Context, string) (ImplResponse, error)
+	GetUserComments(context.Context, string, string, string, int32, int32, string, int32, string) (ImplResponse, error)
 	PostUserOpinions(context.Context, OpinionRequest) (ImplResponse, error)
 	PutOpinionReactions(context.Context, string, ReactionRequest) (ImplResponse, error)
 	GetOpinionReactionsInfo(context.Context, string, ReactionInfoRequest) (ImplResponse, error)
+	GetReactionsInfoBatch(context.Context, ReactionInfoBatchRequest) (ImplResponse, error)
+	GetTrendingOpinions(context.Context, string, int32) (ImplResponse, error)
+	GetCategories(context.Context) (ImplResponse, error)
+	PostOpinionAttachments(context.Context, string, AttachmentRequest) (ImplResponse, error)
+	PostOpinionAttachmentComplete(context.Context, string, string, AttachmentCompleteRequest) (ImplResponse, error)
+	PutCommentReactions(context.Context, string, string, CommentReactionRequest) (ImplResponse, error)
+	PostOpinionReports(context.Context, string, ReportRequest) (ImplResponse, error)
+	PostCommentReports(context.Context, string, string, ReportRequest) (ImplResponse, error)
+}
+
+// ExportAPIRouter defines the required methods for binding the api requests to a responses for the ExportAPI
+// The ExportAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a ExportAPIServicer to perform the required actions, then write the service results to the http response.
+type ExportAPIRouter interface {
+	GetOpinionsGeoJSON(http.ResponseWriter, *http.Request)
+	GetOpinionsCSV(http.ResponseWriter, *http.Request)
+	GetOpinionsKML(http.ResponseWriter, *http.Request)
+}
+
+// ExportAPIServicer defines the api actions for the ExportAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type ExportAPIServicer interface {
+	GetOpinionsGeoJSON(context.Context, string, string, string) (ImplResponse, error)
+	GetOpinionsCSV(context.Context, string, string, string, string) (ImplResponse, error)
+	GetOpinionsKML(context.Context, string, string, string) (ImplResponse, error)
+}
+
+// StatsAPIRouter defines the required methods for binding the api requests to a responses for the StatsAPI
+// The StatsAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a StatsAPIServicer to perform the required actions, then write the service results to the http response.
+type StatsAPIRouter interface {
+	GetAreaStats(http.ResponseWriter, *http.Request)
+}
+
+// StatsAPIServicer defines the api actions for the StatsAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type StatsAPIServicer interface {
+	GetAreaStats(context.Context, time.Time, time.Time) (ImplResponse, error)
+}
+
+// AdminAPIRouter defines the required methods for binding the api requests to a responses for the AdminAPI
+// The AdminAPIRouter implementation should parse necessary information from the http request,
+// pass the data to a AdminAPIServicer to perform the required actions, then write the service results to the http response.
+type AdminAPIRouter interface {
+	GetAdminReports(http.ResponseWriter, *http.Request)
+	PostAdminOpinionModeration(http.ResponseWriter, *http.Request)
+	PostAdminCommentModeration(http.ResponseWriter, *http.Request)
+	GetAdminOpinionHistory(http.ResponseWriter, *http.Request)
+	GetAdminCommentHistory(http.ResponseWriter, *http.Request)
+	PutAdminUserStatus(http.ResponseWriter, *http.Request)
+}
+
+// AdminAPIServicer defines the api actions for the AdminAPI service
+// This interface intended to stay up to date with the openapi yaml used to generate it,
+// while the service implementation can be ignored with the .openapi-generator-ignore file
+// and updated with the logic required for the API.
+type AdminAPIServicer interface {
+	GetAdminReports(context.Context, int32, string) (ImplResponse, error)
+	PostAdminOpinionModeration(context.Context, string, ModerationRequest) (ImplResponse, error)
+	PostAdminCommentModeration(context.Context, string, string, ModerationRequest) (ImplResponse, error)
+	GetAdminOpinionHistory(context.Context, string) (ImplResponse, error)
+	GetAdminCommentHistory(context.Context, string, string) (ImplResponse, error)
+	PutAdminUserStatus(context.Context, UserStatusRequest) (ImplResponse, error)
 }
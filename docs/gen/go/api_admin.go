@@ -0,0 +1,307 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminPathPrefix is the path prefix of the admin routes
+const AdminPathPrefix = "/admin"
+
+const (
+	// DefaultAdminRoleClaim is the JWT claim holding the roles of the caller (Cognito user pool groups)
+	DefaultAdminRoleClaim = "cognito:groups"
+	// DefaultAdminRole is the role required to call the admin routes
+	DefaultAdminRole = "admin"
+)
+
+// AdminAPIController binds http requests to an api service and writes the service results to the http response
+type AdminAPIController struct {
+	service      AdminAPIServicer
+	errorHandler ErrorHandler
+	roleClaim    string
+	role         string
+}
+
+// AdminAPIOption for how the controller is set up.
+type AdminAPIOption func(*AdminAPIController)
+
+// WithAdminAPIErrorHandler inject ErrorHandler into controller
+func WithAdminAPIErrorHandler(h ErrorHandler) AdminAPIOption {
+	return func(c *AdminAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// WithAdminAPIRole sets the JWT claim and the role required to call the admin routes
+func WithAdminAPIRole(claim string, role string) AdminAPIOption {
+	return func(c *AdminAPIController) {
+		c.roleClaim = claim
+		c.role = role
+	}
+}
+
+// NewAdminAPIController creates a default api controller
+func NewAdminAPIController(s AdminAPIServicer, opts ...AdminAPIOption) Router {
+	controller := &AdminAPIController{
+		service:      s,
+		errorHandler: DefaultErrorHandler,
+		roleClaim:    DefaultAdminRoleClaim,
+		role:         DefaultAdminRole,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// PathPrefix returns the path prefix shared by the admin routes
+func (c *AdminAPIController) PathPrefix() string {
+	return AdminPathPrefix
+}
+
+// Middleware rejects requests without the admin role claim
+func (c *AdminAPIController) Middleware(inner http.Handler) http.Handler {
+	return RequireRole(c.roleClaim, c.role, c.errorHandler)(inner)
+}
+
+// Routes returns all the api routes for the AdminAPIController
+func (c *AdminAPIController) Routes() Routes {
+	return Routes{
+		"GetAdminReports": Route{
+			strings.ToUpper("Get"),
+			"/admin/reports",
+			c.GetAdminReports,
+		},
+		"PostAdminOpinionModeration": Route{
+			strings.ToUpper("Post"),
+			"/admin/opinions/{opinionId}/moderation",
+			c.PostAdminOpinionModeration,
+		},
+		"PostAdminCommentModeration": Route{
+			strings.ToUpper("Post"),
+			"/admin/opinions/{opinionId}/comments/{commentId}/moderation",
+			c.PostAdminCommentModeration,
+		},
+		"GetAdminOpinionHistory": Route{
+			strings.ToUpper("Get"),
+			"/admin/opinions/{opinionId}/history",
+			c.GetAdminOpinionHistory,
+		},
+		"GetAdminCommentHistory": Route{
+			strings.ToUpper("Get"),
+			"/admin/opinions/{opinionId}/comments/{commentId}/history",
+			c.GetAdminCommentHistory,
+		},
+		"PutAdminUserStatus": Route{
+			strings.ToUpper("Put"),
+			"/admin/users/status",
+			c.PutAdminUserStatus,
+		},
+	}
+}
+
+// GetAdminReports - 通報キュー取得API
+func (c *AdminAPIController) GetAdminReports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limitParam, err := parseNumericParameter[int32](
+		query.Get("limit"),
+		WithDefaultOrParse[int32](20, parseInt32),
+		WithMinimum[int32](1),
+		WithMaximum[int32](100),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	cursorParam := query.Get("cursor")
+	result, err := c.service.GetAdminReports(r.Context(), limitParam, cursorParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	WriteResponseHeaders(w, result.Headers)
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostAdminOpinionModeration - 意見モデレーションAPI
+func (c *AdminAPIController) PostAdminOpinionModeration(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	moderationRequestParam := ModerationRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&moderationRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	moderationRequestParam.Normalize()
+	if err := AssertModerationRequestRequired(moderationRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertModerationRequestConstraints(moderationRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostAdminOpinionModeration(r.Context(), opinionIdParam, moderationRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostAdminCommentModeration - コメントモデレーションAPI
+func (c *AdminAPIController) PostAdminCommentModeration(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentIdParam := params["commentId"]
+	if commentIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"commentId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("commentId", commentIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	moderationRequestParam := ModerationRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&moderationRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	moderationRequestParam.Normalize()
+	if err := AssertModerationRequestRequired(moderationRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertModerationRequestConstraints(moderationRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostAdminCommentModeration(r.Context(), opinionIdParam, commentIdParam, moderationRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// GetAdminOpinionHistory - 意見モデレーション履歴取得API
+func (c *AdminAPIController) GetAdminOpinionHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	result, err := c.service.GetAdminOpinionHistory(r.Context(), opinionIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// GetAdminCommentHistory - コメントモデレーション履歴取得API
+func (c *AdminAPIController) GetAdminCommentHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentIdParam := params["commentId"]
+	if commentIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"commentId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("commentId", commentIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	result, err := c.service.GetAdminCommentHistory(r.Context(), opinionIdParam, commentIdParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PutAdminUserStatus - ユーザー利用状態変更API
+func (c *AdminAPIController) PutAdminUserStatus(w http.ResponseWriter, r *http.Request) {
+	userStatusRequestParam := UserStatusRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&userStatusRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	userStatusRequestParam.Normalize()
+	if err := AssertUserStatusRequestRequired(userStatusRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertUserStatusRequestConstraints(userStatusRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PutAdminUserStatus(r.Context(), userStatusRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -0,0 +1,93 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// AdminAPIService is a service that implements the logic for the AdminAPIServicer
+// This service should implement the business logic for every endpoint for the AdminAPI API.
+// Include any external packages or services that will be required by this service.
+type AdminAPIService struct {
+}
+
+// NewAdminAPIService creates a default api service
+func NewAdminAPIService() AdminAPIServicer {
+	return &AdminAPIService{}
+}
+
+// GetAdminReports - 通報キュー取得API
+func (s *AdminAPIService) GetAdminReports(ctx context.Context, limit int32, cursor string) (ImplResponse, error) {
+	// TODO - update GetAdminReports with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, []ReportedItem{}) or use other options such as http.Ok ...
+	// return Response(200, []ReportedItem{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetAdminReports method not implemented")
+}
+
+// PostAdminOpinionModeration - 意見モデレーションAPI
+func (s *AdminAPIService) PostAdminOpinionModeration(ctx context.Context, opinionId string, moderationRequest ModerationRequest) (ImplResponse, error) {
+	// TODO - update PostAdminOpinionModeration with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, {}) or use other options such as http.Ok ...
+	// return Response(200, {}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostAdminOpinionModeration method not implemented")
+}
+
+// PostAdminCommentModeration - コメントモデレーションAPI
+func (s *AdminAPIService) PostAdminCommentModeration(ctx context.Context, opinionId string, commentId string, moderationRequest ModerationRequest) (ImplResponse, error) {
+	// TODO - update PostAdminCommentModeration with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, {}) or use other options such as http.Ok ...
+	// return Response(200, {}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostAdminCommentModeration method not implemented")
+}
+
+// GetAdminOpinionHistory - 意見モデレーション履歴取得API
+func (s *AdminAPIService) GetAdminOpinionHistory(ctx context.Context, opinionId string) (ImplResponse, error) {
+	// TODO - update GetAdminOpinionHistory with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, ModerationHistory{}) or use other options such as http.Ok ...
+	// return Response(200, ModerationHistory{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetAdminOpinionHistory method not implemented")
+}
+
+// GetAdminCommentHistory - コメントモデレーション履歴取得API
+func (s *AdminAPIService) GetAdminCommentHistory(ctx context.Context, opinionId string, commentId string) (ImplResponse, error) {
+	// TODO - update GetAdminCommentHistory with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, ModerationHistory{}) or use other options such as http.Ok ...
+	// return Response(200, ModerationHistory{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetAdminCommentHistory method not implemented")
+}
+
+// PutAdminUserStatus - ユーザー利用状態変更API
+func (s *AdminAPIService) PutAdminUserStatus(ctx context.Context, userStatusRequest UserStatusRequest) (ImplResponse, error) {
+	// TODO - update PutAdminUserStatus with the required logic for this service method.
+	// Add api_admin_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, UserStatus{}) or use other options such as http.Ok ...
+	// return Response(200, UserStatus{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PutAdminUserStatus method not implemented")
+}
@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// adminReportsStub returns an empty report queue
+type adminReportsStub struct {
+	AdminAPIService
+	called bool
+}
+
+func (s *adminReportsStub) GetAdminReports(ctx context.Context, limit int32, cursor string) (ImplResponse, error) {
+	s.called = true
+	return Response(200, []any{}), nil
+}
+
+func TestAdminRoutesRequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     map[string]string
+		wantStatus int
+	}{
+		{"not authenticated", nil, http.StatusUnauthorized},
+		{"without the role", map[string]string{"sub": "user-1", DefaultAdminRoleClaim: "[editor]"}, http.StatusForbidden},
+		{"with the role", map[string]string{"sub": "admin-1", DefaultAdminRoleClaim: "[editor admin]"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &adminReportsStub{}
+			router := NewRouter(NewAdminAPIController(service))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/reports", nil)
+			if tt.claims != nil {
+				req = req.WithContext(WithClaims(req.Context(), tt.claims))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if service.called != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("service called = %v", service.called)
+			}
+		})
+	}
+}
+
+// ungroupedAdminRouter exposes an admin route without implementing RouteGroup
+type ungroupedAdminRouter struct{}
+
+func (ungroupedAdminRouter) Routes() Routes {
+	return Routes{
+		"GetAdminReports": Route{http.MethodGet, "/admin/reports", func(w http.ResponseWriter, r *http.Request) {}},
+	}
+}
+
+func TestNewRouterRejectsUngroupedAdminRoutes(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewRouter registered an admin route without the authorization middleware")
+		}
+	}()
+	NewRouter(ungroupedAdminRouter{})
+}
+
+func TestIsAdminPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"/admin", true},
+		{"/admin/reports", true},
+		{"/administrators", false},
+		{"/user/opinions", false},
+	}
+	for _, tt := range tests {
+		if got := isAdminPath(tt.pattern); got != tt.want {
+			t.Errorf("isAdminPath(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
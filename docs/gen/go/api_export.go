@@ -0,0 +1,159 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+var errInvalidLang = errors.New("lang must be either 'ja' or 'en'")
+
+// ExportAPIController binds http requests to an api service and writes the service results to the http response
+type ExportAPIController struct {
+	service      ExportAPIServicer
+	errorHandler ErrorHandler
+}
+
+// ExportAPIOption for how the controller is set up.
+type ExportAPIOption func(*ExportAPIController)
+
+// WithExportAPIErrorHandler inject ErrorHandler into controller
+func WithExportAPIErrorHandler(h ErrorHandler) ExportAPIOption {
+	return func(c *ExportAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewExportAPIController creates a default api controller
+func NewExportAPIController(s ExportAPIServicer, opts ...ExportAPIOption) Router {
+	controller := &ExportAPIController{
+		service:      s,
+		errorHandler: DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the ExportAPIController
+func (c *ExportAPIController) Routes() Routes {
+	return Routes{
+		"GetOpinionsGeoJSON": Route{
+			strings.ToUpper("Get"),
+			"/user/opinions.geojson",
+			c.GetOpinionsGeoJSON,
+		},
+		"GetOpinionsCSV": Route{
+			strings.ToUpper("Get"),
+			"/user/opinions.csv",
+			c.GetOpinionsCSV,
+		},
+		"GetOpinionsKML": Route{
+			strings.ToUpper("Get"),
+			"/user/opinions.kml",
+			c.GetOpinionsKML,
+		},
+	}
+}
+
+// GetOpinionsGeoJSON - 意見GeoJSONエクスポートAPI
+func (c *ExportAPIController) GetOpinionsGeoJSON(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	areaParam, err := parseAreaParameter(query.Get("area"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	categoryParam, err := parseCategoryParameter(query.Get("category"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tagParam := parseTagParameter(query.Get("tag"))
+	result, err := c.service.GetOpinionsGeoJSON(r.Context(), areaParam, categoryParam, tagParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, stream the body and the result code
+	c.encodeStreamResponse(w, result)
+}
+
+// GetOpinionsCSV - 意見CSVエクスポートAPI
+func (c *ExportAPIController) GetOpinionsCSV(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	areaParam, err := parseAreaParameter(query.Get("area"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	categoryParam, err := parseCategoryParameter(query.Get("category"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tagParam := parseTagParameter(query.Get("tag"))
+	langParam := query.Get("lang")
+	if langParam != "" && langParam != "ja" && langParam != "en" {
+		c.errorHandler(w, r, &ParsingError{Err: errInvalidLang}, nil)
+		return
+	}
+	result, err := c.service.GetOpinionsCSV(r.Context(), areaParam, categoryParam, tagParam, langParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, stream the body and the result code
+	c.encodeStreamResponse(w, result)
+}
+
+// GetOpinionsKML - 意見KMLエクスポートAPI
+func (c *ExportAPIController) GetOpinionsKML(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	areaParam, err := parseAreaParameter(query.Get("area"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	categoryParam, err := parseCategoryParameter(query.Get("category"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tagParam := parseTagParameter(query.Get("tag"))
+	result, err := c.service.GetOpinionsKML(r.Context(), areaParam, categoryParam, tagParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, stream the body and the result code
+	c.encodeStreamResponse(w, result)
+}
+
+// encodeStreamResponse writes a StreamBody result. Errors after streaming has started can only be logged.
+func (c *ExportAPIController) encodeStreamResponse(w http.ResponseWriter, result ImplResponse) {
+	body, ok := result.Body.(StreamBody)
+	if !ok {
+		EncodeJSONResponse(result.Body, &result.Code, w)
+		return
+	}
+	if err := EncodeStreamResponse(body, &result.Code, w); err != nil {
+		log.Printf("failed to stream export response: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ExportAPIService is a service that implements the logic for the ExportAPIServicer
+// This service should implement the business logic for every endpoint for the ExportAPI API.
+// Include any external packages or services that will be required by this service.
+type ExportAPIService struct {
+}
+
+// NewExportAPIService creates a default api service
+func NewExportAPIService() ExportAPIServicer {
+	return &ExportAPIService{}
+}
+
+// GetOpinionsGeoJSON - 意見GeoJSONエクスポートAPI
+func (s *ExportAPIService) GetOpinionsGeoJSON(ctx context.Context, area string, category string, tag string) (ImplResponse, error) {
+	// TODO - update GetOpinionsGeoJSON with the required logic for this service method.
+	// Add api_export_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetOpinionsGeoJSON method not implemented")
+}
+
+// GetOpinionsCSV - 意見CSVエクスポートAPI
+func (s *ExportAPIService) GetOpinionsCSV(ctx context.Context, area string, category string, tag string, lang string) (ImplResponse, error) {
+	// TODO - update GetOpinionsCSV with the required logic for this service method.
+	// Add api_export_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetOpinionsCSV method not implemented")
+}
+
+// GetOpinionsKML - 意見KMLエクスポートAPI
+func (s *ExportAPIService) GetOpinionsKML(ctx context.Context, area string, category string, tag string) (ImplResponse, error) {
+	// TODO - update GetOpinionsKML with the required logic for this service method.
+	// Add api_export_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetOpinionsKML method not implemented")
+}
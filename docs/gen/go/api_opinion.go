@@ -56,6 +56,16 @@ func (c *OpinionAPIController) Routes() Routes {
 			"/user/opinions",
 			c.GetUserOpinions,
 		},
+		"GetTrendingOpinions": Route{
+			strings.ToUpper("Get"),
+			"/user/opinions/trending",
+			c.GetTrendingOpinions,
+		},
+		"GetCategories": Route{
+			strings.ToUpper("Get"),
+			"/user/categories",
+			c.GetCategories,
+		},
 		"PostUserOpinions": Route{
 			strings.ToUpper("Post"),
 			"/user/opinions",
@@ -71,6 +81,21 @@ func (c *OpinionAPIController) Routes() Routes {
 			"/user/opinions/{opinionId}/comments",
 			c.GetUserComments,
 		},
+		"PutCommentReactions": Route{
+			strings.ToUpper("Put"),
+			"/user/opinions/{opinionId}/comments/{commentId}/reactions",
+			c.PutCommentReactions,
+		},
+		"PostOpinionReports": Route{
+			strings.ToUpper("Post"),
+			"/user/opinions/{opinionId}/reports",
+			c.PostOpinionReports,
+		},
+		"PostCommentReports": Route{
+			strings.ToUpper("Post"),
+			"/user/opinions/{opinionId}/comments/{commentId}/reports",
+			c.PostCommentReports,
+		},
 		"PutOpinionReactions": Route{
 			strings.ToUpper("Put"),
 			"/user/opinions/{opinionId}/reactions",
@@ -81,12 +106,98 @@ func (c *OpinionAPIController) Routes() Routes {
 			"/user/opinions/{opinionId}/reactions",
 			c.GetOpinionReactionsInfo,
 		},
+		"GetReactionsInfoBatch": Route{
+			strings.ToUpper("Get"),
+			"/user/reactions",
+			c.GetReactionsInfoBatch,
+		},
+		"PostOpinionAttachments": Route{
+			strings.ToUpper("Post"),
+			"/user/opinions/{opinionId}/attachments",
+			c.PostOpinionAttachments,
+		},
+		"PostOpinionAttachmentComplete": Route{
+			strings.ToUpper("Post"),
+			"/user/opinions/{opinionId}/attachments/{attachmentId}/complete",
+			c.PostOpinionAttachmentComplete,
+		},
 	}
 }
 
 // GetUserOpinions - ユーザー意見取得API
 func (c *OpinionAPIController) GetUserOpinions(w http.ResponseWriter, r *http.Request) {
-	result, err := c.service.GetUserOpinions(r.Context())
+	query := r.URL.Query()
+	areaParam, err := parseAreaParameter(query.Get("area"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	categoryParam, err := parseCategoryParameter(query.Get("category"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	tagParam := parseTagParameter(query.Get("tag"))
+	sortParam, err := parseEnumParameter("sort", query.Get("sort"), "new", "top", "hot", "discussed")
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	limitParam, err := parseNumericParameter[int32](
+		query.Get("limit"),
+		WithDefaultOrParse[int32](0, parseInt32),
+		WithMinimum[int32](1),
+		WithMaximum[int32](100),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	cursorParam := query.Get("cursor")
+	// 閲覧ユーザーは検証済みのトークンから取得する（認証済みの場合は自分の承認待ち・却下の意見も返す）
+	viewer := ClaimsFromContext(r.Context()).Actor()
+	result, err := c.service.GetUserOpinions(r.Context(), viewer, areaParam, categoryParam, tagParam, sortParam, limitParam, cursorParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	WriteResponseHeaders(w, result.Headers)
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// GetTrendingOpinions - 急上昇の意見取得API
+func (c *OpinionAPIController) GetTrendingOpinions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	windowParam, err := parseEnumParameter("window", query.Get("window"), "24h", "7d")
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	limitParam, err := parseNumericParameter[int32](
+		query.Get("limit"),
+		WithDefaultOrParse[int32](20, parseInt32),
+		WithMinimum[int32](1),
+		WithMaximum[int32](50),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	result, err := c.service.GetTrendingOpinions(r.Context(), windowParam, limitParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// GetCategories - カテゴリー一覧取得API
+func (c *OpinionAPIController) GetCategories(w http.ResponseWriter, r *http.Request) {
+	result, err := c.service.GetCategories(r.Context())
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
@@ -104,6 +215,10 @@ func (c *OpinionAPIController) PostUserComments(w http.ResponseWriter, r *http.R
 		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
 		return
 	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
 	commentRequestParam := CommentRequest{}
 	d := json.NewDecoder(r.Body)
 	d.DisallowUnknownFields()
@@ -111,6 +226,7 @@ func (c *OpinionAPIController) PostUserComments(w http.ResponseWriter, r *http.R
 		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
 		return
 	}
+	commentRequestParam.Normalize()
 	if err := AssertCommentRequestRequired(commentRequestParam); err != nil {
 		c.errorHandler(w, r, err, nil)
 		return
@@ -137,13 +253,70 @@ func (c *OpinionAPIController) GetUserComments(w http.ResponseWriter, r *http.Re
 		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
 		return
 	}
-	result, err := c.service.GetUserComments(r.Context(), opinionIdParam)
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	query := r.URL.Query()
+	viewParam, err := parseEnumParameter("view", query.Get("view"), "flat", "tree")
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	depthParam, err := parseNumericParameter[int32](
+		query.Get("depth"),
+		WithDefaultOrParse[int32](3, parseInt32),
+		WithMinimum[int32](0),
+		WithMaximum[int32](3),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	replyLimitParam, err := parseNumericParameter[int32](
+		query.Get("replyLimit"),
+		WithDefaultOrParse[int32](3, parseInt32),
+		WithMinimum[int32](0),
+		WithMaximum[int32](20),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	parentCommentIdParam := query.Get("parentCommentId")
+	if parentCommentIdParam != "" {
+		if err := assertUUIDParameter("parentCommentId", parentCommentIdParam); err != nil {
+			c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+			return
+		}
+	}
+	limitParam, err := parseNumericParameter[int32](
+		query.Get("limit"),
+		WithDefaultOrParse[int32](20, parseInt32),
+		WithMinimum[int32](1),
+		WithMaximum[int32](100),
+	)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	cursorParam := query.Get("cursor")
+	// mailAddressをヘッダーから取得（指定した場合はコメントごとの自分のリアクション状態を返す）
+	mailAddress := strings.TrimSpace(r.Header.Get("mailAddress"))
+	if mailAddress != "" {
+		if err := ValidateSchemaValue("MailAddress", "mailAddress", mailAddress); err != nil {
+			c.errorHandler(w, r, err, nil)
+			return
+		}
+	}
+	result, err := c.service.GetUserComments(r.Context(), opinionIdParam, mailAddress, viewParam, depthParam, replyLimitParam, parentCommentIdParam, limitParam, cursorParam)
 	// If an error occurred, encode the error with the status code
 	if err != nil {
 		c.errorHandler(w, r, err, &result)
 		return
 	}
 	// If no error, encode the body and the result code
+	WriteResponseHeaders(w, result.Headers)
 	EncodeJSONResponse(result.Body, &result.Code, w)
 }
 
@@ -156,6 +329,7 @@ func (c *OpinionAPIController) PostUserOpinions(w http.ResponseWriter, r *http.R
 		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
 		return
 	}
+	opinionRequestParam.Normalize()
 	if err := AssertOpinionRequestRequired(opinionRequestParam); err != nil {
 		c.errorHandler(w, r, err, nil)
 		return
@@ -182,6 +356,10 @@ func (c *OpinionAPIController) PutOpinionReactions(w http.ResponseWriter, r *htt
 		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
 		return
 	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
 	reactionRequestParam := ReactionRequest{}
 	d := json.NewDecoder(r.Body)
 	d.DisallowUnknownFields()
@@ -189,6 +367,7 @@ func (c *OpinionAPIController) PutOpinionReactions(w http.ResponseWriter, r *htt
 		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
 		return
 	}
+	reactionRequestParam.Normalize()
 	if err := AssertReactionRequestRequired(reactionRequestParam); err != nil {
 		c.errorHandler(w, r, err, nil)
 		return
@@ -215,9 +394,13 @@ func (c *OpinionAPIController) GetOpinionReactionsInfo(w http.ResponseWriter, r
 		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
 		return
 	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
 
 	// mailAddressをヘッダーから取得
-	mailAddress := r.Header.Get("mailAddress")
+	mailAddress := strings.TrimSpace(r.Header.Get("mailAddress"))
 	if mailAddress == "" {
 		c.errorHandler(w, r, &RequiredError{"mailAddress"}, nil)
 		return
@@ -244,3 +427,252 @@ func (c *OpinionAPIController) GetOpinionReactionsInfo(w http.ResponseWriter, r
 	// If no error, encode the body and the result code
 	EncodeJSONResponse(result.Body, &result.Code, w)
 }
+
+// GetReactionsInfoBatch - リアクション情報一括取得API
+func (c *OpinionAPIController) GetReactionsInfoBatch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opinionIdsParam, err := parseUUIDArrayParameter("opinionIds", query.Get("opinionIds"), maxBatchOpinionIds)
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+
+	// mailAddressをヘッダーから取得
+	reactionInfoBatchRequestParam := ReactionInfoBatchRequest{
+		MailAddress: strings.TrimSpace(r.Header.Get("mailAddress")),
+		OpinionIds:  opinionIdsParam,
+	}
+	if err := AssertReactionInfoBatchRequestRequired(reactionInfoBatchRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertReactionInfoBatchRequestConstraints(reactionInfoBatchRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.GetReactionsInfoBatch(r.Context(), reactionInfoBatchRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostOpinionAttachments - 添付ファイルアップロードURL発行API
+func (c *OpinionAPIController) PostOpinionAttachments(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	attachmentRequestParam := AttachmentRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&attachmentRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	attachmentRequestParam.Normalize()
+	if err := AssertAttachmentRequestRequired(attachmentRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertAttachmentRequestConstraints(attachmentRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostOpinionAttachments(r.Context(), opinionIdParam, attachmentRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostOpinionAttachmentComplete - 添付ファイルアップロード完了通知API
+func (c *OpinionAPIController) PostOpinionAttachmentComplete(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	attachmentIdParam := params["attachmentId"]
+	if attachmentIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"attachmentId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("attachmentId", attachmentIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	attachmentCompleteRequestParam := AttachmentCompleteRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&attachmentCompleteRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	attachmentCompleteRequestParam.Normalize()
+	if err := AssertAttachmentCompleteRequestRequired(attachmentCompleteRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertAttachmentCompleteRequestConstraints(attachmentCompleteRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostOpinionAttachmentComplete(r.Context(), opinionIdParam, attachmentIdParam, attachmentCompleteRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PutCommentReactions - コメントリアクションAPI
+func (c *OpinionAPIController) PutCommentReactions(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentIdParam := params["commentId"]
+	if commentIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"commentId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("commentId", commentIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentReactionRequestParam := CommentReactionRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&commentReactionRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentReactionRequestParam.Normalize()
+	if err := AssertCommentReactionRequestRequired(commentReactionRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertCommentReactionRequestConstraints(commentReactionRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PutCommentReactions(r.Context(), opinionIdParam, commentIdParam, commentReactionRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostOpinionReports - 意見通報API
+func (c *OpinionAPIController) PostOpinionReports(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	reportRequestParam := ReportRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&reportRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	reportRequestParam.Normalize()
+	if err := AssertReportRequestRequired(reportRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertReportRequestConstraints(reportRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostOpinionReports(r.Context(), opinionIdParam, reportRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
+
+// PostCommentReports - コメント通報API
+func (c *OpinionAPIController) PostCommentReports(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	opinionIdParam := params["opinionId"]
+	if opinionIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"opinionId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("opinionId", opinionIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	commentIdParam := params["commentId"]
+	if commentIdParam == "" {
+		c.errorHandler(w, r, &RequiredError{"commentId"}, nil)
+		return
+	}
+	if err := assertUUIDParameter("commentId", commentIdParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	reportRequestParam := ReportRequest{}
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&reportRequestParam); err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	reportRequestParam.Normalize()
+	if err := AssertReportRequestRequired(reportRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	if err := AssertReportRequestConstraints(reportRequestParam); err != nil {
+		c.errorHandler(w, r, err, nil)
+		return
+	}
+	result, err := c.service.PostCommentReports(r.Context(), opinionIdParam, commentIdParam, reportRequestParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
@@ -27,7 +27,7 @@ func NewOpinionAPIService() OpinionAPIServicer {
 }
 
 // GetUserOpinions - ユーザー意見取得API
-func (s *OpinionAPIService) GetUserOpinions(ctx context.Context) (ImplResponse, error) {
+func (s *OpinionAPIService) GetUserOpinions(ctx context.Context, viewer string, area string, category string, tag string, sort string, limit int32, cursor string) (ImplResponse, error) {
 	// TODO - update GetUserOpinions with the required logic for this service method.
 	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
 
@@ -49,7 +49,7 @@ func (s *OpinionAPIService) PostUserComments(ctx context.Context, opinionId stri
 }
 
 // GetUserComments - コメント取得API
-func (s *OpinionAPIService) GetUserComments(ctx context.Context, opinionId string) (ImplResponse, error) {
+func (s *OpinionAPIService) GetUserComments(ctx context.Context, opinionId string, mailAddress string, view string, depth int32, replyLimit int32, parentCommentId string, limit int32, cursor string) (ImplResponse, error) {
 	// TODO - update PostUserComments with the required logic for this service method.
 	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
 
@@ -75,6 +75,39 @@ func (s *OpinionAPIService) GetOpinionReactionsInfo(context.Context, string, Rea
 	return Response(http.StatusNotImplemented, nil), errors.New("GetUserOpinions method not implemented")
 }
 
+// GetCategories - カテゴリー一覧取得API
+func (s *OpinionAPIService) GetCategories(ctx context.Context) (ImplResponse, error) {
+	// TODO - update GetCategories with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, []CategoryCount{}) or use other options such as http.Ok ...
+	// return Response(200, []CategoryCount{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetCategories method not implemented")
+}
+
+// GetTrendingOpinions - 急上昇の意見取得API
+func (s *OpinionAPIService) GetTrendingOpinions(ctx context.Context, window string, limit int32) (ImplResponse, error) {
+	// TODO - update GetTrendingOpinions with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, []TrendingOpinion{}) or use other options such as http.Ok ...
+	// return Response(200, []TrendingOpinion{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetTrendingOpinions method not implemented")
+}
+
+// GetReactionsInfoBatch - リアクション情報一括取得API
+func (s *OpinionAPIService) GetReactionsInfoBatch(ctx context.Context, reactionInfoBatchRequest ReactionInfoBatchRequest) (ImplResponse, error) {
+	// TODO - update GetReactionsInfoBatch with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, []ReactionInfo{}) or use other options such as http.Ok ...
+	// return Response(200, []ReactionInfo{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetReactionsInfoBatch method not implemented")
+}
+
 // PutOpinionReactions - リアクションAPI
 func (s *OpinionAPIService) PutOpinionReactions(ctx context.Context, opinionId string, reactionRequest ReactionRequest) (ImplResponse, error) {
 	// TODO - update PutOpinionReactions with the required logic for this service method.
@@ -85,3 +118,58 @@ func (s *OpinionAPIService) PutOpinionReactions(ctx context.Context, opinionId s
 
 	return Response(http.StatusNotImplemented, nil), errors.New("PutOpinionReactions method not implemented")
 }
+
+// PostOpinionAttachments - 添付ファイルアップロードURL発行API
+func (s *OpinionAPIService) PostOpinionAttachments(ctx context.Context, opinionId string, attachmentRequest AttachmentRequest) (ImplResponse, error) {
+	// TODO - update PostOpinionAttachments with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(201, AttachmentUpload{}) or use other options such as http.Ok ...
+	// return Response(201, AttachmentUpload{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostOpinionAttachments method not implemented")
+}
+
+// PostOpinionAttachmentComplete - 添付ファイルアップロード完了通知API
+func (s *OpinionAPIService) PostOpinionAttachmentComplete(ctx context.Context, opinionId string, attachmentId string, attachmentCompleteRequest AttachmentCompleteRequest) (ImplResponse, error) {
+	// TODO - update PostOpinionAttachmentComplete with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, Attachment{}) or use other options such as http.Ok ...
+	// return Response(200, Attachment{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostOpinionAttachmentComplete method not implemented")
+}
+
+// PutCommentReactions - コメントリアクションAPI
+func (s *OpinionAPIService) PutCommentReactions(ctx context.Context, opinionId string, commentId string, commentReactionRequest CommentReactionRequest) (ImplResponse, error) {
+	// TODO - update PutCommentReactions with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(201, PutOpinionReactions201Response{}) or use other options such as http.Ok ...
+	// return Response(201, PutOpinionReactions201Response{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PutCommentReactions method not implemented")
+}
+
+// PostOpinionReports - 意見通報API
+func (s *OpinionAPIService) PostOpinionReports(ctx context.Context, opinionId string, reportRequest ReportRequest) (ImplResponse, error) {
+	// TODO - update PostOpinionReports with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(201, {}) or use other options such as http.Ok ...
+	// return Response(201, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostOpinionReports method not implemented")
+}
+
+// PostCommentReports - コメント通報API
+func (s *OpinionAPIService) PostCommentReports(ctx context.Context, opinionId string, commentId string, reportRequest ReportRequest) (ImplResponse, error) {
+	// TODO - update PostCommentReports with the required logic for this service method.
+	// Add api_opinion_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(201, {}) or use other options such as http.Ok ...
+	// return Response(201, nil),nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("PostCommentReports method not implemented")
+}
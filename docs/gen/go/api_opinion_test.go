@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// viewerRecorder records the viewer passed to the service
+type viewerRecorder struct {
+	OpinionAPIService
+	viewer *string
+}
+
+func (s *viewerRecorder) GetUserOpinions(ctx context.Context, viewer string, area string, category string, tag string, sort string, limit int32, cursor string) (ImplResponse, error) {
+	s.viewer = &viewer
+	return Response(200, []Opinion{}), nil
+}
+
+func TestGetUserOpinionsViewer(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     map[string]string
+		header     string
+		wantViewer string
+	}{
+		{"anonymous", nil, "", ""},
+		{"mailAddress header is ignored", nil, "victim@example.com", ""},
+		{"authenticated by email", map[string]string{"sub": "user-1", "email": "author@example.com"}, "", "author@example.com"},
+		{"claims take precedence over the header", map[string]string{"email": "author@example.com"}, "victim@example.com", "author@example.com"},
+		{"authenticated without email", map[string]string{"sub": "user-1"}, "", "user-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &viewerRecorder{}
+			router := NewRouter(NewOpinionAPIController(service))
+
+			req := httptest.NewRequest(http.MethodGet, "/user/opinions", nil)
+			if tt.header != "" {
+				req.Header.Set("mailAddress", tt.header)
+			}
+			if tt.claims != nil {
+				req = req.WithContext(WithClaims(req.Context(), tt.claims))
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			if service.viewer == nil || *service.viewer != tt.wantViewer {
+				t.Errorf("viewer = %v, want %q", service.viewer, tt.wantViewer)
+			}
+		})
+	}
+}
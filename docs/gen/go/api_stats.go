@@ -0,0 +1,84 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// StatsAPIController binds http requests to an api service and writes the service results to the http response
+type StatsAPIController struct {
+	service      StatsAPIServicer
+	errorHandler ErrorHandler
+}
+
+// StatsAPIOption for how the controller is set up.
+type StatsAPIOption func(*StatsAPIController)
+
+// WithStatsAPIErrorHandler inject ErrorHandler into controller
+func WithStatsAPIErrorHandler(h ErrorHandler) StatsAPIOption {
+	return func(c *StatsAPIController) {
+		c.errorHandler = h
+	}
+}
+
+// NewStatsAPIController creates a default api controller
+func NewStatsAPIController(s StatsAPIServicer, opts ...StatsAPIOption) Router {
+	controller := &StatsAPIController{
+		service:      s,
+		errorHandler: DefaultErrorHandler,
+	}
+
+	for _, opt := range opts {
+		opt(controller)
+	}
+
+	return controller
+}
+
+// Routes returns all the api routes for the StatsAPIController
+func (c *StatsAPIController) Routes() Routes {
+	return Routes{
+		"GetAreaStats": Route{
+			strings.ToUpper("Get"),
+			"/stats/areas",
+			c.GetAreaStats,
+		},
+	}
+}
+
+// GetAreaStats - 区市町村別統計取得API
+func (c *StatsAPIController) GetAreaStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	fromParam, err := parseTime(query.Get("from"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	toParam, err := parseTime(query.Get("to"))
+	if err != nil {
+		c.errorHandler(w, r, &ParsingError{Err: err}, nil)
+		return
+	}
+	if !fromParam.IsZero() && !toParam.IsZero() && !fromParam.Before(toParam) {
+		c.errorHandler(w, r, &ParsingError{Err: errors.New("from must be before to")}, nil)
+		return
+	}
+	result, err := c.service.GetAreaStats(r.Context(), fromParam, toParam)
+	// If an error occurred, encode the error with the status code
+	if err != nil {
+		c.errorHandler(w, r, err, &result)
+		return
+	}
+	// If no error, encode the body and the result code
+	EncodeJSONResponse(result.Body, &result.Code, w)
+}
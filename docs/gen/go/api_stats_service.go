@@ -0,0 +1,39 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// StatsAPIService is a service that implements the logic for the StatsAPIServicer
+// This service should implement the business logic for every endpoint for the StatsAPI API.
+// Include any external packages or services that will be required by this service.
+type StatsAPIService struct {
+}
+
+// NewStatsAPIService creates a default api service
+func NewStatsAPIService() StatsAPIServicer {
+	return &StatsAPIService{}
+}
+
+// GetAreaStats - 区市町村別統計取得API
+func (s *StatsAPIService) GetAreaStats(ctx context.Context, from time.Time, to time.Time) (ImplResponse, error) {
+	// TODO - update GetAreaStats with the required logic for this service method.
+	// Add api_stats_service.go to the .openapi-generator-ignore to avoid overwriting this service implementation when updating open api generation.
+
+	// TODO: Uncomment the next line to return response Response(200, AreaStatsResponse{}) or use other options such as http.Ok ...
+	// return Response(200, AreaStatsResponse{}), nil
+
+	return Response(http.StatusNotImplemented, nil), errors.New("GetAreaStats method not implemented")
+}
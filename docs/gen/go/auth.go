@@ -0,0 +1,76 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Claims are the verified JWT claims of the caller (API Gateway passes array claims as "[a b]")
+type Claims map[string]string
+
+type claimsKey struct{}
+
+// WithClaims stores the verified claims in the context
+func WithClaims(ctx context.Context, claims map[string]string) context.Context {
+	return context.WithValue(ctx, claimsKey{}, Claims(claims))
+}
+
+// ClaimsFromContext returns the claims stored by WithClaims (nil when the request was not authenticated)
+func ClaimsFromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// Values splits a claim into its values. Array claims ("[admin editor]") and comma or space separated claims are supported.
+func (c Claims) Values(name string) []string {
+	return strings.FieldsFunc(c[name], func(r rune) bool {
+		return r == '[' || r == ']' || r == ',' || r == ' '
+	})
+}
+
+// HasRole reports whether the claim contains the role
+func (c Claims) HasRole(claim string, role string) bool {
+	return slices.Contains(c.Values(claim), role)
+}
+
+// Actor returns the identity of the caller recorded in audit logs (email, or sub when email is not present)
+func (c Claims) Actor() string {
+	if email := c["email"]; email != "" {
+		return email
+	}
+	return c["sub"]
+}
+
+var (
+	errAuthenticationRequired = NewUnauthorizedError("authentication_required", "A valid access token is required.")
+	errRoleRequired           = NewForbiddenError("role_required", "The access token does not have the required role.")
+)
+
+// RequireRole returns a middleware rejecting requests without claims (401) or whose claim does not contain the role (403)
+func RequireRole(claim string, role string, errorHandler ErrorHandler) func(http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := ClaimsFromContext(r.Context())
+			if claims == nil {
+				errorHandler(w, r, errAuthenticationRequired, nil)
+				return
+			}
+			if !claims.HasRole(claim, role) {
+				errorHandler(w, r, errRoleRequired, nil)
+				return
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
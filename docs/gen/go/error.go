@@ -10,9 +10,14 @@
 package openapi
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 var (
@@ -42,21 +47,211 @@ func (e *RequiredError) Error() string {
 	return fmt.Sprintf("required field '%s' is zero value.", e.Field)
 }
 
+// ErrorKind classifies an APIError and decides its http status code
+type ErrorKind int
+
+const (
+	// ErrorKindInternal is an unexpected failure. Details are logged but never returned to clients.
+	ErrorKindInternal ErrorKind = iota
+	ErrorKindNotFound
+	ErrorKindConflict
+	ErrorKindValidation
+	ErrorKindForbidden
+	ErrorKindRateLimited
+	ErrorKindUnauthorized
+)
+
+// Status returns the http status code of the kind
+func (k ErrorKind) Status() int {
+	switch k {
+	case ErrorKindNotFound:
+		return http.StatusNotFound
+	case ErrorKindConflict:
+		return http.StatusConflict
+	case ErrorKindValidation:
+		return http.StatusUnprocessableEntity
+	case ErrorKindForbidden:
+		return http.StatusForbidden
+	case ErrorKindRateLimited:
+		return http.StatusTooManyRequests
+	case ErrorKindUnauthorized:
+		return http.StatusUnauthorized
+	}
+	return http.StatusInternalServerError
+}
+
+// APIError is an error returned by services that is safe to expose to clients as a problem response
+type APIError struct {
+	Kind ErrorKind
+	// Code is a stable machine readable error code (e.g. opinion_not_found)
+	Code string
+	// Detail is a human readable explanation returned to clients
+	Detail string
+	// FieldErrors describes invalid fields for validation errors
+	FieldErrors []FieldError
+	// RetryAfter is sent as the Retry-After header for rate limited errors
+	RetryAfter time.Duration
+	// Extensions are additional members of the problem response (e.g. the id of a conflicting resource)
+	Extensions map[string]interface{}
+	// Err is the internal cause. It is logged but never returned to clients.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Detail, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// NewNotFoundError creates an APIError for a missing resource
+func NewNotFoundError(code, detail string) *APIError {
+	return &APIError{Kind: ErrorKindNotFound, Code: code, Detail: detail}
+}
+
+// NewConflictError creates an APIError for a request conflicting with the current state
+func NewConflictError(code, detail string) *APIError {
+	return &APIError{Kind: ErrorKindConflict, Code: code, Detail: detail}
+}
+
+// NewValidationError creates an APIError for a semantically invalid request
+func NewValidationError(code, detail string, fieldErrors ...FieldError) *APIError {
+	return &APIError{Kind: ErrorKindValidation, Code: code, Detail: detail, FieldErrors: fieldErrors}
+}
+
+// NewForbiddenError creates an APIError for a request the caller is not allowed to make
+func NewForbiddenError(code, detail string) *APIError {
+	return &APIError{Kind: ErrorKindForbidden, Code: code, Detail: detail}
+}
+
+// NewUnauthorizedError creates an APIError for a request without valid credentials
+func NewUnauthorizedError(code, detail string) *APIError {
+	return &APIError{Kind: ErrorKindUnauthorized, Code: code, Detail: detail}
+}
+
+// NewRateLimitedError creates an APIError for a request exceeding the rate limit
+func NewRateLimitedError(code, detail string, retryAfter time.Duration) *APIError {
+	return &APIError{Kind: ErrorKindRateLimited, Code: code, Detail: detail, RetryAfter: retryAfter}
+}
+
+// NewInternalError wraps an unexpected error. Only a generic message is returned to clients.
+func NewInternalError(err error) *APIError {
+	return &APIError{Kind: ErrorKindInternal, Code: "internal_error", Detail: "An unexpected error occurred.", Err: err}
+}
+
+// Problem is a RFC 7807 problem details object
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestId string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+
+	extensions map[string]interface{}
+}
+
+// MarshalJSON adds the extension members to the problem object
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	b, err := json.Marshal(problem(p))
+	if err != nil || len(p.extensions) == 0 {
+		return b, err
+	}
+	members := map[string]interface{}{}
+	for k, v := range p.extensions {
+		members[k] = v
+	}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// EncodeProblemResponse writes a problem as application/problem+json
+func EncodeProblemResponse(problem Problem, w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/problem+json; charset=UTF-8")
+	w.WriteHeader(problem.Status)
+	return json.NewEncoder(w).Encode(problem)
+}
+
 // ErrorHandler defines the required method for handling error. You may implement it and inject this into a controller if
 // you would like errors to be handled differently from the DefaultErrorHandler
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error, result *ImplResponse)
 
-// DefaultErrorHandler defines the default logic on how to handle errors from the controller. Any errors from parsing
-// request params will return a StatusBadRequest. Otherwise, the error code originating from the servicer will be used.
+// DefaultErrorHandler defines the default logic on how to handle errors from the controller. Errors are returned as
+// RFC 7807 problem details: parsing errors as 400, missing or invalid fields as 422, APIError by its kind, and any other
+// error as 500 (or the client error code originating from the servicer). Internal details are logged, never returned.
 func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error, result *ImplResponse) {
-	if _, ok := err.(*ParsingError); ok {
-		// Handle parsing errors
-		EncodeJSONResponse(err.Error(), func(i int) *int { return &i }(http.StatusBadRequest), w)
-	} else if _, ok := err.(*RequiredError); ok {
-		// Handle missing required errors
-		EncodeJSONResponse(err.Error(), func(i int) *int { return &i }(http.StatusUnprocessableEntity), w)
+	requestId := RequestIDFromContext(r.Context())
+	problem := problemFromError(err, result)
+	problem.Instance = r.URL.Path
+	problem.RequestId = requestId
+
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("request_id=%s %s %s failed: status=%d error=%v", requestId, r.Method, r.URL.Path, problem.Status, err)
 	} else {
-		// Handle all other errors
-		EncodeJSONResponse(err.Error(), &result.Code, w)
+		log.Printf("request_id=%s %s %s rejected: status=%d code=%s error=%v", requestId, r.Method, r.URL.Path, problem.Status, problem.Code, err)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(apiErr.RetryAfter.Seconds()))))
+	}
+	EncodeProblemResponse(problem, w)
+}
+
+func newProblem(status int, code string, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func problemFromError(err error, result *ImplResponse) Problem {
+	var (
+		parsingErr    *ParsingError
+		requiredErr   *RequiredError
+		validationErr *ValidationError
+		apiErr        *APIError
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		status := apiErr.Kind.Status()
+		if apiErr.Kind == ErrorKindInternal {
+			return newProblem(status, "internal_error", "An unexpected error occurred.")
+		}
+		problem := newProblem(status, apiErr.Code, apiErr.Detail)
+		problem.Errors = apiErr.FieldErrors
+		problem.extensions = apiErr.Extensions
+		return problem
+	case errors.As(err, &parsingErr):
+		return newProblem(http.StatusBadRequest, "invalid_request", err.Error())
+	case errors.As(err, &requiredErr):
+		problem := newProblem(http.StatusUnprocessableEntity, "validation_failed", "Required field is missing.")
+		problem.Errors = []FieldError{{Field: requiredErr.Field, Code: "required", Message: "is required"}}
+		return problem
+	case errors.As(err, &validationErr):
+		problem := newProblem(http.StatusUnprocessableEntity, "validation_failed", "Request does not satisfy the constraints.")
+		problem.Errors = validationErr.Errors
+		return problem
+	}
+
+	// 型のないエラーの詳細は返さない（ステータスはサービスが返したコードを使う）
+	if result != nil && result.Code >= 400 && result.Code < 500 {
+		return newProblem(result.Code, "request_failed", "")
+	}
+	if result != nil && result.Code >= 500 {
+		return newProblem(result.Code, "internal_error", "An unexpected error occurred.")
 	}
+	return newProblem(http.StatusInternalServerError, "internal_error", "An unexpected error occurred.")
 }
@@ -0,0 +1,236 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader is the request header identifying retries of the same write request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to "true" on responses replayed from a previous request with the same key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// DefaultIdempotencyTTL is how long the first response is kept for retries
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength is the maximum length of an idempotency key
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentResponseSize is the maximum size of a response body stored for retries. Larger responses are not stored.
+const maxIdempotentResponseSize = 256 << 10
+
+// IdempotencyRecord is the stored state of an idempotency key
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request (method, path and body) that first used the key
+	Fingerprint string
+	// Completed is false while the first request is still being processed
+	Completed  bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore keeps the responses of write requests shared by every instance
+type IdempotencyStore interface {
+	// Reserve claims the key for a request. It returns a reservation token when the key was claimed, or the existing record when the key is already used.
+	// A claim whose request did not finish expires so that the key can be claimed again.
+	Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (reservation string, existing *IdempotencyRecord, err error)
+	// Complete stores the response of the request that claimed the key. It fails without storing when the claim has been taken over by another request.
+	Complete(ctx context.Context, key string, reservation string, record IdempotencyRecord, ttl time.Duration) error
+	// Release removes the claim so that the request can be retried (e.g. after a server error). A claim taken over by another request is kept.
+	Release(ctx context.Context, key string, reservation string) error
+}
+
+var (
+	errInvalidIdempotencyKey = errors.New("Idempotency-Key must be 1 to 255 printable ASCII characters")
+	errIdempotencyKeyReused  = NewValidationError(
+		"idempotency_key_reused",
+		"The Idempotency-Key has already been used for a different request.",
+		FieldError{Field: IdempotencyKeyHeader, Code: "reused", Message: "was used with a different method, path or body"},
+	)
+	errIdempotentRequestInProgress = NewConflictError(
+		"idempotent_request_in_progress",
+		"A request with the same Idempotency-Key is still being processed.",
+	)
+)
+
+// Idempotency wraps a handler so that write requests (POST, PUT, PATCH, DELETE) with an Idempotency-Key header are processed once.
+// The first response is stored for the ttl and replayed for retries with the same key and body.
+// Reusing a key with a different method, path or body is rejected with 422, and a retry while the first request is running with 409.
+// Server errors and rate limited responses are not stored so that the request can be retried.
+// Keys are scoped to the authenticated caller, or to the source IP for unauthenticated requests. When the store fails the request is processed without idempotency.
+func Idempotency(next http.Handler, store IdempotencyStore, ttl time.Duration, errorHandler ErrorHandler) http.Handler {
+	reject := func(w http.ResponseWriter, r *http.Request, err error) {
+		WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			errorHandler(w, r, err, nil)
+		})).ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+		if idempotencyKey == "" || !isWriteMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !isValidIdempotencyKey(idempotencyKey) {
+			reject(w, r, &ParsingError{Err: errInvalidIdempotencyKey})
+			return
+		}
+
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				reject(w, r, &ParsingError{Err: err})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		key := hashIdempotency(idempotencyScope(r), idempotencyKey)
+		fingerprint := hashIdempotency(r.Method, r.URL.Path, string(body))
+
+		reservation, existing, err := store.Reserve(r.Context(), key, fingerprint, ttl)
+		if err != nil {
+			log.Printf("idempotency reserve failed for %s %s: %v", r.Method, r.URL.Path, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				reject(w, r, errIdempotencyKeyReused)
+			case !existing.Completed:
+				inProgress := *errIdempotentRequestInProgress
+				inProgress.RetryAfter = time.Second
+				reject(w, r, &inProgress)
+			default:
+				replayResponse(w, r, *existing)
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Server errors and rate limited responses may change on retry, so they are not stored
+		if recorder.statusCode >= http.StatusInternalServerError || recorder.statusCode == http.StatusTooManyRequests || recorder.overflow {
+			if err := store.Release(r.Context(), key, reservation); err != nil {
+				log.Printf("idempotency release failed for %s %s: %v", r.Method, r.URL.Path, err)
+			}
+			return
+		}
+		header := recorder.Header().Clone()
+		header.Del(RequestIDHeader)
+		record := IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			StatusCode:  recorder.statusCode,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		}
+		if err := store.Complete(r.Context(), key, reservation, record, ttl); err != nil {
+			log.Printf("idempotency complete failed for %s %s: %v", r.Method, r.URL.Path, err)
+		}
+	})
+}
+
+// idempotencyScope returns the caller owning the keys of the request
+func idempotencyScope(r *http.Request) string {
+	if identity := callerIdentity(r); identity != "" {
+		return "id#" + identity
+	}
+	return "ip#" + sourceIP(r)
+}
+
+// replayResponse writes the stored response (the request id of the retry is kept)
+func replayResponse(w http.ResponseWriter, r *http.Request, record IdempotencyRecord) {
+	WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, values := range record.Header {
+			w.Header()[name] = values
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(record.StatusCode)
+		w.Write(record.Body)
+	})).ServeHTTP(w, r)
+}
+
+// responseRecorder writes the response through and keeps a copy to store it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if r.body.Len()+len(b) > maxIdempotentResponseSize {
+		r.overflow = true
+	} else {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// hashIdempotency hashes the parts so that neither the caller nor the body is stored as is
+func hashIdempotency(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,239 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore
+type memoryIdempotencyStore struct {
+	records      map[string]IdempotencyRecord
+	reservations map[string]string
+	issued       int
+	err          error
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]IdempotencyRecord{}, reservations: map[string]string{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, key string, fingerprint string, ttl time.Duration) (string, *IdempotencyRecord, error) {
+	if s.err != nil {
+		return "", nil, s.err
+	}
+	if record, ok := s.records[key]; ok {
+		return "", &record, nil
+	}
+	return s.claim(key, fingerprint), nil, nil
+}
+
+// claim stores a new claim of the key, as Reserve does after the previous claim expired
+func (s *memoryIdempotencyStore) claim(key string, fingerprint string) string {
+	s.issued++
+	reservation := fmt.Sprintf("r%d", s.issued)
+	s.records[key] = IdempotencyRecord{Fingerprint: fingerprint}
+	s.reservations[key] = reservation
+	return reservation
+}
+
+func (s *memoryIdempotencyStore) Complete(ctx context.Context, key string, reservation string, record IdempotencyRecord, ttl time.Duration) error {
+	if s.reservations[key] != reservation || s.records[key].Completed {
+		return errors.New("reservation lost")
+	}
+	s.records[key] = record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string, reservation string) error {
+	if s.reservations[key] == reservation && !s.records[key].Completed {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		method   string
+		key      string
+		body     string
+		actor    string
+		ip       string
+		status   int  // ハンドラーが返すステータス
+		want     int  // クライアントが受け取るステータス
+		replayed bool // 保存したレスポンスを返したかどうか
+		handled  bool // ハンドラーが呼ばれたかどうか
+	}
+	tests := []struct {
+		name     string
+		requests []request
+	}{
+		{"retry replays the first response", []request{
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.2", 500, 201, true, false},
+		}},
+		{"key reused with another body", []request{
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+			{"POST", "k1", `{"a":2}`, "a@example.com", "192.0.2.1", 201, 422, false, false},
+		}},
+		{"server errors are released", []request{
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 500, 500, false, true},
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 500, 201, true, false},
+		}},
+		{"rate limited responses are released", []request{
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 429, 429, false, true},
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+		}},
+		{"client errors are stored", []request{
+			{"POST", "k1", `{}`, "a@example.com", "192.0.2.1", 422, 422, false, true},
+			{"POST", "k1", `{}`, "a@example.com", "192.0.2.1", 201, 422, true, false},
+		}},
+		{"keys are scoped to the caller", []request{
+			{"POST", "k1", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+			{"POST", "k1", `{"a":1}`, "b@example.com", "192.0.2.1", 201, 201, false, true},
+		}},
+		{"unauthenticated keys are scoped to the source ip", []request{
+			{"POST", "k1", `{"a":1}`, "", "192.0.2.1", 201, 201, false, true},
+			{"POST", "k1", `{"a":1}`, "", "192.0.2.2", 201, 201, false, true},
+			{"POST", "k1", `{"a":1}`, "", "192.0.2.1", 201, 201, true, false},
+		}},
+		{"requests without a key are not stored", []request{
+			{"POST", "", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+			{"POST", "", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 201, false, true},
+		}},
+		{"read requests are not stored", []request{
+			{"GET", "k1", ``, "a@example.com", "192.0.2.1", 200, 200, false, true},
+			{"GET", "k1", ``, "a@example.com", "192.0.2.1", 200, 200, false, true},
+		}},
+		{"invalid key", []request{
+			{"POST", "key with spaces", `{"a":1}`, "a@example.com", "192.0.2.1", 201, 400, false, false},
+			{"POST", strings.Repeat("k", 256), `{"a":1}`, "a@example.com", "192.0.2.1", 201, 400, false, false},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryIdempotencyStore()
+			stored := map[string]string{} // 呼び出し元ごとに保存されているはずのレスポンスの本文
+			for i, req := range tt.requests {
+				handled := false
+				next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					handled = true
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(req.status)
+					w.Write([]byte(`{"request":` + string(rune('0'+i)) + `}`))
+				})
+				handler := Idempotency(next, store, time.Hour, DefaultErrorHandler)
+
+				r := httptest.NewRequest(req.method, "/user/opinions", strings.NewReader(req.body))
+				r.RemoteAddr = req.ip + ":12345"
+				if req.key != "" {
+					r.Header.Set(IdempotencyKeyHeader, req.key)
+				}
+				if req.actor != "" {
+					r = r.WithContext(WithClaims(r.Context(), map[string]string{"email": req.actor}))
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+
+				if rec.Code != req.want {
+					t.Fatalf("request %d: status = %d, want %d: %s", i, rec.Code, req.want, rec.Body.String())
+				}
+				if got := rec.Header().Get(IdempotentReplayedHeader) == "true"; got != req.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, got, req.replayed)
+				}
+				if handled != req.handled {
+					t.Errorf("request %d: handled = %v, want %v", i, handled, req.handled)
+				}
+				caller := req.actor
+				if caller == "" {
+					caller = req.ip
+				}
+				if req.replayed && rec.Body.String() != stored[caller] {
+					t.Errorf("request %d: body = %s, want the stored response %s", i, rec.Body.String(), stored[caller])
+				}
+				if handled && req.status < 500 && req.status != http.StatusTooManyRequests {
+					stored[caller] = rec.Body.String()
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	var handler http.Handler
+	var inner *httptest.ResponseRecorder
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 最初のリクエストの処理中に同じキーで再送する
+		if inner == nil {
+			inner = httptest.NewRecorder()
+			retry := httptest.NewRequest(http.MethodPost, "/user/opinions", strings.NewReader(`{}`))
+			retry.Header.Set(IdempotencyKeyHeader, "k1")
+			handler.ServeHTTP(inner, retry)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler = Idempotency(next, store, time.Hour, DefaultErrorHandler)
+
+	r := httptest.NewRequest(http.MethodPost, "/user/opinions", strings.NewReader(`{}`))
+	r.Header.Set(IdempotencyKeyHeader, "k1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("first request status = %d, want 201", rec.Code)
+	}
+	if inner.Code != http.StatusConflict || inner.Header().Get("Retry-After") != "1" {
+		t.Errorf("retry status = %d, Retry-After = %q, want 409 and 1", inner.Code, inner.Header().Get("Retry-After"))
+	}
+}
+
+func TestIdempotencyStoreFailure(t *testing.T) {
+	store := &memoryIdempotencyStore{err: errors.New("store unavailable")}
+	handled := 0
+	handler := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled++
+		w.WriteHeader(http.StatusCreated)
+	}), store, time.Hour, DefaultErrorHandler)
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/user/opinions", strings.NewReader(`{}`))
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want the request processed without idempotency", rec.Code)
+		}
+	}
+	if handled != 2 {
+		t.Errorf("handled %d requests, want 2", handled)
+	}
+}
+
+func TestIdempotencyLostReservation(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	var key string
+	handler := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 処理に時間がかかり予約の期限が切れ、同じキーの再送が予約し直した
+		for k, record := range store.records {
+			key = k
+			store.claim(k, record.Fingerprint)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}), store, time.Hour, DefaultErrorHandler)
+
+	r := httptest.NewRequest(http.MethodPost, "/user/opinions", strings.NewReader(`{}`))
+	r.Header.Set(IdempotencyKeyHeader, "k1")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	// 期限切れの予約のレスポンスで、予約し直したリクエストの分を上書きしない
+	if record := store.records[key]; record.Completed {
+		t.Errorf("record = %+v, want the new claim kept in progress", record)
+	}
+}
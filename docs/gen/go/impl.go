@@ -9,8 +9,14 @@
 
 package openapi
 
+import (
+	"net/http"
+)
+
 // ImplResponse defines an implementation response with error code and the associated body
 type ImplResponse struct {
 	Code int
 	Body interface{}
+	// Headers are added to the http response (e.g. the cursor of the next page)
+	Headers http.Header
 }
@@ -22,7 +22,8 @@ func Logger(inner http.Handler, name string) http.Handler {
 		inner.ServeHTTP(w, r)
 
 		log.Printf(
-			"%s %s %s %s",
+			"%s %s %s %s %s",
+			RequestIDFromContext(r.Context()),
 			r.Method,
 			r.RequestURI,
 			name,
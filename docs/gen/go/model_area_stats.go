@@ -0,0 +1,61 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+// AreaStatsResponse - 区市町村別統計
+type AreaStatsResponse struct {
+
+	// 集計期間の開始日時（未指定の場合は省略）
+	From *time.Time `json:"from,omitempty"`
+
+	// 集計期間の終了日時（未指定の場合は省略）
+	To *time.Time `json:"to,omitempty"`
+
+	Areas []AreaStats `json:"areas"`
+}
+
+// AreaStats - 区市町村ごとの集計値
+type AreaStats struct {
+
+	// 区市町村コード
+	AreaCode string `json:"areaCode"`
+
+	// 区市町村名
+	AreaName string `json:"areaName"`
+
+	// 期間内に投稿された意見数
+	OpinionCount int32 `json:"opinionCount"`
+
+	// 期間内に投稿されたコメント数
+	CommentCount int32 `json:"commentCount"`
+
+	// 期間内のリアクション数
+	ReactionCount int32 `json:"reactionCount"`
+
+	// 期間内に最もリアクションされた意見（リアクションがない場合はnull）
+	MostReactedOpinion *MostReactedOpinion `json:"mostReactedOpinion"`
+}
+
+// MostReactedOpinion - 期間内に最もリアクションされた意見
+type MostReactedOpinion struct {
+
+	// 投稿を識別するid
+	OpinionId string `json:"opinionId"`
+
+	// 投稿内容
+	Opinion string `json:"opinion"`
+
+	// 期間内のリアクション数
+	ReactionCount int32 `json:"reactionCount"`
+}
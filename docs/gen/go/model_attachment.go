@@ -0,0 +1,48 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type Attachment struct {
+
+	// 添付ファイルを識別するid
+	AttachmentId string `json:"attachmentId"`
+
+	// ファイルの形式
+	ContentType string `json:"contentType"`
+
+	// 表示用のURL（画像の変換中は省略）
+	Url string `json:"url,omitempty"`
+
+	// サムネイルの名前とURL（画像の変換中は省略）
+	Thumbnails map[string]string `json:"thumbnails,omitempty"`
+
+	// 画像の変換中かどうか
+	Processing bool `json:"processing"`
+}
+
+// AssertAttachmentRequired checks if the required fields are not zero-ed
+func AssertAttachmentRequired(obj Attachment) error {
+	elements := map[string]interface{}{
+		"attachmentId": obj.AttachmentId,
+		"contentType":  obj.ContentType,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertAttachmentConstraints checks if the values respects the defined constraints
+func AssertAttachmentConstraints(obj Attachment) error {
+	return nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+)
+
+type AttachmentCompleteRequest struct {
+
+	// 投稿ユーザーのメールアドレス(本人情報)
+	MailAddress string `json:"mailAddress"`
+}
+
+// AssertAttachmentCompleteRequestRequired checks if the required fields are not zero-ed
+func AssertAttachmentCompleteRequestRequired(obj AttachmentCompleteRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertAttachmentCompleteRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertAttachmentCompleteRequestConstraints(obj AttachmentCompleteRequest) error {
+	v := newSchemaValidator("AttachmentCompleteRequest")
+	v.String("mailAddress", obj.MailAddress)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *AttachmentCompleteRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+}
@@ -0,0 +1,58 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+)
+
+type AttachmentRequest struct {
+
+	// 投稿ユーザーのメールアドレス(本人情報)
+	MailAddress string `json:"mailAddress"`
+
+	// 添付するファイルの形式
+	ContentType string `json:"contentType"`
+
+	// ファイルサイズ（バイト）
+	Size int64 `json:"size"`
+}
+
+// AssertAttachmentRequestRequired checks if the required fields are not zero-ed
+func AssertAttachmentRequestRequired(obj AttachmentRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+		"contentType": obj.ContentType,
+		"size":        obj.Size,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertAttachmentRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertAttachmentRequestConstraints(obj AttachmentRequest) error {
+	v := newSchemaValidator("AttachmentRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.String("contentType", obj.ContentType)
+	size := float64(obj.Size)
+	v.Number("size", &size)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *AttachmentRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.ContentType = strings.TrimSpace(obj.ContentType)
+}
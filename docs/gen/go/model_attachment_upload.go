@@ -0,0 +1,54 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AttachmentUpload struct {
+
+	// 添付ファイルを識別するid
+	AttachmentId string `json:"attachmentId"`
+
+	// アップロード先の署名付きURL
+	UploadUrl string `json:"uploadUrl"`
+
+	// アップロードに使うHTTPメソッド
+	Method string `json:"method"`
+
+	// アップロード時にそのまま送る必要があるヘッダー
+	Headers map[string]string `json:"headers"`
+
+	// uploadUrlの有効期限
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AssertAttachmentUploadRequired checks if the required fields are not zero-ed
+func AssertAttachmentUploadRequired(obj AttachmentUpload) error {
+	elements := map[string]interface{}{
+		"attachmentId": obj.AttachmentId,
+		"uploadUrl":    obj.UploadUrl,
+		"method":       obj.Method,
+		"expiresAt":    obj.ExpiresAt,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertAttachmentUploadConstraints checks if the values respects the defined constraints
+func AssertAttachmentUploadConstraints(obj AttachmentUpload) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type CategoryCount struct {
+
+	// カテゴリーのコード
+	Code string `json:"code"`
+
+	// カテゴリーの表示名
+	Name string `json:"name"`
+
+	// カテゴリーの意見数
+	OpinionCount int32 `json:"opinionCount"`
+}
+
+// AssertCategoryCountRequired checks if the required fields are not zero-ed
+func AssertCategoryCountRequired(obj CategoryCount) error {
+	elements := map[string]interface{}{
+		"code": obj.Code,
+		"name": obj.Name,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertCategoryCountConstraints checks if the values respects the defined constraints
+func AssertCategoryCountConstraints(obj CategoryCount) error {
+	return nil
+}
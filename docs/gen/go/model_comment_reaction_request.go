@@ -0,0 +1,51 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+)
+
+type CommentReactionRequest struct {
+
+	// 投稿ユーザーのメールアドレス(本人情報)
+	MailAddress string `json:"mailAddress"`
+
+	// リアクション
+	// falseはリアクションの取り消しのため、未指定と区別できるようポインタで受け取る
+	Reaction *bool `json:"reaction"`
+}
+
+// AssertCommentReactionRequestRequired checks if the required fields are not zero-ed
+func AssertCommentReactionRequestRequired(obj CommentReactionRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+		"reaction":    obj.Reaction,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertCommentReactionRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertCommentReactionRequestConstraints(obj CommentReactionRequest) error {
+	v := newSchemaValidator("CommentReactionRequest")
+	v.String("mailAddress", obj.MailAddress)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *CommentReactionRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+}
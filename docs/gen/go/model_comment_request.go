@@ -9,8 +9,9 @@
 
 package openapi
 
-
-
+import (
+	"strings"
+)
 
 type CommentRequest struct {
 
@@ -19,6 +20,9 @@ type CommentRequest struct {
 
 	// コメント内容
 	Comment string `json:"comment"`
+
+	// 返信先のコメントのid（意見へのコメントの場合は省略）
+	ParentCommentId string `json:"parentCommentId,omitempty"`
 }
 
 // AssertCommentRequestRequired checks if the required fields are not zero-ed
@@ -36,7 +40,18 @@ func AssertCommentRequestRequired(obj CommentRequest) error {
 	return nil
 }
 
-// AssertCommentRequestConstraints checks if the values respects the defined constraints
+// AssertCommentRequestConstraints checks if the values respects the constraints defined in openapi.yaml
 func AssertCommentRequestConstraints(obj CommentRequest) error {
-	return nil
+	v := newSchemaValidator("CommentRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.String("comment", obj.Comment)
+	v.String("parentCommentId", obj.ParentCommentId)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *CommentRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.Comment = strings.TrimSpace(obj.Comment)
+	obj.ParentCommentId = strings.TrimSpace(obj.ParentCommentId)
 }
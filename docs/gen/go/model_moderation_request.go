@@ -0,0 +1,51 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+)
+
+type ModerationRequest struct {
+
+	// 操作（hide: 非表示、unhide: 表示に戻す、delete: 削除）
+	Action string `json:"action"`
+
+	// 操作の理由などのメモ（履歴に残す）
+	Note string `json:"note,omitempty"`
+}
+
+// AssertModerationRequestRequired checks if the required fields are not zero-ed
+func AssertModerationRequestRequired(obj ModerationRequest) error {
+	elements := map[string]interface{}{
+		"action": obj.Action,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertModerationRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertModerationRequestConstraints(obj ModerationRequest) error {
+	v := newSchemaValidator("ModerationRequest")
+	v.String("action", obj.Action)
+	v.String("note", obj.Note)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *ModerationRequest) Normalize() {
+	obj.Action = strings.TrimSpace(obj.Action)
+	obj.Note = strings.TrimSpace(obj.Note)
+}
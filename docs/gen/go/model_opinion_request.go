@@ -9,8 +9,9 @@
 
 package openapi
 
-
-
+import (
+	"strings"
+)
 
 type OpinionRequest struct {
 
@@ -21,6 +22,12 @@ type OpinionRequest struct {
 
 	// 投稿内容
 	Opinion string `json:"opinion"`
+
+	// カテゴリー（省略可）
+	Category string `json:"category,omitempty"`
+
+	// 近くで投稿された似た意見があっても投稿する（同じユーザーの重複投稿は拒否する）
+	IgnoreSimilar bool `json:"ignoreSimilar,omitempty"`
 }
 
 // AssertOpinionRequestRequired checks if the required fields are not zero-ed
@@ -42,7 +49,21 @@ func AssertOpinionRequestRequired(obj OpinionRequest) error {
 	return nil
 }
 
-// AssertOpinionRequestConstraints checks if the values respects the defined constraints
+// AssertOpinionRequestConstraints checks if the values respects the constraints defined in openapi.yaml
 func AssertOpinionRequestConstraints(obj OpinionRequest) error {
-	return nil
+	v := newSchemaValidator("OpinionRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.Object("coordinate", func(cv *schemaValidator) {
+		validateOpinionRequestCoordinate(cv, obj.Coordinate)
+	})
+	v.String("opinion", obj.Opinion)
+	v.String("category", obj.Category)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *OpinionRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.Opinion = strings.TrimSpace(obj.Opinion)
+	obj.Category = strings.TrimSpace(obj.Category)
 }
@@ -16,18 +16,32 @@ package openapi
 type OpinionRequestCoordinate struct {
 
 	// 緯度
-	Latitude float64 `json:"latitude,omitempty"`
+	// 0は有効な値のため、未指定と区別できるようポインタで受け取る
+	Latitude *float64 `json:"latitude"`
 
 	// 経度
-	Longitude float64 `json:"longitude,omitempty"`
+	Longitude *float64 `json:"longitude"`
 }
 
-// AssertOpinionRequestCoordinateRequired checks if the required fields are not zero-ed
+// AssertOpinionRequestCoordinateRequired checks if the required fields are not nil
 func AssertOpinionRequestCoordinateRequired(obj OpinionRequestCoordinate) error {
+	if obj.Latitude == nil {
+		return &RequiredError{Field: "coordinate.latitude"}
+	}
+	if obj.Longitude == nil {
+		return &RequiredError{Field: "coordinate.longitude"}
+	}
 	return nil
 }
 
-// AssertOpinionRequestCoordinateConstraints checks if the values respects the defined constraints
+// AssertOpinionRequestCoordinateConstraints checks if the values respects the constraints defined in openapi.yaml
 func AssertOpinionRequestCoordinateConstraints(obj OpinionRequestCoordinate) error {
-	return nil
+	v := newSchemaValidator("OpinionRequest_coordinate")
+	validateOpinionRequestCoordinate(v, obj)
+	return v.Err()
+}
+
+func validateOpinionRequestCoordinate(v *schemaValidator, obj OpinionRequestCoordinate) {
+	v.Number("latitude", obj.Latitude)
+	v.Number("longitude", obj.Longitude)
 }
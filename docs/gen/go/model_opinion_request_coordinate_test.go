@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAssertOpinionRequestCoordinate(t *testing.T) {
+	float := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name         string
+		coordinate   OpinionRequestCoordinate
+		wantRequired string // RequiredErrorになる項目
+		wantInvalid  string // 制約違反になる項目
+	}{
+		{name: "tokyo", coordinate: OpinionRequestCoordinate{Latitude: float(35.6895), Longitude: float(139.6917)}},
+		{name: "zero is valid", coordinate: OpinionRequestCoordinate{Latitude: float(0), Longitude: float(0)}},
+		{name: "bounds are inclusive", coordinate: OpinionRequestCoordinate{Latitude: float(-90), Longitude: float(180)}},
+		{name: "missing latitude", coordinate: OpinionRequestCoordinate{Longitude: float(139)}, wantRequired: "coordinate.latitude"},
+		{name: "missing longitude", coordinate: OpinionRequestCoordinate{Latitude: float(35)}, wantRequired: "coordinate.longitude"},
+		{name: "latitude above 90", coordinate: OpinionRequestCoordinate{Latitude: float(90.1), Longitude: float(139)}, wantInvalid: "latitude"},
+		{name: "longitude below -180", coordinate: OpinionRequestCoordinate{Latitude: float(35), Longitude: float(-180.1)}, wantInvalid: "longitude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AssertOpinionRequestCoordinateRequired(tt.coordinate)
+			var requiredErr *RequiredError
+			if tt.wantRequired != "" {
+				if !errors.As(err, &requiredErr) || requiredErr.Field != tt.wantRequired {
+					t.Fatalf("required error = %v, want field %s", err, tt.wantRequired)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected required error: %v", err)
+			}
+
+			err = AssertOpinionRequestCoordinateConstraints(tt.coordinate)
+			var validationErr *ValidationError
+			if tt.wantInvalid == "" {
+				if err != nil {
+					t.Errorf("unexpected constraint error: %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 || validationErr.Errors[0].Field != tt.wantInvalid {
+				t.Errorf("constraint error = %v, want field %s", err, tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -14,6 +14,9 @@ package openapi
 
 type PutOpinionReactions201Response struct {
 
+	// リアクションの種類
+	Type string `json:"type,omitempty"`
+
 	// リアクション
 	Reaction bool `json:"reaction,omitempty"`
 }
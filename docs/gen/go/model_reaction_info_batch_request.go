@@ -0,0 +1,39 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type ReactionInfoBatchRequest struct {
+
+	// 投稿ユーザーのメールアドレス(本人情報)
+	MailAddress string `json:"mailAddress"`
+
+	// リアクション情報を取得する投稿のid（重複は除く）
+	OpinionIds []string `json:"opinionIds"`
+}
+
+// AssertReactionInfoBatchRequestRequired checks if the required fields are not zero-ed
+func AssertReactionInfoBatchRequestRequired(obj ReactionInfoBatchRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+		"opinionIds":  obj.OpinionIds,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertReactionInfoBatchRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertReactionInfoBatchRequestConstraints(obj ReactionInfoBatchRequest) error {
+	return ValidateSchemaValue("MailAddress", "mailAddress", obj.MailAddress)
+}
@@ -29,7 +29,7 @@ func AssertReactionInfoRequestRequired(obj ReactionInfoRequest) error {
 	return nil
 }
 
-// AssertReactionRequestConstraints checks if the values respects the defined constraints
+// AssertReactionInfoRequestConstraints checks if the values respects the constraints defined in openapi.yaml
 func AssertReactionInfoRequestConstraints(obj ReactionInfoRequest) error {
-	return nil
+	return ValidateSchemaValue("MailAddress", "mailAddress", obj.MailAddress)
 }
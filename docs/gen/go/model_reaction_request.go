@@ -9,8 +9,9 @@
 
 package openapi
 
-
-
+import (
+	"strings"
+)
 
 type ReactionRequest struct {
 
@@ -18,13 +19,18 @@ type ReactionRequest struct {
 	MailAddress string `json:"mailAddress"`
 
 	// リアクション
-	Reaction bool `json:"reaction,omitempty"`
+	// falseはリアクションの取り消しのため、未指定と区別できるようポインタで受け取る
+	Reaction *bool `json:"reaction"`
+
+	// リアクションの種類（省略時はデフォルトの種類）
+	Type string `json:"type,omitempty"`
 }
 
 // AssertReactionRequestRequired checks if the required fields are not zero-ed
 func AssertReactionRequestRequired(obj ReactionRequest) error {
 	elements := map[string]interface{}{
 		"mailAddress": obj.MailAddress,
+		"reaction":    obj.Reaction,
 	}
 	for name, el := range elements {
 		if isZero := IsZeroValue(el); isZero {
@@ -35,7 +41,16 @@ func AssertReactionRequestRequired(obj ReactionRequest) error {
 	return nil
 }
 
-// AssertReactionRequestConstraints checks if the values respects the defined constraints
+// AssertReactionRequestConstraints checks if the values respects the constraints defined in openapi.yaml
 func AssertReactionRequestConstraints(obj ReactionRequest) error {
-	return nil
+	v := newSchemaValidator("ReactionRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.String("type", obj.Type)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *ReactionRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.Type = strings.TrimSpace(obj.Type)
 }
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// reactionRecorder records the reaction passed to the service
+type reactionRecorder struct {
+	OpinionAPIService
+	reaction *bool
+}
+
+func (s *reactionRecorder) PutOpinionReactions(ctx context.Context, opinionId string, req ReactionRequest) (ImplResponse, error) {
+	s.reaction = req.Reaction
+	return Response(201, nil), nil
+}
+
+func (s *reactionRecorder) PutCommentReactions(ctx context.Context, opinionId string, commentId string, req CommentReactionRequest) (ImplResponse, error) {
+	s.reaction = req.Reaction
+	return Response(201, nil), nil
+}
+
+func TestPutReactionsRequiresReaction(t *testing.T) {
+	const opinionPath = "/user/opinions/00000000-0000-0000-0000-000000000001"
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		wantStatus   int
+		wantReaction string // サービスに渡されたreaction（"nil"は呼ばれていない）
+		wantField    string
+	}{
+		{"opinion reaction true", opinionPath + "/reactions", `{"mailAddress":"a@example.com","reaction":true}`, 201, "true", ""},
+		{"opinion reaction false un-reacts", opinionPath + "/reactions", `{"mailAddress":"a@example.com","reaction":false}`, 201, "false", ""},
+		{"opinion reaction missing", opinionPath + "/reactions", `{"mailAddress":"a@example.com"}`, 422, "nil", "reaction"},
+		{"opinion reaction null", opinionPath + "/reactions", `{"mailAddress":"a@example.com","reaction":null}`, 422, "nil", "reaction"},
+		{"comment reaction false un-reacts", opinionPath + "/comments/00000000-0000-0000-0000-000000000002/reactions", `{"mailAddress":"a@example.com","reaction":false}`, 201, "false", ""},
+		{"comment reaction missing", opinionPath + "/comments/00000000-0000-0000-0000-000000000002/reactions", `{"mailAddress":"a@example.com"}`, 422, "nil", "reaction"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &reactionRecorder{}
+			router := NewRouter(NewOpinionAPIController(service))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			got := "nil"
+			if service.reaction != nil {
+				got = map[bool]string{true: "true", false: "false"}[*service.reaction]
+			}
+			if got != tt.wantReaction {
+				t.Errorf("reaction passed to the service = %s, want %s", got, tt.wantReaction)
+			}
+			if tt.wantField == "" {
+				return
+			}
+			var problem struct {
+				Code   string       `json:"code"`
+				Errors []FieldError `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if len(problem.Errors) != 1 || problem.Errors[0].Field != tt.wantField || problem.Errors[0].Code != "required" {
+				t.Errorf("problem = %s, want a required error for %s", rec.Body.String(), tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+)
+
+type ReportRequest struct {
+
+	// 通報するユーザーのメールアドレス(本人情報)
+	MailAddress string `json:"mailAddress"`
+
+	// 通報理由
+	Reason string `json:"reason"`
+
+	// 補足（任意）
+	Detail string `json:"detail,omitempty"`
+}
+
+// AssertReportRequestRequired checks if the required fields are not zero-ed
+func AssertReportRequestRequired(obj ReportRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+		"reason":      obj.Reason,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertReportRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertReportRequestConstraints(obj ReportRequest) error {
+	v := newSchemaValidator("ReportRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.String("reason", obj.Reason)
+	v.String("detail", obj.Detail)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *ReportRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.Reason = strings.TrimSpace(obj.Reason)
+	obj.Detail = strings.TrimSpace(obj.Detail)
+}
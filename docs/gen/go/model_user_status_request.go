@@ -0,0 +1,61 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"strings"
+	"time"
+)
+
+type UserStatusRequest struct {
+
+	// 対象のユーザーのメールアドレス
+	MailAddress string `json:"mailAddress"`
+
+	// 変更後の利用状態（active: 利用中、suspended: 期限まで利用停止、banned: 無期限に利用停止）
+	Status string `json:"status"`
+
+	// 利用停止の期限（suspendedの場合は必須）
+	Until time.Time `json:"until,omitempty"`
+
+	// 変更の理由などのメモ（履歴に残す）
+	Note string `json:"note,omitempty"`
+}
+
+// AssertUserStatusRequestRequired checks if the required fields are not zero-ed
+func AssertUserStatusRequestRequired(obj UserStatusRequest) error {
+	elements := map[string]interface{}{
+		"mailAddress": obj.MailAddress,
+		"status":      obj.Status,
+	}
+	for name, el := range elements {
+		if isZero := IsZeroValue(el); isZero {
+			return &RequiredError{Field: name}
+		}
+	}
+
+	return nil
+}
+
+// AssertUserStatusRequestConstraints checks if the values respects the constraints defined in openapi.yaml
+func AssertUserStatusRequestConstraints(obj UserStatusRequest) error {
+	v := newSchemaValidator("UserStatusRequest")
+	v.String("mailAddress", obj.MailAddress)
+	v.String("status", obj.Status)
+	v.String("note", obj.Note)
+	return v.Err()
+}
+
+// Normalize trims leading and trailing whitespace of the text fields
+func (obj *UserStatusRequest) Normalize() {
+	obj.MailAddress = strings.TrimSpace(obj.MailAddress)
+	obj.Status = strings.TrimSpace(obj.Status)
+	obj.Note = strings.TrimSpace(obj.Note)
+}
@@ -0,0 +1,154 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimit is a token bucket holding up to Requests tokens, refilled at Requests tokens per Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Enabled reports whether the limit is set (the zero value does not limit)
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// RouteRateLimit is the budget of a route for each authenticated caller (JWT actor) and each source IP
+type RouteRateLimit struct {
+	Identity RateLimit
+	IP       RateLimit
+}
+
+// RateLimitBucket is a token bucket of a route and a caller
+type RateLimitBucket struct {
+	Key   string
+	Limit RateLimit
+}
+
+// RateLimitStore takes tokens from buckets shared by every instance.
+// TakeTokens takes a token from every bucket or from none of them: when any bucket is empty no token is taken
+// and it returns how long to wait until every bucket has a token. It returns 0 when the tokens were taken.
+type RateLimitStore interface {
+	TakeTokens(ctx context.Context, buckets []RateLimitBucket) (time.Duration, error)
+}
+
+// DefaultRateLimits are the budgets of the routes writing user content, keyed by route name
+var DefaultRateLimits = map[string]RouteRateLimit{
+	"PostUserOpinions": {
+		Identity: RateLimit{Requests: 5, Per: 10 * time.Minute},
+		IP:       RateLimit{Requests: 20, Per: 10 * time.Minute},
+	},
+	"PostUserComments": {
+		Identity: RateLimit{Requests: 20, Per: 10 * time.Minute},
+		IP:       RateLimit{Requests: 60, Per: 10 * time.Minute},
+	},
+	"PutOpinionReactions": {
+		Identity: RateLimit{Requests: 60, Per: time.Minute},
+		IP:       RateLimit{Requests: 180, Per: time.Minute},
+	},
+	"PutCommentReactions": {
+		Identity: RateLimit{Requests: 60, Per: time.Minute},
+		IP:       RateLimit{Requests: 180, Per: time.Minute},
+	},
+	"PostOpinionReports": {
+		Identity: RateLimit{Requests: 10, Per: time.Hour},
+		IP:       RateLimit{Requests: 30, Per: time.Hour},
+	},
+	"PostCommentReports": {
+		Identity: RateLimit{Requests: 10, Per: time.Hour},
+		IP:       RateLimit{Requests: 30, Per: time.Hour},
+	},
+	"PostOpinionAttachments": {
+		Identity: RateLimit{Requests: 20, Per: time.Hour},
+		IP:       RateLimit{Requests: 60, Per: time.Hour},
+	},
+}
+
+// RateLimiter wraps the router and rejects requests exceeding the budget of the matched route with 429 and Retry-After.
+// Requests are limited per authenticated caller (the actor of the verified JWT claims) and per source IP (http.Request.RemoteAddr);
+// unauthenticated requests are limited by source IP only. Routes without a budget are not limited.
+// A request is rejected without consuming any bucket when one of its buckets is empty, so a caller rejected by the IP budget
+// does not lose its own budget.
+// The limiter fails open: when the store fails the request is let through unlimited and the failure is logged,
+// so that an outage of the store does not stop the API.
+func RateLimiter(router *mux.Router, store RateLimitStore, limits map[string]RouteRateLimit, errorHandler ErrorHandler) http.Handler {
+	reject := func(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+		WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			errorHandler(w, r, NewRateLimitedError("rate_limited", "Too many requests. Retry after a while.", retryAfter), nil)
+		})).ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var match mux.RouteMatch
+		if !router.Match(r, &match) || match.Route == nil {
+			router.ServeHTTP(w, r)
+			return
+		}
+		name := match.Route.GetName()
+		limit, ok := limits[name]
+		if !ok {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		buckets := make([]RateLimitBucket, 0, 2)
+		if key := rateLimitKey(name, "id", callerIdentity(r)); key != "" && limit.Identity.Enabled() {
+			buckets = append(buckets, RateLimitBucket{Key: key, Limit: limit.Identity})
+		}
+		if key := rateLimitKey(name, "ip", sourceIP(r)); key != "" && limit.IP.Enabled() {
+			buckets = append(buckets, RateLimitBucket{Key: key, Limit: limit.IP})
+		}
+		if len(buckets) > 0 {
+			retryAfter, err := store.TakeTokens(r.Context(), buckets)
+			if err != nil {
+				log.Printf("rate limit store failed for %s, letting the request through without a limit (fail open): %v", name, err)
+			} else if retryAfter > 0 {
+				reject(w, r, retryAfter)
+				return
+			}
+		}
+		router.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey returns the bucket key of a route and a caller. The caller is hashed so that mail addresses are not stored.
+func rateLimitKey(route string, kind string, caller string) string {
+	if caller == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(caller))
+	return route + "#" + kind + "#" + hex.EncodeToString(sum[:])
+}
+
+// callerIdentity returns the actor of the verified JWT claims, or "" when the request is not authenticated.
+// Headers and bodies are not trusted because any caller can set them.
+func callerIdentity(r *http.Request) string {
+	return ClaimsFromContext(r.Context()).Actor()
+}
+
+// sourceIP returns the IP address of the client (RemoteAddr may or may not contain a port)
+func sourceIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// memoryRateLimitStore is an in-memory RateLimitStore counting the remaining requests of each bucket (no refill)
+type memoryRateLimitStore struct {
+	taken map[string]int
+	calls [][]RateLimitBucket
+	err   error
+}
+
+func (s *memoryRateLimitStore) TakeTokens(ctx context.Context, buckets []RateLimitBucket) (time.Duration, error) {
+	s.calls = append(s.calls, buckets)
+	if s.err != nil {
+		return 0, s.err
+	}
+	for _, bucket := range buckets {
+		if s.taken[bucket.Key] >= bucket.Limit.Requests {
+			return bucket.Limit.Per, nil
+		}
+	}
+	for _, bucket := range buckets {
+		s.taken[bucket.Key]++
+	}
+	return 0, nil
+}
+
+func newRateLimitedRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.Methods(http.MethodPost).Path("/limited").Name("Limited").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	router.Methods(http.MethodGet).Path("/free").Name("Free").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return router
+}
+
+func TestRateLimiter(t *testing.T) {
+	limits := map[string]RouteRateLimit{
+		"Limited": {
+			Identity: RateLimit{Requests: 2, Per: time.Minute},
+			IP:       RateLimit{Requests: 3, Per: time.Minute},
+		},
+	}
+	type request struct {
+		method string
+		path   string
+		ip     string
+		actor  string
+		header string // mailAddressヘッダー（制限には使わない）
+		want   int
+	}
+	tests := []struct {
+		name     string
+		requests []request
+	}{
+		{"routes without a budget are not limited", []request{
+			{"GET", "/free", "192.0.2.1", "", "", 200},
+			{"GET", "/free", "192.0.2.1", "", "", 200},
+			{"GET", "/free", "192.0.2.1", "", "", 200},
+			{"GET", "/free", "192.0.2.1", "", "", 200},
+		}},
+		{"per identity", []request{
+			{"POST", "/limited", "192.0.2.1", "a@example.com", "", 201},
+			{"POST", "/limited", "192.0.2.2", "a@example.com", "", 201},
+			{"POST", "/limited", "192.0.2.3", "a@example.com", "", 429},
+			{"POST", "/limited", "192.0.2.3", "b@example.com", "", 201},
+		}},
+		{"unauthenticated requests are limited by ip only", []request{
+			{"POST", "/limited", "192.0.2.1", "", "a@example.com", 201},
+			{"POST", "/limited", "192.0.2.1", "", "b@example.com", 201},
+			{"POST", "/limited", "192.0.2.1", "", "c@example.com", 201},
+			{"POST", "/limited", "192.0.2.1", "", "d@example.com", 429},
+			{"POST", "/limited", "192.0.2.2", "", "d@example.com", 201},
+		}},
+		{"rejection by ip does not consume the identity budget", []request{
+			{"POST", "/limited", "192.0.2.1", "", "", 201},
+			{"POST", "/limited", "192.0.2.1", "", "", 201},
+			{"POST", "/limited", "192.0.2.1", "", "", 201},
+			{"POST", "/limited", "192.0.2.1", "a@example.com", "", 429},
+			{"POST", "/limited", "192.0.2.1", "a@example.com", "", 429},
+			{"POST", "/limited", "192.0.2.9", "a@example.com", "", 201},
+			{"POST", "/limited", "192.0.2.9", "a@example.com", "", 201},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryRateLimitStore{taken: map[string]int{}}
+			handler := RateLimiter(newRateLimitedRouter(), store, limits, DefaultErrorHandler)
+			for i, req := range tt.requests {
+				r := httptest.NewRequest(req.method, req.path, strings.NewReader(`{"mailAddress":"body@example.com"}`))
+				r.RemoteAddr = req.ip + ":12345"
+				if req.header != "" {
+					r.Header.Set("mailAddress", req.header)
+				}
+				if req.actor != "" {
+					r = r.WithContext(WithClaims(r.Context(), map[string]string{"email": req.actor}))
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+				if rec.Code != req.want {
+					t.Fatalf("request %d: status = %d, want %d", i, rec.Code, req.want)
+				}
+				if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "60" {
+					t.Errorf("request %d: Retry-After = %q, want 60", i, rec.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterFailsOpen(t *testing.T) {
+	store := &memoryRateLimitStore{err: errors.New("store unavailable")}
+	limits := map[string]RouteRateLimit{"Limited": {IP: RateLimit{Requests: 1, Per: time.Minute}}}
+	handler := RateLimiter(newRateLimitedRouter(), store, limits, DefaultErrorHandler)
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/limited", nil))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: status = %d, want the request let through", i, rec.Code)
+		}
+	}
+	if len(store.calls) != 3 {
+		t.Errorf("store called %d times, want 3", len(store.calls))
+	}
+}
+
+func TestRateLimiterTakesBucketsTogether(t *testing.T) {
+	store := &memoryRateLimitStore{taken: map[string]int{}}
+	limits := map[string]RouteRateLimit{
+		"Limited": {Identity: RateLimit{Requests: 1, Per: time.Minute}, IP: RateLimit{Requests: 1, Per: time.Minute}},
+	}
+	handler := RateLimiter(newRateLimitedRouter(), store, limits, DefaultErrorHandler)
+	r := httptest.NewRequest(http.MethodPost, "/limited", nil)
+	r = r.WithContext(WithClaims(r.Context(), map[string]string{"sub": "user-1"}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(store.calls) != 1 || len(store.calls[0]) != 2 {
+		t.Fatalf("calls = %v, want both buckets in one call", store.calls)
+	}
+	for _, bucket := range store.calls[0] {
+		if strings.Contains(bucket.Key, "user-1") {
+			t.Errorf("bucket key %q contains the caller as is", bucket.Key)
+		}
+	}
+}
@@ -12,6 +12,7 @@ package openapi
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 	"github.com/gorilla/mux"
 	"io"
@@ -19,6 +20,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -38,30 +41,81 @@ type Router interface {
 	Routes() Routes
 }
 
+// RouteGroup is implemented by routers whose routes share a path prefix and a middleware (e.g. authorization).
+// Their routes are registered on a separate subrouter and every handler is wrapped by the middleware.
+type RouteGroup interface {
+	PathPrefix() string
+	Middleware(http.Handler) http.Handler
+}
+
 const errMsgRequiredMissing = "required parameter is missing"
 const errMsgMinValueConstraint = "provided parameter is not respecting minimum value constraint"
 const errMsgMaxValueConstraint = "provided parameter is not respecting maximum value constraint"
 
-// NewRouter creates a new router for any number of api routers
+// NewRouter creates a new router for any number of api routers.
+// It panics when a route under AdminPathPrefix is not registered through a RouteGroup for that prefix,
+// so that admin routes are never served without the authorization middleware.
 func NewRouter(routers ...Router) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 	for _, api := range routers {
+		target := router
+		group, grouped := api.(RouteGroup)
+		if grouped {
+			target = router.PathPrefix(group.PathPrefix()).Subrouter()
+		}
 		for name, route := range api.Routes() {
+			if isAdminPath(route.Pattern) && (!grouped || group.PathPrefix() != AdminPathPrefix) {
+				panic(fmt.Sprintf("route %s (%s) must be registered through a RouteGroup for %s", name, route.Pattern, AdminPathPrefix))
+			}
 			var handler http.Handler
 			handler = route.HandlerFunc
+			pattern := route.Pattern
+			if grouped {
+				handler = group.Middleware(handler)
+				pattern = strings.TrimPrefix(pattern, group.PathPrefix())
+			}
 			handler = Logger(handler, name)
+			handler = WithRequestID(handler)
 
-			router.
+			target.
 				Methods(route.Method).
-				Path(route.Pattern).
+				Path(pattern).
 				Name(name).
 				Handler(handler)
 		}
 	}
 
+	// 未定義のパス・メソッドもproblem+jsonで返す
+	router.NotFoundHandler = WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		DefaultErrorHandler(w, r, NewNotFoundError("route_not_found", "No route matches the request path."), nil)
+	}))
+	router.MethodNotAllowedHandler = WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := newProblem(http.StatusMethodNotAllowed, "method_not_allowed", "The method is not allowed for the request path.")
+		problem.Instance = r.URL.Path
+		problem.RequestId = RequestIDFromContext(r.Context())
+		EncodeProblemResponse(problem, w)
+	}))
+
 	return router
 }
 
+// isAdminPath reports whether the route pattern is under AdminPathPrefix
+func isAdminPath(pattern string) bool {
+	return pattern == AdminPathPrefix || strings.HasPrefix(pattern, AdminPathPrefix+"/")
+}
+
+// NextCursorHeader is the response header carrying the cursor of the next page
+const NextCursorHeader = "X-Next-Cursor"
+
+// WriteResponseHeaders adds the headers of an ImplResponse to the http response
+func WriteResponseHeaders(w http.ResponseWriter, headers http.Header) {
+	for name, values := range headers {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+}
+
 // EncodeJSONResponse uses the json encoder to write an interface to the http response with an optional status code
 func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error {
 	wHeader := w.Header()
@@ -97,6 +151,45 @@ func EncodeJSONResponse(i interface{}, status *int, w http.ResponseWriter) error
 	return nil
 }
 
+// StreamBody is a response body written incrementally to the http response instead of being encoded at once
+type StreamBody struct {
+	ContentType string
+	// Filename is set to the Content-Disposition header when not empty
+	Filename string
+	WriteTo  func(w io.Writer) error
+}
+
+// flushWriter flushes the underlying http.ResponseWriter after every write when it supports http.Flusher
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+// EncodeStreamResponse writes the stream body to the http response with an optional status code.
+// Errors occurring after the header has been written can no longer change the status code and are only returned.
+func EncodeStreamResponse(body StreamBody, status *int, w http.ResponseWriter) error {
+	wHeader := w.Header()
+	wHeader.Set("Content-Type", body.ContentType)
+	if body.Filename != "" {
+		wHeader.Set("Content-Disposition", "attachment; filename="+body.Filename)
+	}
+
+	if status != nil {
+		w.WriteHeader(*status)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return body.WriteTo(flushWriter{w: w})
+}
+
 // ReadFormFileToTempFile reads file data from a request form and writes it to a temporary file
 func ReadFormFileToTempFile(r *http.Request, key string) (*os.File, error) {
 	_, fileHeader, err := r.FormFile(key)
@@ -339,6 +432,76 @@ func parseNumericArrayParameter[T Number](param, delim string, required bool, fn
 }
 
 
+// areaCodePattern matches a 5-digit local government code (e.g. 13101)
+var areaCodePattern = regexp.MustCompile(`^[0-9]{5}$`)
+
+// parseAreaParameter validates the optional area query parameter
+func parseAreaParameter(param string) (string, error) {
+	if param != "" && !areaCodePattern.MatchString(param) {
+		return "", errors.New("area must be a 5-digit local government code")
+	}
+	return param, nil
+}
+
+// assertUUIDParameter checks that a path parameter declared as format: uuid is a uuid
+func assertUUIDParameter(name string, param string) error {
+	if !uuidPattern.MatchString(param) {
+		return fmt.Errorf("%s must be a uuid", name)
+	}
+	return nil
+}
+
+// parseEnumParameter validates an optional string parameter declared with an enum
+func parseEnumParameter(name string, param string, allowed ...string) (string, error) {
+	if param == "" || slices.Contains(allowed, param) {
+		return param, nil
+	}
+	return "", fmt.Errorf("%s must be one of %s", name, strings.Join(allowed, ", "))
+}
+
+// parseCategoryParameter validates the optional category query parameter against the Category schema
+func parseCategoryParameter(param string) (string, error) {
+	if param == "" {
+		return "", nil
+	}
+	if err := ValidateSchemaValue("Category", "category", param); err != nil {
+		return "", errors.New("category must be one of the managed categories")
+	}
+	return param, nil
+}
+
+// parseTagParameter trims the optional tag query parameter (a leading # may be omitted)
+func parseTagParameter(param string) string {
+	return strings.TrimLeft(strings.TrimSpace(param), "#＃")
+}
+
+// maxBatchOpinionIds is the maxItems of the opinionIds query parameter
+const maxBatchOpinionIds = 100
+
+// parseUUIDArrayParameter parses a comma separated list of uuids, dropping duplicates
+func parseUUIDArrayParameter(name string, param string, maxItems int) ([]string, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var values []string
+	seen := make(map[string]bool)
+	for _, v := range strings.Split(param, ",") {
+		v = strings.TrimSpace(v)
+		if err := assertUUIDParameter(name, v); err != nil {
+			return nil, err
+		}
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	if len(values) > maxItems {
+		return nil, fmt.Errorf("%s must not contain more than %d items", name, maxItems)
+	}
+	return values, nil
+}
+
 // parseQuery parses query paramaters and returns an error if any malformed value pairs are encountered.
 func parseQuery(rawQuery string) (url.Values, error) {
 	return url.ParseQuery(rawQuery)
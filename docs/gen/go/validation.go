@@ -0,0 +1,269 @@
+/*
+ * Tochijihai User API
+ *
+ * 都知事杯のユーザー向けAPIです。
+ *
+ * API version: 0.1.9
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	docs "user-backend/docs"
+)
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	// Field is the JSON path of the field (e.g. coordinate.latitude)
+	Field string `json:"field"`
+	// Code is a stable machine readable reason (required, minLength, maxLength, minimum, maximum, format, pattern, enum, controlCharacter)
+	Code string `json:"code"`
+	// Message is a human readable description
+	Message string `json:"message"`
+	// Span is the offending part of a text field (e.g. a prohibited word)
+	Span *TextSpan `json:"span,omitempty"`
+}
+
+// TextSpan is a range of a text in characters (Unicode code points). End is exclusive.
+type TextSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ValidationError indicates that one or more fields do not respect the constraints of the OpenAPI spec
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return "validation failed: " + strings.Join(messages, ", ")
+}
+
+// specSchema is the subset of an OpenAPI schema object used for request validation
+type specSchema struct {
+	Ref        string                 `yaml:"$ref"`
+	Type       string                 `yaml:"type"`
+	Format     string                 `yaml:"format"`
+	Required   []string               `yaml:"required"`
+	Properties map[string]*specSchema `yaml:"properties"`
+	MinLength  *int                   `yaml:"minLength"`
+	MaxLength  *int                   `yaml:"maxLength"`
+	Minimum    *float64               `yaml:"minimum"`
+	Maximum    *float64               `yaml:"maximum"`
+	Pattern    string                 `yaml:"pattern"`
+	Enum       []string               `yaml:"enum"`
+
+	pattern *regexp.Regexp
+}
+
+type specDocument struct {
+	Components struct {
+		Schemas map[string]*specSchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// specSchemas holds the component schemas of the embedded OpenAPI spec, so that the constraints
+// enforced here always follow docs/openapi.yaml
+var specSchemas = loadSpecSchemas(docs.OpenAPI)
+
+func loadSpecSchemas(spec []byte) map[string]*specSchema {
+	var doc specDocument
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		panic(fmt.Sprintf("failed to parse embedded openapi.yaml: %v", err))
+	}
+	for _, schema := range doc.Components.Schemas {
+		compilePatterns(schema)
+	}
+	return doc.Components.Schemas
+}
+
+// compilePatterns compiles the pattern of the schema and its properties in advance
+func compilePatterns(s *specSchema) {
+	if s == nil {
+		return
+	}
+	if s.Pattern != "" {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	for _, p := range s.Properties {
+		compilePatterns(p)
+	}
+}
+
+// resolveSchema follows a local $ref (#/components/schemas/Name)
+func resolveSchema(s *specSchema) *specSchema {
+	for s != nil && s.Ref != "" {
+		s = specSchemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// schemaValidator collects field errors of a value against a schema of the embedded spec
+type schemaValidator struct {
+	schema *specSchema
+	prefix string
+	errors *[]FieldError
+}
+
+// newSchemaValidator creates a validator for the named component schema
+func newSchemaValidator(name string) *schemaValidator {
+	schema, ok := specSchemas[name]
+	if !ok {
+		log.Printf("schema %s is not defined in openapi.yaml", name)
+	}
+	return &schemaValidator{schema: schema, errors: &[]FieldError{}}
+}
+
+func (v *schemaValidator) property(field string) *specSchema {
+	if v.schema == nil {
+		return nil
+	}
+	return resolveSchema(v.schema.Properties[field])
+}
+
+func (v *schemaValidator) isRequired(field string) bool {
+	if v.schema == nil {
+		return false
+	}
+	for _, r := range v.schema.Required {
+		if r == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *schemaValidator) addError(field, code, message string) {
+	*v.errors = append(*v.errors, FieldError{Field: v.prefix + field, Code: code, Message: message})
+}
+
+// Required reports the field when it is required by the spec and missing
+func (v *schemaValidator) Required(field string, missing bool) {
+	if missing && v.isRequired(field) {
+		v.addError(field, "required", "is required")
+	}
+}
+
+// String validates a string property. The length is counted in Unicode characters.
+func (v *schemaValidator) String(field string, value string) {
+	v.Required(field, value == "")
+	if value == "" {
+		return
+	}
+	validateString(v, field, v.property(field), value)
+}
+
+// Number validates a number property. A nil value is treated as missing.
+func (v *schemaValidator) Number(field string, value *float64) {
+	v.Required(field, value == nil)
+	schema := v.property(field)
+	if value == nil || schema == nil {
+		return
+	}
+	if schema.Minimum != nil && *value < *schema.Minimum {
+		v.addError(field, "minimum", fmt.Sprintf("must be greater than or equal to %v", *schema.Minimum))
+	}
+	if schema.Maximum != nil && *value > *schema.Maximum {
+		v.addError(field, "maximum", fmt.Sprintf("must be less than or equal to %v", *schema.Maximum))
+	}
+}
+
+// Object validates a nested object property with the schema it references
+func (v *schemaValidator) Object(field string, validate func(*schemaValidator)) {
+	validate(&schemaValidator{
+		schema: v.property(field),
+		prefix: v.prefix + field + ".",
+		errors: v.errors,
+	})
+}
+
+// Err returns a ValidationError when any field is invalid
+func (v *schemaValidator) Err() error {
+	if len(*v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: *v.errors}
+}
+
+// validateString checks control characters, length, format, pattern and enum of a string value
+func validateString(v *schemaValidator, field string, schema *specSchema, value string) {
+	if schema == nil {
+		return
+	}
+	// 改行・タブは本文として許可し、それ以外の制御文字は拒否する
+	for _, r := range value {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			v.addError(field, "controlCharacter", "must not contain control characters")
+			break
+		}
+	}
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.addError(field, "minLength", fmt.Sprintf("must be at least %d characters", *schema.MinLength))
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.addError(field, "maxLength", fmt.Sprintf("must be at most %d characters", *schema.MaxLength))
+	}
+	if schema.Format == "email" && !isEmail(value) {
+		v.addError(field, "format", "must be a valid email address")
+	}
+	if schema.Format == "uuid" && !uuidPattern.MatchString(value) {
+		v.addError(field, "format", "must be a valid uuid")
+	}
+	if schema.pattern != nil {
+		if !schema.pattern.MatchString(value) {
+			v.addError(field, "pattern", "must match "+schema.Pattern)
+		}
+	}
+	if len(schema.Enum) > 0 {
+		valid := false
+		for _, e := range schema.Enum {
+			if e == value {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			v.addError(field, "enum", "must be one of "+strings.Join(schema.Enum, ", "))
+		}
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isEmail accepts a bare address (addr-spec) without display name
+func isEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || addr.Name != "" {
+		return false
+	}
+	// ドメイン部にはドットを1つ以上含むことを要求する
+	at := strings.LastIndex(value, "@")
+	return at > 0 && strings.Contains(value[at+1:], ".")
+}
+
+// ValidateSchemaValue validates a single value (e.g. a header or path parameter) against a component schema
+func ValidateSchemaValue(name string, field string, value string) error {
+	v := &schemaValidator{errors: &[]FieldError{}}
+	if value == "" {
+		v.addError(field, "required", "is required")
+		return v.Err()
+	}
+	validateString(v, field, resolveSchema(specSchemas[name]), value)
+	return v.Err()
+}
@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAssertOpinionRequestConstraints(t *testing.T) {
+	float := func(f float64) *float64 { return &f }
+	valid := func() OpinionRequest {
+		return OpinionRequest{
+			MailAddress: "tochiji.hai@example.com",
+			Coordinate:  OpinionRequestCoordinate{Latitude: float(35.6895), Longitude: float(139.6917)},
+			Opinion:     "すごくきれいな場所です！",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(*OpinionRequest)
+		wantFields []string // "field:code"
+	}{
+		{name: "valid", modify: func(*OpinionRequest) {}},
+		{
+			name:   "500 characters counted in runes",
+			modify: func(r *OpinionRequest) { r.Opinion = strings.Repeat("桜", 500) },
+		},
+		{
+			name:       "501 characters",
+			modify:     func(r *OpinionRequest) { r.Opinion = strings.Repeat("桜", 501) },
+			wantFields: []string{"opinion:maxLength"},
+		},
+		{
+			name:   "newlines and tabs are allowed",
+			modify: func(r *OpinionRequest) { r.Opinion = "1行目\r\n2行目\tタブ" },
+		},
+		{
+			name:       "control characters",
+			modify:     func(r *OpinionRequest) { r.Opinion = "ベル\a文字" },
+			wantFields: []string{"opinion:controlCharacter"},
+		},
+		{
+			name:       "invalid email",
+			modify:     func(r *OpinionRequest) { r.MailAddress = "tochiji.hai" },
+			wantFields: []string{"mailAddress:format"},
+		},
+		{
+			name:       "email with display name",
+			modify:     func(r *OpinionRequest) { r.MailAddress = "都知事 <tochiji.hai@example.com>" },
+			wantFields: []string{"mailAddress:format"},
+		},
+		{
+			name:       "email without dot in the domain",
+			modify:     func(r *OpinionRequest) { r.MailAddress = "tochiji.hai@localhost" },
+			wantFields: []string{"mailAddress:format"},
+		},
+		{
+			name: "every invalid field is reported with its path",
+			modify: func(r *OpinionRequest) {
+				r.MailAddress = "invalid"
+				r.Coordinate.Latitude = float(91)
+				r.Opinion = strings.Repeat("a", 501)
+			},
+			wantFields: []string{"mailAddress:format", "coordinate.latitude:maximum", "opinion:maxLength"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(&req)
+			err := AssertOpinionRequestConstraints(req)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("error = %v, want ValidationError", err)
+			}
+			got := make([]string, 0, len(validationErr.Errors))
+			for _, fe := range validationErr.Errors {
+				got = append(got, fe.Field+":"+fe.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("field errors = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestOpinionRequestNormalize(t *testing.T) {
+	req := OpinionRequest{MailAddress: "  tochiji.hai@example.com\n", Opinion: "\t本文 ", Category: " road "}
+	req.Normalize()
+	if req.MailAddress != "tochiji.hai@example.com" || req.Opinion != "本文" || req.Category != "road" {
+		t.Errorf("Normalize() = %+v", req)
+	}
+}
+
+func TestValidateSchemaValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantCode string
+	}{
+		{"valid", "tochiji.hai@example.com", ""},
+		{"empty", "", "required"},
+		{"invalid format", "not-an-email", "format"},
+		{"too long", strings.Repeat("a", 250) + "@example.com", "maxLength"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchemaValue("MailAddress", "mailAddress", tt.value)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Errors[0].Code != tt.wantCode {
+				t.Errorf("error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSpecRequiredFieldsExist(t *testing.T) {
+	// requiredに存在しない項目（typoなど）が含まれていないことを確認する
+	for name, schema := range specSchemas {
+		for _, field := range schema.Required {
+			if _, ok := schema.Properties[field]; !ok {
+				t.Errorf("schema %s requires %s, which is not a property", name, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package docs はAPI仕様書(openapi.yaml)をバイナリに埋め込み、実行時のバリデーションなどから参照できるようにする。
+package docs
+
+import _ "embed"
+
+// OpenAPI - API仕様書(openapi.yaml)の内容
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+// utf8BOM - ExcelでUTF-8として認識させるためのBOM
+const utf8BOM = "\xef\xbb\xbf"
+
+var csvHeaders = []string{"opinionId", "latitude", "longitude", "opinion", "createdDateTime", "areaCode", "areaName", "category", "tags", "reactionCount", "commentCount"}
+
+var csvHeadersJa = []string{"意見ID", "緯度", "経度", "意見", "投稿日時", "区市町村コード", "区市町村名", "カテゴリー", "ハッシュタグ", "リアクション数", "コメント数"}
+
+// CSVEncoder - 意見をCSV(UTF-8 BOM付き)として逐次書き出すエンコーダー
+type CSVEncoder struct {
+	w              *csv.Writer
+	raw            io.Writer
+	japaneseHeader bool
+	headerWritten  bool
+}
+
+// NewCSVEncoder - japaneseHeaderがtrueの場合はヘッダー行を日本語で出力する
+func NewCSVEncoder(w io.Writer, japaneseHeader bool) *CSVEncoder {
+	return &CSVEncoder{
+		w:              csv.NewWriter(w),
+		raw:            w,
+		japaneseHeader: japaneseHeader,
+	}
+}
+
+func (e *CSVEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+
+	if _, err := io.WriteString(e.raw, utf8BOM); err != nil {
+		return err
+	}
+	headers := csvHeaders
+	if e.japaneseHeader {
+		headers = csvHeadersJa
+	}
+	return e.w.Write(headers)
+}
+
+// WriteOpinion - 意見1件を1行として書き出す
+func (e *CSVEncoder) WriteOpinion(opinion infra.OpinionItem, counts infra.OpinionCounts) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	var createdDateTime string
+	if !opinion.CreatedDateTime.IsZero() {
+		createdDateTime = opinion.CreatedDateTime.Format(time.RFC3339)
+	}
+
+	err := e.w.Write([]string{
+		opinion.ID,
+		strconv.FormatFloat(opinion.Coordinate.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(opinion.Coordinate.Longitude, 'f', -1, 64),
+		escapeFormula(opinion.Opinion),
+		createdDateTime,
+		opinion.AreaCode,
+		opinion.AreaName,
+		opinion.Category,
+		joinTags(opinion.Tags),
+		strconv.Itoa(int(counts.ReactionCount)),
+		strconv.Itoa(int(counts.CommentCount)),
+	})
+	if err != nil {
+		return err
+	}
+	// 行ごとに書き込み先へ流す
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// escapeFormula - 表計算ソフトで数式として実行される文字で始まる値の先頭に'を付ける（CSVインジェクション対策）
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
+// Close - 意見が0件の場合もヘッダー行を出力する
+func (e *CSVEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	opinion := infra.OpinionItem{
+		ID:              "00000000-0000-0000-0000-000000000001",
+		MailAddress:     "tochiji.hai@example.com",
+		Coordinate:      infra.Coordinate{Latitude: 35.6938, Longitude: 139.7036},
+		Opinion:         "改行と\"引用符\",カンマを\n含む意見",
+		CreatedDateTime: time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC),
+		AreaCode:        "13104",
+		AreaName:        "新宿区",
+		Category:        "road",
+		Tags:            []string{"歩道", "自転車"},
+	}
+
+	tests := []struct {
+		name           string
+		japaneseHeader bool
+		opinions       []infra.OpinionItem
+		want           [][]string
+	}{
+		{
+			name: "header only",
+			want: [][]string{csvHeaders},
+		},
+		{
+			name:           "japanese header",
+			japaneseHeader: true,
+			want:           [][]string{csvHeadersJa},
+		},
+		{
+			name:     "quoted values and joined tags",
+			opinions: []infra.OpinionItem{opinion},
+			want: [][]string{
+				csvHeaders,
+				{
+					"00000000-0000-0000-0000-000000000001", "35.6938", "139.7036", "改行と\"引用符\",カンマを\n含む意見",
+					"2024-04-01T09:30:00Z", "13104", "新宿区", "road", "#歩道 #自転車", "2", "1",
+				},
+			},
+		},
+		{
+			name: "formula-like opinions are escaped",
+			opinions: []infra.OpinionItem{
+				{ID: "3", Opinion: "=HYPERLINK(\"http://example.com\")", Tags: []string{}},
+				{ID: "4", Opinion: "+1 賛成", Tags: []string{}},
+				{ID: "5", Opinion: "-2+3", Tags: []string{}},
+				{ID: "6", Opinion: "@SUM(A1)", Tags: []string{}},
+				{ID: "7", Opinion: "\t=1", Tags: []string{}},
+				{ID: "8", Opinion: "\r=1", Tags: []string{}},
+				{ID: "9", Opinion: "公園 = 憩いの場", Tags: []string{}},
+			},
+			want: [][]string{
+				csvHeaders,
+				{"3", "0", "0", "'=HYPERLINK(\"http://example.com\")", "", "", "", "", "", "2", "1"},
+				{"4", "0", "0", "'+1 賛成", "", "", "", "", "", "2", "1"},
+				{"5", "0", "0", "'-2+3", "", "", "", "", "", "2", "1"},
+				{"6", "0", "0", "'@SUM(A1)", "", "", "", "", "", "2", "1"},
+				{"7", "0", "0", "'\t=1", "", "", "", "", "", "2", "1"},
+				{"8", "0", "0", "'\r=1", "", "", "", "", "", "2", "1"},
+				{"9", "0", "0", "公園 = 憩いの場", "", "", "", "", "", "2", "1"},
+			},
+		},
+		{
+			name:     "missing createdDateTime is empty",
+			opinions: []infra.OpinionItem{{ID: "2", Tags: []string{}}},
+			want:     [][]string{csvHeaders, {"2", "0", "0", "", "", "", "", "", "", "2", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewCSVEncoder(&buf, tt.japaneseHeader)
+			for _, o := range tt.opinions {
+				if err := enc.WriteOpinion(o, infra.OpinionCounts{ReactionCount: 2, CommentCount: 1}); err != nil {
+					t.Fatalf("WriteOpinion() error = %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			out := buf.String()
+			if !strings.HasPrefix(out, utf8BOM) {
+				t.Fatalf("output does not start with the UTF-8 BOM: %q", out)
+			}
+			if strings.Contains(out, "tochiji.hai@example.com") {
+				t.Errorf("output contains the mail address: %q", out)
+			}
+			records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(out, utf8BOM))).ReadAll()
+			if err != nil {
+				t.Fatalf("output is not valid CSV: %v", err)
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("got %d records, want %d: %q", len(records), len(tt.want), records)
+			}
+			for i := range records {
+				if strings.Join(records[i], "\x00") != strings.Join(tt.want[i], "\x00") {
+					t.Errorf("record %d = %q, want %q", i, records[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	infra "user-backend/infra"
+)
+
+// Encoder - 意見を1件ずつ書き出すエクスポート形式のエンコーダー
+// 全件をメモリに保持せず、書き込み先へ逐次出力する
+type Encoder interface {
+	// WriteOpinion - 意見1件を書き出す
+	WriteOpinion(opinion infra.OpinionItem, counts infra.OpinionCounts) error
+	// Close - 末尾（閉じタグなど）を書き出す。意見が0件の場合も有効な出力になる
+	Close() error
+}
+
+// Format - エクスポート形式
+type Format string
+
+const (
+	FormatGeoJSON Format = "geojson"
+	FormatCSV     Format = "csv"
+	FormatKML     Format = "kml"
+)
+
+// ContentType - 形式ごとのContent-Type
+func (f Format) ContentType() string {
+	switch f {
+	case FormatGeoJSON:
+		return "application/geo+json; charset=UTF-8"
+	case FormatCSV:
+		return "text/csv; charset=UTF-8"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml; charset=UTF-8"
+	}
+	return "application/octet-stream"
+}
+
+// ParseFormat - 文字列からエクスポート形式を取得する
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatGeoJSON, FormatCSV, FormatKML:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported export format: %s", s)
+}
+
+// NewEncoder - 形式に応じたエンコーダーを作成する
+// japaneseHeaderはCSVのヘッダー行を日本語にする場合にtrueを指定する（CSV以外では無視）
+func NewEncoder(format Format, w io.Writer, japaneseHeader bool) Encoder {
+	switch format {
+	case FormatCSV:
+		return NewCSVEncoder(w, japaneseHeader)
+	case FormatKML:
+		return NewKMLEncoder(w)
+	}
+	return NewGeoJSONEncoder(w)
+}
+
+// joinTags - ハッシュタグを「#タグ」の空白区切りで1つの値にまとめる（CSV・KML用）
+func joinTags(tags []string) string {
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		values[i] = "#" + tag
+	}
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+// Feature - 意見1件を表すGeoJSON(RFC 7946)のFeature
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Geometry          `json:"geometry"`
+	Properties OpinionProperties `json:"properties"`
+}
+
+// Geometry - GeoJSONのPointジオメトリ
+type Geometry struct {
+	Type string `json:"type"`
+	// GeoJSONの座標順は[経度, 緯度]
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// OpinionProperties - Featureに含める意見の属性（個人情報は含めない）
+type OpinionProperties struct {
+	OpinionId       string     `json:"opinionId"`
+	Opinion         string     `json:"opinion"`
+	CreatedDateTime *time.Time `json:"createdDateTime"`
+	AreaCode        string     `json:"areaCode,omitempty"`
+	AreaName        string     `json:"areaName,omitempty"`
+	Category        string     `json:"category,omitempty"`
+	Tags            []string   `json:"tags"`
+	ReactionCount   int32      `json:"reactionCount"`
+	CommentCount    int32      `json:"commentCount"`
+}
+
+// NewFeature - 意見とその集計値からFeatureを作成する
+func NewFeature(opinion infra.OpinionItem, counts infra.OpinionCounts) Feature {
+	var createdDateTime *time.Time
+	if !opinion.CreatedDateTime.IsZero() {
+		createdDateTime = &opinion.CreatedDateTime
+	}
+
+	return Feature{
+		Type: "Feature",
+		Geometry: Geometry{
+			Type:        "Point",
+			Coordinates: [2]float64{opinion.Coordinate.Longitude, opinion.Coordinate.Latitude},
+		},
+		Properties: OpinionProperties{
+			OpinionId:       opinion.ID,
+			Opinion:         opinion.Opinion,
+			CreatedDateTime: createdDateTime,
+			AreaCode:        opinion.AreaCode,
+			AreaName:        opinion.AreaName,
+			Category:        opinion.Category,
+			Tags:            opinion.Tags,
+			ReactionCount:   counts.ReactionCount,
+			CommentCount:    counts.CommentCount,
+		},
+	}
+}
+
+// GeoJSONEncoder - 意見をFeatureCollectionとして逐次書き出すエンコーダー
+type GeoJSONEncoder struct {
+	w       io.Writer
+	written int
+}
+
+func NewGeoJSONEncoder(w io.Writer) *GeoJSONEncoder {
+	return &GeoJSONEncoder{w: w}
+}
+
+// WriteOpinion - Featureを1件書き出す
+func (e *GeoJSONEncoder) WriteOpinion(opinion infra.OpinionItem, counts infra.OpinionCounts) error {
+	// 1件目の前にFeatureCollectionの開始部分、2件目以降は区切りのカンマを出力
+	separator := ","
+	if e.written == 0 {
+		separator = `{"type":"FeatureCollection","features":[`
+	}
+	if _, err := io.WriteString(e.w, separator); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(NewFeature(opinion, counts))
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(b); err != nil {
+		return err
+	}
+	e.written++
+	return nil
+}
+
+// Close - FeatureCollectionを閉じる
+func (e *GeoJSONEncoder) Close() error {
+	if e.written == 0 {
+		_, err := io.WriteString(e.w, `{"type":"FeatureCollection","features":[]}`+"\n")
+		return err
+	}
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
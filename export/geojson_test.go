@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+func TestGeoJSONEncoder(t *testing.T) {
+	created := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	shinjuku := infra.OpinionItem{
+		ID:              "00000000-0000-0000-0000-000000000001",
+		MailAddress:     "tochiji.hai@example.com",
+		Coordinate:      infra.Coordinate{Latitude: 35.6938, Longitude: 139.7036},
+		Opinion:         "歩道が狭い",
+		CreatedDateTime: created,
+		AreaCode:        "13104",
+		AreaName:        "新宿区",
+		Category:        "road",
+		Tags:            []string{"歩道"},
+	}
+	legacy := infra.OpinionItem{
+		ID:         "00000000-0000-0000-0000-000000000002",
+		Coordinate: infra.Coordinate{Latitude: 35.0, Longitude: 139.0},
+		Opinion:    "createdDateTimeのない古い意見",
+		Tags:       []string{},
+	}
+
+	tests := []struct {
+		name     string
+		opinions []infra.OpinionItem
+		counts   []infra.OpinionCounts
+		want     string
+	}{
+		{
+			name: "no opinions",
+			want: `{"type":"FeatureCollection","features":[]}`,
+		},
+		{
+			name:     "one opinion",
+			opinions: []infra.OpinionItem{shinjuku},
+			counts:   []infra.OpinionCounts{{ReactionCount: 3, CommentCount: 1}},
+			want: `{"type":"FeatureCollection","features":[` +
+				`{"type":"Feature","geometry":{"type":"Point","coordinates":[139.7036,35.6938]},` +
+				`"properties":{"opinionId":"00000000-0000-0000-0000-000000000001","opinion":"歩道が狭い",` +
+				`"createdDateTime":"2024-04-01T09:30:00Z","areaCode":"13104","areaName":"新宿区","category":"road",` +
+				`"tags":["歩道"],"reactionCount":3,"commentCount":1}}]}`,
+		},
+		{
+			name:     "opinions are separated and optional properties are omitted",
+			opinions: []infra.OpinionItem{shinjuku, legacy},
+			counts:   []infra.OpinionCounts{{}, {}},
+			want: `{"type":"FeatureCollection","features":[` +
+				`{"type":"Feature","geometry":{"type":"Point","coordinates":[139.7036,35.6938]},` +
+				`"properties":{"opinionId":"00000000-0000-0000-0000-000000000001","opinion":"歩道が狭い",` +
+				`"createdDateTime":"2024-04-01T09:30:00Z","areaCode":"13104","areaName":"新宿区","category":"road",` +
+				`"tags":["歩道"],"reactionCount":0,"commentCount":0}},` +
+				`{"type":"Feature","geometry":{"type":"Point","coordinates":[139,35]},` +
+				`"properties":{"opinionId":"00000000-0000-0000-0000-000000000002","opinion":"createdDateTimeのない古い意見",` +
+				`"createdDateTime":null,"tags":[],"reactionCount":0,"commentCount":0}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewGeoJSONEncoder(&buf)
+			for i, opinion := range tt.opinions {
+				if err := enc.WriteOpinion(opinion, tt.counts[i]); err != nil {
+					t.Fatalf("WriteOpinion() error = %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want+"\n" {
+				t.Errorf("output = %s\nwant %s", got, tt.want)
+			}
+			if !json.Valid(buf.Bytes()) {
+				t.Errorf("output is not valid JSON: %s", buf.String())
+			}
+		})
+	}
+}
+
+func TestNewFeatureOmitsMailAddress(t *testing.T) {
+	feature := NewFeature(infra.OpinionItem{ID: "1", MailAddress: "tochiji.hai@example.com"}, infra.OpinionCounts{})
+	b, err := json.Marshal(feature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("tochiji.hai@example.com")) {
+		t.Errorf("feature contains the mail address: %s", b)
+	}
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+const kmlHeader = xml.Header + `<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>都知事杯 意見一覧</name>` + "\n"
+
+const kmlFooter = "</Document></kml>\n"
+
+// kmlPlacemarkNameLength - Placemarkの名前として使う意見本文の最大文字数
+const kmlPlacemarkNameLength = 30
+
+type kmlPlacemark struct {
+	XMLName      xml.Name        `xml:"Placemark"`
+	ID           string          `xml:"id,attr"`
+	Name         string          `xml:"name"`
+	Description  string          `xml:"description"`
+	ExtendedData kmlExtendedData `xml:"ExtendedData"`
+	Point        kmlPoint        `xml:"Point"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	// KMLの座標順は「経度,緯度」
+	Coordinates string `xml:"coordinates"`
+}
+
+// KMLEncoder - 意見をKMLのPlacemarkとして逐次書き出すエンコーダー
+type KMLEncoder struct {
+	w             io.Writer
+	enc           *xml.Encoder
+	headerWritten bool
+}
+
+func NewKMLEncoder(w io.Writer) *KMLEncoder {
+	return &KMLEncoder{w: w, enc: xml.NewEncoder(w)}
+}
+
+func (e *KMLEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	_, err := io.WriteString(e.w, kmlHeader)
+	return err
+}
+
+// WriteOpinion - 意見1件をPlacemarkとして書き出す
+func (e *KMLEncoder) WriteOpinion(opinion infra.OpinionItem, counts infra.OpinionCounts) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	var createdDateTime string
+	if !opinion.CreatedDateTime.IsZero() {
+		createdDateTime = opinion.CreatedDateTime.Format(time.RFC3339)
+	}
+
+	placemark := kmlPlacemark{
+		ID:          opinion.ID,
+		Name:        truncate(opinion.Opinion, kmlPlacemarkNameLength),
+		Description: fmt.Sprintf("%s\n\nリアクション数: %d / コメント数: %d", opinion.Opinion, counts.ReactionCount, counts.CommentCount),
+		ExtendedData: kmlExtendedData{Data: []kmlData{
+			{Name: "opinionId", Value: opinion.ID},
+			{Name: "createdDateTime", Value: createdDateTime},
+			{Name: "areaCode", Value: opinion.AreaCode},
+			{Name: "areaName", Value: opinion.AreaName},
+			{Name: "category", Value: opinion.Category},
+			{Name: "tags", Value: joinTags(opinion.Tags)},
+			{Name: "reactionCount", Value: strconv.Itoa(int(counts.ReactionCount))},
+			{Name: "commentCount", Value: strconv.Itoa(int(counts.CommentCount))},
+		}},
+		Point: kmlPoint{
+			Coordinates: strconv.FormatFloat(opinion.Coordinate.Longitude, 'f', -1, 64) + "," +
+				strconv.FormatFloat(opinion.Coordinate.Latitude, 'f', -1, 64),
+		},
+	}
+	if err := e.enc.Encode(placemark); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+// Close - Documentを閉じる
+func (e *KMLEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, kmlFooter)
+	return err
+}
+
+// truncate - 文字数（rune数）で文字列を切り詰める
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	infra "user-backend/infra"
+)
+
+func TestKMLEncoder(t *testing.T) {
+	opinion := infra.OpinionItem{
+		ID:              "00000000-0000-0000-0000-000000000001",
+		MailAddress:     "tochiji.hai@example.com",
+		Coordinate:      infra.Coordinate{Latitude: 35.6938, Longitude: 139.7036},
+		Opinion:         "<script>&エスケープが必要な意見",
+		CreatedDateTime: time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC),
+		AreaCode:        "13104",
+		AreaName:        "新宿区",
+		Tags:            []string{"歩道"},
+	}
+
+	tests := []struct {
+		name           string
+		opinions       []infra.OpinionItem
+		wantPlacemarks int
+		wantContains   []string
+	}{
+		{
+			name:         "no opinions is an empty document",
+			wantContains: []string{"<Document>", kmlFooter},
+		},
+		{
+			name:           "placemark with escaped text and longitude first",
+			opinions:       []infra.OpinionItem{opinion},
+			wantPlacemarks: 1,
+			wantContains: []string{
+				`<Placemark id="00000000-0000-0000-0000-000000000001">`,
+				"<name>&lt;script&gt;&amp;エスケープが必要な意見</name>",
+				"<coordinates>139.7036,35.6938</coordinates>",
+				`<Data name="areaName"><value>新宿区</value></Data>`,
+				`<Data name="tags"><value>#歩道</value></Data>`,
+				`<Data name="reactionCount"><value>4</value></Data>`,
+			},
+		},
+		{
+			name:           "one placemark per opinion",
+			opinions:       []infra.OpinionItem{opinion, opinion, opinion},
+			wantPlacemarks: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewKMLEncoder(&buf)
+			for _, o := range tt.opinions {
+				if err := enc.WriteOpinion(o, infra.OpinionCounts{ReactionCount: 4}); err != nil {
+					t.Fatalf("WriteOpinion() error = %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			out := buf.String()
+			var doc struct {
+				Placemarks []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"Document>Placemark"`
+			}
+			if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("output is not valid XML: %v\n%s", err, out)
+			}
+			if len(doc.Placemarks) != tt.wantPlacemarks {
+				t.Errorf("got %d placemarks, want %d", len(doc.Placemarks), tt.wantPlacemarks)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "tochiji.hai@example.com") {
+				t.Errorf("output contains the mail address")
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"", 3, ""},
+		{"abc", 3, "abc"},
+		{"abcd", 3, "abc…"},
+		{"都知事杯の意見", 3, "都知事…"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{"geojson", FormatGeoJSON, false},
+		{"csv", FormatCSV, false},
+		{"kml", FormatKML, false},
+		{"xlsx", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
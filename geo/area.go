@@ -0,0 +1,159 @@
+package geo
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// tokyoAreasGeoJSON - 同梱の東京都の区市町村境界データ
+// 国土数値情報（行政区域データ N03）から `make area-data` で生成する
+//
+//go:embed data/tokyo_areas.geojson
+var tokyoAreasGeoJSON []byte
+
+// ErrNoAreaData - 境界データに区市町村が1つも含まれていない
+// 空のデータで起動すると意見に区市町村が付与されず、areaでの絞り込みも常に0件になるため、読み込みの時点でエラーにする
+var ErrNoAreaData = errors.New("area data contains no areas")
+
+// Area - 区市町村（全国地方公共団体コード5桁と名称）とその境界
+type Area struct {
+	Code     string
+	Name     string
+	Geometry MultiPolygon
+	bbox     bbox
+}
+
+type bbox struct {
+	minLng, minLat, maxLng, maxLat float64
+}
+
+func (b bbox) contains(pt Point) bool {
+	return pt.Longitude >= b.minLng && pt.Longitude <= b.maxLng &&
+		pt.Latitude >= b.minLat && pt.Latitude <= b.maxLat
+}
+
+// AreaIndex - 座標から区市町村を求めるための索引（外部のジオコーディングサービスは使わない）
+type AreaIndex struct {
+	areas []Area
+}
+
+// TokyoAreas - 同梱の東京都の区市町村境界データから索引を作成する
+func TokyoAreas() (*AreaIndex, error) {
+	features, err := ParseGeoJSON(tokyoAreasGeoJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundled area data: %w", err)
+	}
+	idx, err := NewAreaIndex(features)
+	if errors.Is(err, ErrNoAreaData) {
+		return nil, fmt.Errorf("bundled area data is empty, run `make area-data` to generate it: %w", err)
+	}
+	return idx, err
+}
+
+// LoadAreaIndex - pathが指定されていればそのGeoJSONファイル、空の場合は同梱データから索引を作成する
+func LoadAreaIndex(path string) (*AreaIndex, error) {
+	if path == "" {
+		return TokyoAreas()
+	}
+	return LoadAreaIndexFile(path)
+}
+
+// LoadAreaIndexFile - GeoJSONファイルから索引を作成する
+func LoadAreaIndexFile(path string) (*AreaIndex, error) {
+	features, err := LoadGeoJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := NewAreaIndex(features)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// NewAreaIndex - Featureの属性code・name（またはN03形式のN03_007・N03_004）から索引を作成する
+// 同じコードのFeatureは1つの区域にまとめる。区市町村が1つもない場合はErrNoAreaDataを返す
+func NewAreaIndex(features []Feature) (*AreaIndex, error) {
+	byCode := make(map[string]*Area)
+	var codes []string
+	for _, f := range features {
+		code := stringProperty(f.Properties, "code", "N03_007")
+		if code == "" {
+			// 所属未定地などコードのない区域は対象外
+			continue
+		}
+		area, ok := byCode[code]
+		if !ok {
+			area = &Area{Code: code, Name: stringProperty(f.Properties, "name", "N03_004")}
+			byCode[code] = area
+			codes = append(codes, code)
+		}
+		area.Geometry = append(area.Geometry, f.Geometry...)
+	}
+
+	if len(codes) == 0 {
+		return nil, ErrNoAreaData
+	}
+
+	sort.Strings(codes)
+	idx := &AreaIndex{areas: make([]Area, 0, len(codes))}
+	for _, code := range codes {
+		area := byCode[code]
+		area.bbox = boundingBox(area.Geometry)
+		idx.areas = append(idx.areas, *area)
+	}
+	return idx, nil
+}
+
+// Resolve - 座標が含まれる区市町村を返す
+func (idx *AreaIndex) Resolve(pt Point) (Area, bool) {
+	for _, area := range idx.areas {
+		if area.bbox.contains(pt) && area.Geometry.Contains(pt) {
+			return area, true
+		}
+	}
+	return Area{}, false
+}
+
+// Areas - 索引に含まれる区市町村をコード順に返す
+func (idx *AreaIndex) Areas() []Area {
+	return idx.areas
+}
+
+// Lookup - コードから区市町村を返す
+func (idx *AreaIndex) Lookup(code string) (Area, bool) {
+	i := sort.Search(len(idx.areas), func(i int) bool { return idx.areas[i].Code >= code })
+	if i < len(idx.areas) && idx.areas[i].Code == code {
+		return idx.areas[i], true
+	}
+	return Area{}, false
+}
+
+func boundingBox(m MultiPolygon) bbox {
+	b := bbox{minLng: math.Inf(1), minLat: math.Inf(1), maxLng: math.Inf(-1), maxLat: math.Inf(-1)}
+	for _, polygon := range m {
+		if len(polygon) == 0 {
+			continue
+		}
+		// 外周だけを見れば十分
+		for _, pt := range polygon[0] {
+			b.minLng = math.Min(b.minLng, pt.Longitude)
+			b.minLat = math.Min(b.minLat, pt.Latitude)
+			b.maxLng = math.Max(b.maxLng, pt.Longitude)
+			b.maxLat = math.Max(b.maxLat, pt.Latitude)
+		}
+	}
+	return b
+}
+
+func stringProperty(properties map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := properties[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
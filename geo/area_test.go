@@ -0,0 +1,157 @@
+package geo
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func areaFeature(properties map[string]interface{}, rings ...[]Point) Feature {
+	return Feature{Properties: properties, Geometry: MultiPolygon{Polygon(rings)}}
+}
+
+func TestNewAreaIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		features  []Feature
+		wantCodes []string
+		wantNames []string
+		wantErr   error
+	}{
+		{
+			name: "code and name properties",
+			features: []Feature{
+				areaFeature(map[string]interface{}{"code": "13104", "name": "新宿区"}, square(0, 0, 1)),
+				areaFeature(map[string]interface{}{"code": "13101", "name": "千代田区"}, square(1, 0, 1)),
+			},
+			wantCodes: []string{"13101", "13104"},
+			wantNames: []string{"千代田区", "新宿区"},
+		},
+		{
+			name: "N03 properties and features of the same code are merged",
+			features: []Feature{
+				areaFeature(map[string]interface{}{"N03_007": "13421", "N03_004": "小笠原村"}, square(142, 27, 1)),
+				areaFeature(map[string]interface{}{"N03_007": "13421", "N03_004": "小笠原村"}, square(141, 24, 1)),
+			},
+			wantCodes: []string{"13421"},
+			wantNames: []string{"小笠原村"},
+		},
+		{
+			name: "features without code are skipped",
+			features: []Feature{
+				areaFeature(map[string]interface{}{"N03_004": "所属未定地"}, square(0, 0, 1)),
+				areaFeature(map[string]interface{}{"code": "13104", "name": "新宿区"}, square(1, 0, 1)),
+			},
+			wantCodes: []string{"13104"},
+			wantNames: []string{"新宿区"},
+		},
+		{
+			name:    "no areas",
+			wantErr: ErrNoAreaData,
+		},
+		{
+			name:     "only features without code",
+			features: []Feature{areaFeature(map[string]interface{}{}, square(0, 0, 1))},
+			wantErr:  ErrNoAreaData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, err := NewAreaIndex(tt.features)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewAreaIndex() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			areas := idx.Areas()
+			if len(areas) != len(tt.wantCodes) {
+				t.Fatalf("got %d areas, want %d", len(areas), len(tt.wantCodes))
+			}
+			for i, area := range areas {
+				if area.Code != tt.wantCodes[i] || area.Name != tt.wantNames[i] {
+					t.Errorf("area %d = %s %s, want %s %s", i, area.Code, area.Name, tt.wantCodes[i], tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAreaIndexResolve(t *testing.T) {
+	idx, err := NewAreaIndex([]Feature{
+		areaFeature(map[string]interface{}{"code": "13101", "name": "千代田区"}, square(0, 0, 1)),
+		// 穴の部分は別の区域
+		areaFeature(map[string]interface{}{"code": "13104", "name": "新宿区"}, square(1, 0, 3), square(2, 1, 1)),
+		areaFeature(map[string]interface{}{"code": "13113", "name": "渋谷区"}, square(2, 1, 1)),
+		areaFeature(map[string]interface{}{"code": "13421", "name": "小笠原村"}, square(10, 0, 1)),
+		areaFeature(map[string]interface{}{"code": "13421", "name": "小笠原村"}, square(20, 0, 1)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		point    Point
+		wantCode string
+	}{
+		{"first area", Point{Longitude: 0.5, Latitude: 0.5}, "13101"},
+		{"second area", Point{Longitude: 1.5, Latitude: 0.5}, "13104"},
+		{"hole belongs to another area", Point{Longitude: 2.5, Latitude: 1.5}, "13113"},
+		{"detached part of a merged area", Point{Longitude: 20.5, Latitude: 0.5}, "13421"},
+		{"outside of all areas", Point{Longitude: 5, Latitude: 5}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, ok := idx.Resolve(tt.point)
+			if ok != (tt.wantCode != "") || area.Code != tt.wantCode {
+				t.Errorf("Resolve(%v) = %q, %v, want %q", tt.point, area.Code, ok, tt.wantCode)
+			}
+		})
+	}
+
+	for _, code := range []string{"13101", "13113", "13421"} {
+		if area, ok := idx.Lookup(code); !ok || area.Code != code {
+			t.Errorf("Lookup(%s) = %q, %v", code, area.Code, ok)
+		}
+	}
+	if _, ok := idx.Lookup("13999"); ok {
+		t.Error("Lookup(13999) found an unknown area")
+	}
+}
+
+func TestTokyoAreas(t *testing.T) {
+	idx, err := TokyoAreas()
+	if errors.Is(err, ErrNoAreaData) && os.Getenv("REQUIRE_AREA_DATA") == "" {
+		// データは国土数値情報から生成するため、生成前のチェックアウトでは検証できない
+		// イメージのビルドではREQUIRE_AREA_DATAを指定し、データが空の場合は失敗させる
+		t.Skipf("bundled area data has not been generated yet (run `make area-data`): %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		point    Point
+		wantCode string
+		wantName string
+	}{
+		{"Shinjuku ward office", Point{Longitude: 139.7036, Latitude: 35.6938}, "13104", "新宿区"},
+		{"Tokyo metropolitan government", Point{Longitude: 139.6917, Latitude: 35.6895}, "13104", "新宿区"},
+		{"Chiyoda ward office", Point{Longitude: 139.7536, Latitude: 35.6940}, "13101", "千代田区"},
+		{"Hachioji city office", Point{Longitude: 139.3160, Latitude: 35.6664}, "13201", "八王子市"},
+		{"Yokohama is outside of Tokyo", Point{Longitude: 139.6380, Latitude: 35.4437}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, ok := idx.Resolve(tt.point)
+			if ok != (tt.wantCode != "") || area.Code != tt.wantCode || area.Name != tt.wantName {
+				t.Errorf("Resolve(%v) = %s %s, %v, want %s %s", tt.point, area.Code, area.Name, ok, tt.wantCode, tt.wantName)
+			}
+		})
+	}
+	if got := len(idx.Areas()); got < 62 {
+		t.Errorf("bundled data has %d areas, want the 62 municipalities of Tokyo", got)
+	}
+}
@@ -0,0 +1,16 @@
+package geo
+
+import "math"
+
+// earthRadius - 地球の平均半径（メートル）
+const earthRadius = 6_371_000
+
+// Distance - 2点間の大円距離（メートル）をハーバーサインの公式で求める
+func Distance(a, b Point) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,31 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64 // メートル
+		tol  float64
+	}{
+		{"same point", Point{139.7036, 35.6938}, Point{139.7036, 35.6938}, 0, 0},
+		{"one degree of latitude", Point{139, 35}, Point{139, 36}, 111_195, 1},
+		{"tokyo station to shinjuku station", Point{139.767125, 35.681236}, Point{139.700464, 35.689729}, 6_100, 100},
+		{"antipodes", Point{0, 0}, Point{180, 0}, math.Pi * earthRadius, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.a, tt.b)
+			if math.Abs(got-tt.want) > tt.tol {
+				t.Errorf("Distance() = %v, want %v±%v", got, tt.want, tt.tol)
+			}
+			if back := Distance(tt.b, tt.a); math.Abs(back-got) > 1e-6 {
+				t.Errorf("Distance is not symmetric: %v and %v", got, back)
+			}
+		})
+	}
+}
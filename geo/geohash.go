@@ -0,0 +1,83 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// geohashBase32 - ジオハッシュで使う32文字（a, i, l, oを含まない）
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash - 点を含むprecision文字のジオハッシュを求める（同じセルの点は同じ文字列になる）
+func Geohash(pt Point, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+	hash := make([]byte, 0, precision)
+	even := true // 経度から交互に二分する
+	bit, ch := 0, 0
+	for len(hash) < precision {
+		ch <<= 1
+		if even {
+			mid := (minLng + maxLng) / 2
+			if pt.Longitude >= mid {
+				ch |= 1
+				minLng = mid
+			} else {
+				maxLng = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if pt.Latitude >= mid {
+				ch |= 1
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			hash = append(hash, geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// geohashCellSize - precision文字のジオハッシュのセルの経度・緯度方向の大きさ（度）
+func geohashCellSize(precision int) (lng float64, lat float64) {
+	bits := 5 * precision
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 360 / math.Exp2(float64(lngBits)), 180 / math.Exp2(float64(latBits))
+}
+
+// GeohashesWithin - 点からradius（メートル）以内を覆うprecision文字のジオハッシュを昇順に返す
+// 半径を囲む矩形と重なるセルを返すため、範囲外の点を含むセルも返す（距離はDistanceで確認する）
+func GeohashesWithin(pt Point, radius float64, precision int) []string {
+	cellLng, cellLat := geohashCellSize(precision)
+	dLat := radius / earthRadius * 180 / math.Pi
+	dLng := dLat / math.Max(math.Cos(pt.Latitude*math.Pi/180), 1e-6)
+	minLat, maxLat := math.Max(pt.Latitude-dLat, -90), math.Min(pt.Latitude+dLat, 90)
+	minLng, maxLng := math.Max(pt.Longitude-dLng, -180), math.Min(pt.Longitude+dLng, 180)
+
+	seen := make(map[string]bool)
+	// セルの大きさずつ進め、最後に矩形の端も含める
+	for lat := minLat; ; lat = math.Min(lat+cellLat, maxLat) {
+		for lng := minLng; ; lng = math.Min(lng+cellLng, maxLng) {
+			seen[Geohash(Point{Longitude: lng, Latitude: lat}, precision)] = true
+			if lng >= maxLng {
+				break
+			}
+		}
+		if lat >= maxLat {
+			break
+		}
+	}
+
+	hashes := make([]string, 0, len(seen))
+	for hash := range seen {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		name      string
+		pt        Point
+		precision int
+		want      string
+	}{
+		// 公開されている実装と同じ値になることを確認する
+		{"spain", Point{Longitude: -5.6, Latitude: 42.6}, 5, "ezs42"},
+		{"jutland", Point{Longitude: 10.40744, Latitude: 57.64911}, 11, "u4pruydqqvj"},
+		{"origin", Point{Longitude: 0, Latitude: 0}, 5, "s0000"},
+		{"south west corner", Point{Longitude: -180, Latitude: -90}, 4, "0000"},
+		{"prefix of a longer hash", Point{Longitude: 10.40744, Latitude: 57.64911}, 6, "u4pruy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Geohash(tt.pt, tt.precision); got != tt.want {
+				t.Errorf("Geohash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeohashCellSize(t *testing.T) {
+	lng, lat := geohashCellSize(6)
+	if math.Abs(lng-0.010986328125) > 1e-12 || math.Abs(lat-0.0054931640625) > 1e-12 {
+		t.Errorf("geohashCellSize(6) = %v, %v", lng, lat)
+	}
+}
+
+func TestGeohashesWithin(t *testing.T) {
+	const precision = 6
+	cellLng, cellLat := geohashCellSize(precision)
+	// セルの中心（周囲100mが1つのセルに収まる）
+	center := Point{Longitude: 139.7 + cellLng/2, Latitude: 35.68 + cellLat/2}
+	center = Point{
+		Longitude: (math.Floor((center.Longitude+180)/cellLng)+0.5)*cellLng - 180,
+		Latitude:  (math.Floor((center.Latitude+90)/cellLat)+0.5)*cellLat - 90,
+	}
+	if got := GeohashesWithin(center, 100, precision); !reflect.DeepEqual(got, []string{Geohash(center, precision)}) {
+		t.Errorf("GeohashesWithin(center) = %v, want only its own cell", got)
+	}
+
+	// セルの角（周囲の4つのセルにまたがる）
+	corner := Point{Longitude: center.Longitude + cellLng/2, Latitude: center.Latitude + cellLat/2}
+	if got := GeohashesWithin(corner, 100, precision); len(got) != 4 {
+		t.Errorf("GeohashesWithin(corner) = %v, want 4 cells", got)
+	}
+
+	// 半径内の点のセルは必ず含まれる
+	pt := Point{Longitude: 139.7036, Latitude: 35.6938}
+	hashes := GeohashesWithin(pt, 1000, precision)
+	covered := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		covered[hash] = true
+	}
+	for angle := 0.0; angle < 360; angle += 15 {
+		for _, r := range []float64{0, 500, 999} {
+			dLat := r * math.Cos(angle*math.Pi/180) / earthRadius * 180 / math.Pi
+			dLng := r * math.Sin(angle*math.Pi/180) / earthRadius * 180 / math.Pi / math.Cos(pt.Latitude*math.Pi/180)
+			near := Point{Longitude: pt.Longitude + dLng, Latitude: pt.Latitude + dLat}
+			if Distance(pt, near) > 1000 {
+				continue
+			}
+			if !covered[Geohash(near, precision)] {
+				t.Errorf("cell of %v (%.0fm, %.0f°) is not covered by %v", near, r, angle, hashes)
+			}
+		}
+	}
+}
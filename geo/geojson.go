@@ -0,0 +1,138 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Feature - GeoJSONから読み込んだポリゴンとその属性
+type Feature struct {
+	Properties map[string]interface{}
+	Geometry   MultiPolygon
+}
+
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONObject        `json:"features"`
+	Geometry    *geoJSONObject         `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+	Geometries  []geoJSONObject        `json:"geometries"`
+}
+
+// ParseGeoJSON - GeoJSON(FeatureCollection / Feature / Geometry)からPolygon・MultiPolygonを読み込む
+// Polygon・MultiPolygon以外のジオメトリは無視する
+func ParseGeoJSON(data []byte) ([]Feature, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return parseObject(obj, nil)
+}
+
+// LoadGeoJSONFile - GeoJSONファイルを読み込む
+func LoadGeoJSONFile(path string) ([]Feature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	features, err := ParseGeoJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return features, nil
+}
+
+// LoadMultiPolygonFile - GeoJSONファイル内の全ポリゴンを1つのMultiPolygonとして読み込む
+func LoadMultiPolygonFile(path string) (MultiPolygon, error) {
+	features, err := LoadGeoJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var area MultiPolygon
+	for _, f := range features {
+		area = append(area, f.Geometry...)
+	}
+	if len(area) == 0 {
+		return nil, fmt.Errorf("%s contains no polygon", path)
+	}
+	return area, nil
+}
+
+func parseObject(obj geoJSONObject, properties map[string]interface{}) ([]Feature, error) {
+	switch obj.Type {
+	case "FeatureCollection":
+		var features []Feature
+		for _, f := range obj.Features {
+			parsed, err := parseObject(f, nil)
+			if err != nil {
+				return nil, err
+			}
+			features = append(features, parsed...)
+		}
+		return features, nil
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil, nil
+		}
+		return parseObject(*obj.Geometry, obj.Properties)
+	case "GeometryCollection":
+		var geometry MultiPolygon
+		for _, g := range obj.Geometries {
+			parsed, err := parseObject(g, properties)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range parsed {
+				geometry = append(geometry, f.Geometry...)
+			}
+		}
+		if len(geometry) == 0 {
+			return nil, nil
+		}
+		return []Feature{{Properties: properties, Geometry: geometry}}, nil
+	case "Polygon":
+		var coordinates [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coordinates); err != nil {
+			return nil, err
+		}
+		polygon, err := toPolygon(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		return []Feature{{Properties: properties, Geometry: MultiPolygon{polygon}}}, nil
+	case "MultiPolygon":
+		var coordinates [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coordinates); err != nil {
+			return nil, err
+		}
+		var geometry MultiPolygon
+		for _, c := range coordinates {
+			polygon, err := toPolygon(c)
+			if err != nil {
+				return nil, err
+			}
+			geometry = append(geometry, polygon)
+		}
+		return []Feature{{Properties: properties, Geometry: geometry}}, nil
+	}
+	// Point・LineStringなどは対象外
+	return nil, nil
+}
+
+func toPolygon(coordinates [][][]float64) (Polygon, error) {
+	polygon := make(Polygon, 0, len(coordinates))
+	for _, ring := range coordinates {
+		points := make([]Point, 0, len(ring))
+		for _, c := range ring {
+			if len(c) < 2 {
+				return nil, fmt.Errorf("invalid position: %v", c)
+			}
+			points = append(points, Point{Longitude: c[0], Latitude: c[1]})
+		}
+		polygon = append(polygon, points)
+	}
+	return polygon, nil
+}
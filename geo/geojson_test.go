@@ -0,0 +1,128 @@
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGeoJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantFeatures int
+		wantPolygons []int // Featureごとのポリゴン数
+		wantErr      bool
+	}{
+		{
+			name:         "bare polygon",
+			data:         `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+			wantFeatures: 1,
+			wantPolygons: []int{1},
+		},
+		{
+			name: "feature collection with polygon and multipolygon",
+			data: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","properties":{"code":"13101"},"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}},
+				{"type":"Feature","properties":{"code":"13102"},"geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[2,2],[3,2],[3,3],[2,2]]]]}}
+			]}`,
+			wantFeatures: 2,
+			wantPolygons: []int{1, 2},
+		},
+		{
+			name:         "geometry collection is merged",
+			data:         `{"type":"Feature","properties":{},"geometry":{"type":"GeometryCollection","geometries":[{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},{"type":"Point","coordinates":[0,0]},{"type":"Polygon","coordinates":[[[2,2],[3,2],[3,3],[2,2]]]}]}}`,
+			wantFeatures: 1,
+			wantPolygons: []int{2},
+		},
+		{
+			name:         "points and features without geometry are ignored",
+			data:         `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null},{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]}}]}`,
+			wantFeatures: 0,
+		},
+		{
+			name:    "invalid position",
+			data:    `{"type":"Polygon","coordinates":[[[0],[1,0],[1,1],[0,0]]]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"type":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			features, err := ParseGeoJSON([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGeoJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(features) != tt.wantFeatures {
+				t.Fatalf("got %d features, want %d", len(features), tt.wantFeatures)
+			}
+			for i, f := range features {
+				if len(f.Geometry) != tt.wantPolygons[i] {
+					t.Errorf("feature %d has %d polygons, want %d", i, len(f.Geometry), tt.wantPolygons[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseGeoJSONKeepsLongitudeLatitudeOrder(t *testing.T) {
+	features, err := ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[139.7,35.6],[139.8,35.6],[139.8,35.7],[139.7,35.6]]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := features[0].Geometry[0][0][0]; got != (Point{Longitude: 139.7, Latitude: 35.6}) {
+		t.Errorf("first position = %+v, want longitude 139.7 and latitude 35.6", got)
+	}
+}
+
+func TestLoadMultiPolygonFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		wantPolygons int
+		wantErr      bool
+	}{
+		{
+			name: "all polygons are merged into the service area",
+			path: write("area.geojson", `{"type":"FeatureCollection","features":[
+				{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}},
+				{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[2,2],[3,2],[3,3],[2,2]]]}}
+			]}`),
+			wantPolygons: 2,
+		},
+		{
+			name:    "no polygon",
+			path:    write("empty.geojson", `{"type":"FeatureCollection","features":[]}`),
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			path:    filepath.Join(dir, "missing.geojson"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, err := LoadMultiPolygonFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMultiPolygonFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(area) != tt.wantPolygons {
+				t.Errorf("got %d polygons, want %d", len(area), tt.wantPolygons)
+			}
+		})
+	}
+}
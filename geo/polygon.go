@@ -0,0 +1,50 @@
+package geo
+
+// Point - 経度・緯度の組（GeoJSONと同じ[経度, 緯度]の順）
+type Point struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// Polygon - 最初のリングが外周、2つ目以降が穴を表すポリゴン
+type Polygon [][]Point
+
+// MultiPolygon - 複数のポリゴン（飛び地や島しょ部を含む行政区域など）
+type MultiPolygon []Polygon
+
+// Contains - 点がポリゴン内にあるかを判定する（穴の中は含まない）
+func (p Polygon) Contains(pt Point) bool {
+	if len(p) == 0 || !ringContains(p[0], pt) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains - 点がいずれかのポリゴン内にあるかを判定する
+func (m MultiPolygon) Contains(pt Point) bool {
+	for _, p := range m {
+		if p.Contains(pt) {
+			return true
+		}
+	}
+	return false
+}
+
+// ringContains - レイキャスティング法による点の内外判定
+// 行政区域程度の範囲であれば経緯度をそのまま平面座標として扱っても問題ない
+func ringContains(ring []Point, pt Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Latitude > pt.Latitude) != (b.Latitude > pt.Latitude) &&
+			pt.Longitude < (b.Longitude-a.Longitude)*(pt.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
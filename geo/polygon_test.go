@@ -0,0 +1,76 @@
+package geo
+
+import "testing"
+
+// square - 左下(lng, lat)から一辺sizeの正方形のリング
+func square(lng, lat, size float64) []Point {
+	return []Point{
+		{Longitude: lng, Latitude: lat},
+		{Longitude: lng + size, Latitude: lat},
+		{Longitude: lng + size, Latitude: lat + size},
+		{Longitude: lng, Latitude: lat + size},
+		{Longitude: lng, Latitude: lat},
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	// 中央に穴の空いた正方形
+	withHole := Polygon{square(0, 0, 10), square(4, 4, 2)}
+	// L字型（凹多角形）
+	lShape := Polygon{{
+		{Longitude: 0, Latitude: 0},
+		{Longitude: 10, Latitude: 0},
+		{Longitude: 10, Latitude: 4},
+		{Longitude: 4, Latitude: 4},
+		{Longitude: 4, Latitude: 10},
+		{Longitude: 0, Latitude: 10},
+		{Longitude: 0, Latitude: 0},
+	}}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Point
+		want    bool
+	}{
+		{"inside", withHole, Point{Longitude: 1, Latitude: 1}, true},
+		{"outside", withHole, Point{Longitude: 11, Latitude: 1}, false},
+		{"in the hole", withHole, Point{Longitude: 5, Latitude: 5}, false},
+		{"between the hole and the outer ring", withHole, Point{Longitude: 7, Latitude: 5}, true},
+		{"inside the concave polygon", lShape, Point{Longitude: 2, Latitude: 8}, true},
+		{"in the notch of the concave polygon", lShape, Point{Longitude: 8, Latitude: 8}, false},
+		{"empty polygon", Polygon{}, Point{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	// 本土と離れた島のような飛び地
+	area := MultiPolygon{{square(139, 35, 1)}, {square(142, 27, 0.5)}}
+
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{"mainland", Point{Longitude: 139.5, Latitude: 35.5}, true},
+		{"island", Point{Longitude: 142.2, Latitude: 27.2}, true},
+		{"sea between them", Point{Longitude: 141, Latitude: 30}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := area.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+	if (MultiPolygon{}).Contains(Point{}) {
+		t.Error("empty MultiPolygon contains a point")
+	}
+}
@@ -0,0 +1,181 @@
+// Package imaging は添付画像の変換（向きの補正、メタデータの除去、サムネイルの生成）を行います。
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Size - 生成する画像の名前と長辺の最大ピクセル数
+type Size struct {
+	Name    string
+	MaxEdge int
+}
+
+// Sizes - 生成する画像のサイズ
+// largeは表示用の画像で、アップロードされた画像の代わりに配信する
+var Sizes = []Size{
+	{Name: "large", MaxEdge: 1600},
+	{Name: "medium", MaxEdge: 640},
+	{Name: "small", MaxEdge: 240},
+}
+
+// maxPixels - デコードする画像の最大ピクセル数（小さいファイルで巨大な画像を展開させる攻撃を防ぐ）
+const maxPixels = 50_000_000
+
+// jpegQuality - JPEGで出力する際の品質
+const jpegQuality = 85
+
+var (
+	// ErrUnsupportedFormat - JPEG・PNG・WebP以外のファイル
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	// ErrTooLarge - ピクセル数が上限を超える画像
+	ErrTooLarge = errors.New("image is too large")
+)
+
+// Variant - 変換後の画像
+type Variant struct {
+	Name        string
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Process - 画像をデコードし、EXIFの画像方向を反映したうえでSizesの各サイズの画像を生成する
+// 再エンコードするため、位置情報を含むメタデータは出力に含まれない。
+// PNGはPNGのまま（透過を保つため）、JPEG・WebPはJPEGで出力する。
+func Process(data []byte) ([]Variant, error) {
+	contentType := http.DetectContentType(data)
+	var decode func([]byte) (image.Image, error)
+	var decodeConfig func([]byte) (image.Config, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+	case "image/webp":
+		decode = func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) }
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, err := decodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	oriented := applyOrientation(toNRGBA(img), Orientation(data, contentType))
+
+	variants := make([]Variant, 0, len(Sizes))
+	for _, size := range Sizes {
+		resized := resize(oriented, size.MaxEdge)
+		variant := Variant{
+			Name:   size.Name,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		}
+		var buf bytes.Buffer
+		if contentType == "image/png" {
+			variant.ContentType, variant.Extension = "image/png", "png"
+			err = png.Encode(&buf, resized)
+		} else {
+			variant.ContentType, variant.Extension = "image/jpeg", "jpg"
+			err = jpeg.Encode(&buf, flatten(resized), &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		variant.Data = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// applyOrientation - EXIFの画像方向（1〜8）に従って画像を回転・反転する
+func applyOrientation(src *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		// 5〜8は縦横が入れ替わる
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 左右反転
+				sx, sy = w-1-x, y
+			case 3: // 180度回転
+				sx, sy = w-1-x, h-1-y
+			case 4: // 上下反転
+				sx, sy = x, h-1-y
+			case 5: // 左上と右下を結ぶ対角線で反転
+				sx, sy = y, x
+			case 6: // 時計回りに90度回転
+				sx, sy = y, h-1-x
+			case 7: // 右上と左下を結ぶ対角線で反転
+				sx, sy = w-1-y, h-1-x
+			case 8: // 反時計回りに90度回転
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// resize - 長辺がmaxEdge以下になるように縮小する（拡大はしない）
+func resize(src *image.NRGBA, maxEdge int) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxEdge && h <= maxEdge {
+		return src
+	}
+	dw, dh := maxEdge, maxEdge
+	if w >= h {
+		dh = max(h*maxEdge/w, 1)
+	} else {
+		dw = max(w*maxEdge/h, 1)
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// flatten - 透過部分を白で塗りつぶす（JPEGは透過を扱えないため）
+func flatten(src *image.NRGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Over)
+	return dst
+}
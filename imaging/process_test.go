@@ -0,0 +1,181 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+// letterImage - 各ピクセルのRに行ごとの文字（"ABC", "DEF"など）を入れた画像
+func letterImage(rows ...string) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := 0; x < len(row); x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: row[x], A: 0xFF})
+		}
+	}
+	return img
+}
+
+// letterRows - letterImageの逆変換
+func letterRows(img *image.NRGBA) []string {
+	b := img.Bounds()
+	rows := make([]string, 0, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var row strings.Builder
+		for x := b.Min.X; x < b.Max.X; x++ {
+			row.WriteByte(img.NRGBAAt(x, y).R)
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 保存されている画像（横3×縦2）。EXIFの画像方向は、表示する際にこの画像をどう変換するかを表す
+	src := []string{
+		"ABC",
+		"DEF",
+	}
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{0, []string{"ABC", "DEF"}},     // EXIFなし
+		{1, []string{"ABC", "DEF"}},     // 回転なし
+		{2, []string{"CBA", "FED"}},     // 左右反転
+		{3, []string{"FED", "CBA"}},     // 180度回転
+		{4, []string{"DEF", "ABC"}},     // 上下反転
+		{5, []string{"AD", "BE", "CF"}}, // 左上と右下を結ぶ対角線で反転
+		{6, []string{"DA", "EB", "FC"}}, // 時計回りに90度回転
+		{7, []string{"FC", "EB", "DA"}}, // 右上と左下を結ぶ対角線で反転
+		{8, []string{"CF", "BE", "AD"}}, // 反時計回りに90度回転
+		{9, []string{"ABC", "DEF"}},     // 範囲外は回転しない
+	}
+	for _, tt := range tests {
+		got := letterRows(applyOrientation(letterImage(src...), tt.orientation))
+		if strings.Join(got, "/") != strings.Join(tt.want, "/") {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+// exifSegment - 画像方向だけを持つEXIFのAPP1セグメント（ビッグエンディアン）
+func exifSegment(orientation int) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // IFDの項目数
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)
+	tiff = binary.BigEndian.AppendUint32(tiff, 0) // 次のIFDなし
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegWithExif - 横w×縦hのJPEGのSOIの直後にEXIFを挿入する
+func jpegWithExif(t *testing.T, w, h int, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), exifSegment(orientation)...), data[2:]...)
+}
+
+// jpegMarkers - JPEGのSOSまでのセグメントのマーカー
+func jpegMarkers(data []byte) []byte {
+	var markers []byte
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		markers = append(markers, data[i+1])
+		if data[i+1] == 0xDA {
+			break
+		}
+		i += 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+	}
+	return markers
+}
+
+func TestProcessAppliesOrientationAndStripsExif(t *testing.T) {
+	data := jpegWithExif(t, 40, 20, 6)
+	if got := Orientation(data, "image/jpeg"); got != 6 {
+		t.Fatalf("Orientation() of the input = %d, want 6", got)
+	}
+
+	variants, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(variants) != len(Sizes) {
+		t.Fatalf("variants = %d, want %d", len(variants), len(Sizes))
+	}
+	for _, v := range variants {
+		// 時計回りに90度回転するため縦横が入れ替わる
+		if v.Width != 20 || v.Height != 40 {
+			t.Errorf("%s: size = %dx%d, want 20x40", v.Name, v.Width, v.Height)
+		}
+		if v.ContentType != "image/jpeg" {
+			t.Errorf("%s: content type = %s, want image/jpeg", v.Name, v.ContentType)
+		}
+		// 位置情報などのメタデータを含むAPP1セグメントを出力しない
+		if markers := jpegMarkers(v.Data); bytes.IndexByte(markers, 0xE1) >= 0 {
+			t.Errorf("%s: output has an APP1 segment (markers % X)", v.Name, markers)
+		}
+		if got := Orientation(v.Data, "image/jpeg"); got != 1 {
+			t.Errorf("%s: Orientation() of the output = %d, want 1", v.Name, got)
+		}
+	}
+}
+
+// pngHeader - 横w×縦hと申告するだけの（画像データのない）PNG
+func pngHeader(w, h uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, w)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, h)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0) // 8bit RGBA
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcessRejectsTooManyPixels(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		tooMany bool
+	}{
+		{"over maxPixels", pngHeader(maxPixels/1000, 1001), true},
+		{"very large dimensions", pngHeader(100_000, 100_000), true},
+		// 上限ちょうどはデコードまで進む（画像データがないためデコードで失敗する）
+		{"exactly maxPixels", pngHeader(maxPixels/1000, 1000), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(tt.data)
+			if err == nil {
+				t.Fatal("Process() error = nil, want an error")
+			}
+			if got := errors.Is(err, ErrTooLarge); got != tt.tooMany {
+				t.Errorf("Process() error = %v, want ErrTooLarge: %v", err, tt.tooMany)
+			}
+		})
+	}
+}
+
+func TestProcessRejectsUnsupportedFormat(t *testing.T) {
+	if _, err := Process([]byte("GIF89a\x01\x00\x01\x00")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Process() error = %v, want ErrUnsupportedFormat", err)
+	}
+}
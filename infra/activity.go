@@ -0,0 +1,189 @@
+package infra
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// opinionActivityTableName - 意見ごとの反応数を時間単位（バケット）で集計するテーブル
+// キーは(bucket, opinionId)。bucketは"H#2006-01-02T15"（1時間）または"D#2006-01-02"（1日）。
+// expiresAtをTTL属性として、集計期間を過ぎたバケットはDynamoDBが削除する。
+const opinionActivityTableName = "opinionActivity"
+
+// TrendWindow - 急上昇の集計期間
+type TrendWindow string
+
+const (
+	TrendWindow24h TrendWindow = "24h" // 直近24時間（現在の1時間を含む1時間単位のバケット24個）
+	TrendWindow7d  TrendWindow = "7d"  // 直近7日間（今日を含む1日単位のバケット7個）
+)
+
+// activityBucket - 集計期間ごとのバケットの単位
+type activityBucket struct {
+	prefix   string
+	layout   string
+	size     time.Duration
+	count    int           // 集計期間に含めるバケット数（現在のバケットを含む）
+	lifetime time.Duration // バケットを保持する期間
+}
+
+var activityBuckets = map[TrendWindow]activityBucket{
+	TrendWindow24h: {prefix: "H#", layout: "2006-01-02T15", size: time.Hour, count: 24, lifetime: 2 * 24 * time.Hour},
+	TrendWindow7d:  {prefix: "D#", layout: "2006-01-02", size: 24 * time.Hour, count: 7, lifetime: 8 * 24 * time.Hour},
+}
+
+// ParseTrendWindow - 集計期間の文字列を検証する
+func ParseTrendWindow(value string) (TrendWindow, bool) {
+	_, ok := activityBuckets[TrendWindow(value)]
+	return TrendWindow(value), ok
+}
+
+func (b activityBucket) key(t time.Time) string {
+	return b.prefix + t.UTC().Truncate(b.size).Format(b.layout)
+}
+
+// TrendingOpinion - 集計期間内に反応が多かった意見
+type TrendingOpinion struct {
+	OpinionItem
+	RecentReactionCount int32 // 集計期間内に増えたリアクション数
+	RecentCommentCount  int32 // 集計期間内に増えたコメント数
+}
+
+// opinionActivityUpdates - トランザクション内で現在の全てのバケットの反応数を増減する
+// attributeは"reactions"または"comments"
+func opinionActivityUpdates(opinionId string, attribute string, delta string, now time.Time) []types.TransactWriteItem {
+	items := make([]types.TransactWriteItem, 0, len(activityBuckets))
+	for _, window := range []TrendWindow{TrendWindow24h, TrendWindow7d} {
+		bucket := activityBuckets[window]
+		expiresAt := now.UTC().Truncate(bucket.size).Add(bucket.lifetime).Unix()
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName: aws.String(opinionActivityTableName),
+			Key: map[string]types.AttributeValue{
+				"bucket":    &types.AttributeValueMemberS{Value: bucket.key(now)},
+				"opinionId": &types.AttributeValueMemberS{Value: opinionId},
+			},
+			UpdateExpression: aws.String("ADD #count :delta SET expiresAt = :expiresAt"),
+			ExpressionAttributeNames: map[string]string{
+				"#count": attribute,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":delta":     &types.AttributeValueMemberN{Value: delta},
+				":expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+			},
+		}})
+	}
+	return items
+}
+
+// GetTrendingOpinions - 集計期間内に増えたリアクション数とコメント数の合計が多い順に意見を取得するメソッド
+// 削除・非表示・未公開の意見は結果に含めず、それらを除いてlimit件になるまで順位の低い意見を補う
+func (db *DynamoDBClient) GetTrendingOpinions(ctx context.Context, window TrendWindow, limit int, now time.Time) ([]TrendingOpinion, error) {
+	bucket := activityBuckets[window]
+
+	// 集計期間内の全てのバケットを合計する
+	totals := make(map[string]*TrendingOpinion)
+	for i := 0; i < bucket.count; i++ {
+		key := bucket.key(now.Add(-time.Duration(i) * bucket.size))
+		err := db.queryAll(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(opinionActivityTableName),
+			KeyConditionExpression: aws.String("#bucket = :bucket"),
+			ExpressionAttributeNames: map[string]string{
+				"#bucket": "bucket",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":bucket": &types.AttributeValueMemberS{Value: key},
+			},
+		}, func(item map[string]types.AttributeValue) {
+			opinionId := item["opinionId"].(*types.AttributeValueMemberS).Value
+			t, ok := totals[opinionId]
+			if !ok {
+				t = &TrendingOpinion{OpinionItem: OpinionItem{ID: opinionId}}
+				totals[opinionId] = t
+			}
+			t.RecentReactionCount += numberAttribute(item, "reactions")
+			t.RecentCommentCount += numberAttribute(item, "comments")
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 非表示・未公開の意見を除いた後にlimit件になるよう、順位の高いものから不足分ずつ本文を取得する
+	ranked := rankTrending(totals)
+	trending := make([]TrendingOpinion, 0, min(limit, len(ranked)))
+	for len(ranked) > 0 && len(trending) < limit {
+		chunk := ranked[:min(limit-len(trending), len(ranked))]
+		ranked = ranked[len(chunk):]
+
+		keys := make([]batchGetKey, 0, len(chunk))
+		for _, t := range chunk {
+			keys = append(keys, batchGetKey{table: opinionsTableName, key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: t.ID},
+			}})
+		}
+		found := make(map[string]bool, len(keys))
+		err := db.batchGetAll(ctx, keys, func(_ string, item map[string]types.AttributeValue) {
+			opinion := opinionFromItem(item)
+			// 非表示・未公開の意見は含めない
+			if !opinion.IsPublic() {
+				return
+			}
+			totals[opinion.ID].OpinionItem = opinion
+			found[opinion.ID] = true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range chunk {
+			if found[t.ID] {
+				trending = append(trending, *t)
+			}
+		}
+	}
+	return trending, nil
+}
+
+// rankTrending - 集計期間内に反応があった意見を、反応数の合計が多い順（同数の場合は意見IDの昇順）に並べる
+func rankTrending(totals map[string]*TrendingOpinion) []*TrendingOpinion {
+	ranked := make([]*TrendingOpinion, 0, len(totals))
+	for _, t := range totals {
+		if t.RecentReactionCount+t.RecentCommentCount > 0 {
+			ranked = append(ranked, t)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		si := ranked[i].RecentReactionCount + ranked[i].RecentCommentCount
+		sj := ranked[j].RecentReactionCount + ranked[j].RecentCommentCount
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+	return ranked
+}
+
+// queryAll - Queryの全ページを取得し、1件ずつfnに渡す
+func (db *DynamoDBClient) queryAll(ctx context.Context, input *dynamodb.QueryInput, fn func(map[string]types.AttributeValue)) error {
+	for {
+		result, err := db.Client.Query(ctx, input)
+		if err != nil {
+			log.Printf("DynamoDB Query failed: %v", err)
+			return err
+		}
+		for _, item := range result.Items {
+			fn(item)
+		}
+		if result.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
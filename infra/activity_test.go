@@ -0,0 +1,47 @@
+package infra
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRankTrending(t *testing.T) {
+	totals := map[string]*TrendingOpinion{
+		"b": {OpinionItem: OpinionItem{ID: "b"}, RecentReactionCount: 3, RecentCommentCount: 1},
+		"a": {OpinionItem: OpinionItem{ID: "a"}, RecentReactionCount: 2, RecentCommentCount: 2},
+		"c": {OpinionItem: OpinionItem{ID: "c"}, RecentReactionCount: 10},
+		"d": {OpinionItem: OpinionItem{ID: "d"}, RecentCommentCount: 1},
+		// リアクションを取り消して合計が0になった意見は含めない
+		"e": {OpinionItem: OpinionItem{ID: "e"}, RecentReactionCount: 1, RecentCommentCount: -1},
+	}
+
+	var got []string
+	for _, t := range rankTrending(totals) {
+		got = append(got, t.ID)
+	}
+	if want := []string{"c", "a", "b", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rankTrending() = %v, want %v", got, want)
+	}
+}
+
+func TestActivityBucketKey(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 45, 0, 0, time.FixedZone("JST", 9*60*60))
+	tests := []struct {
+		window TrendWindow
+		want   string
+	}{
+		{TrendWindow24h, "H#2025-06-01T00"},
+		{TrendWindow7d, "D#2025-06-01"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.window), func(t *testing.T) {
+			if got := activityBuckets[tt.window].key(now); got != tt.want {
+				t.Errorf("key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, ok := ParseTrendWindow("30d"); ok {
+		t.Error("ParseTrendWindow(30d) is valid")
+	}
+}
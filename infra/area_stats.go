@@ -0,0 +1,302 @@
+package infra
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// areaStatsTableName - 区市町村ごとの意見数・コメント数・リアクション数を保持するテーブル
+// キーは(areaCode, bucket)。bucketは全期間の合計が"ALL"、1時間ごとの増減が"H#2006-01-02T15"（UTC）。
+// 意見・コメント・リアクションを保存・削除するトランザクションで増減する
+const areaStatsTableName = "areaStats"
+
+// AreaStatsTotalBucket - 全期間の合計のバケット
+const AreaStatsTotalBucket = "ALL"
+
+// opinionsByAreaReactionIndexName - 区市町村ごとに意見をリアクション数の多い順に取得するGSI
+// 射影する属性はid, opinion, reactionCount, createdDateTime, publicationStatus, hidden
+const opinionsByAreaReactionIndexName = "areaCode-reactionCount-index"
+
+// mostReactedPageSize - 最もリアクションされた意見を探す際に1回のQueryで読む件数
+const mostReactedPageSize = 20
+
+// areaStatsBucket - 日時を含む1時間のバケット
+func areaStatsBucket(t time.Time) string {
+	return "H#" + t.UTC().Truncate(time.Hour).Format("2006-01-02T15")
+}
+
+// AreaCounts - 区市町村ごとの件数
+type AreaCounts struct {
+	AreaCode      string
+	AreaName      string
+	OpinionCount  int32
+	CommentCount  int32
+	ReactionCount int32
+}
+
+// areaStatsUpdates - トランザクション内で区市町村の全期間の合計と、tを含む1時間のバケットの件数を増減する
+// attributeは"opinionCount"・"commentCount"・"reactionCount"。エリア外（area.Codeが空）の場合は何もしない
+func areaStatsUpdates(area Area, attribute string, delta string, t time.Time) []types.TransactWriteItem {
+	if area.Code == "" {
+		return nil
+	}
+	items := make([]types.TransactWriteItem, 0, 2)
+	for _, bucket := range []string{AreaStatsTotalBucket, areaStatsBucket(t)} {
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName: aws.String(areaStatsTableName),
+			Key: map[string]types.AttributeValue{
+				"areaCode": &types.AttributeValueMemberS{Value: area.Code},
+				"bucket":   &types.AttributeValueMemberS{Value: bucket},
+			},
+			UpdateExpression: aws.String("ADD #count :delta SET areaName = :areaName"),
+			ExpressionAttributeNames: map[string]string{
+				"#count": attribute,
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":delta":    &types.AttributeValueMemberN{Value: delta},
+				":areaName": &types.AttributeValueMemberS{Value: area.Name},
+			},
+		}})
+	}
+	return items
+}
+
+// opinionArea - 意見の区市町村を取得する（意見が存在しない・エリア外の場合は空）
+func (db *DynamoDBClient) opinionArea(ctx context.Context, opinionId string) (Area, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		ProjectionExpression: aws.String("areaCode, areaName"),
+	})
+	if err != nil {
+		return Area{}, err
+	}
+	var area Area
+	if code, ok := result.Item["areaCode"].(*types.AttributeValueMemberS); ok {
+		area.Code = code.Value
+		area.Name = result.Item["areaName"].(*types.AttributeValueMemberS).Value
+	}
+	return area, nil
+}
+
+// GetAreaCounts - 区市町村ごとの件数を取得するメソッド
+// from・toがゼロ値の場合は全期間の合計を返す。指定した場合はfromを含む1時間からtoの直前を含む1時間までのバケットを合計する
+// （1時間単位で集計するため、期間の端は1時間単位に広がる）。areaCodesがnilの場合はテーブル全体から集計する
+func (db *DynamoDBClient) GetAreaCounts(ctx context.Context, areaCodes []string, from time.Time, to time.Time) (map[string]AreaCounts, error) {
+	counts := make(map[string]AreaCounts)
+	add := func(item map[string]types.AttributeValue) {
+		code := item["areaCode"].(*types.AttributeValueMemberS).Value
+		c := counts[code]
+		c.AreaCode = code
+		if name, ok := item["areaName"].(*types.AttributeValueMemberS); ok {
+			c.AreaName = name.Value
+		}
+		c.OpinionCount += numberAttribute(item, "opinionCount")
+		c.CommentCount += numberAttribute(item, "commentCount")
+		c.ReactionCount += numberAttribute(item, "reactionCount")
+		counts[code] = c
+	}
+
+	lower, upper, total := areaStatsBucketRange(from, to)
+	if lower > upper {
+		return counts, nil
+	}
+
+	if areaCodes == nil {
+		err := db.scanAll(ctx, areaStatsTableName, "", func(item map[string]types.AttributeValue) {
+			bucket := item["bucket"].(*types.AttributeValueMemberS).Value
+			if bucket >= lower && bucket <= upper {
+				add(item)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return counts, nil
+	}
+
+	if total {
+		keys := make([]batchGetKey, 0, len(areaCodes))
+		for _, code := range areaCodes {
+			keys = append(keys, batchGetKey{table: areaStatsTableName, key: map[string]types.AttributeValue{
+				"areaCode": &types.AttributeValueMemberS{Value: code},
+				"bucket":   &types.AttributeValueMemberS{Value: AreaStatsTotalBucket},
+			}})
+		}
+		err := db.batchGetAll(ctx, keys, func(_ string, item map[string]types.AttributeValue) {
+			add(item)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return counts, nil
+	}
+
+	for _, code := range areaCodes {
+		err := db.queryAll(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(areaStatsTableName),
+			KeyConditionExpression: aws.String("areaCode = :areaCode AND #bucket BETWEEN :lower AND :upper"),
+			ExpressionAttributeNames: map[string]string{
+				"#bucket": "bucket",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":areaCode": &types.AttributeValueMemberS{Value: code},
+				":lower":    &types.AttributeValueMemberS{Value: lower},
+				":upper":    &types.AttributeValueMemberS{Value: upper},
+			},
+		}, add)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// areaStatsBucketRange - 期間に含まれるバケットの範囲（両端を含む）
+// 期間を指定しない場合は全期間の合計のバケットだけを範囲とし、totalをtrueにする
+func areaStatsBucketRange(from time.Time, to time.Time) (lower string, upper string, total bool) {
+	if from.IsZero() && to.IsZero() {
+		return AreaStatsTotalBucket, AreaStatsTotalBucket, true
+	}
+	// "ALL"は"H#"より前に並ぶため、開始を指定しない場合も"H#"から読む
+	lower, upper = "H#", "H#~"
+	if !from.IsZero() {
+		lower = areaStatsBucket(from)
+	}
+	if !to.IsZero() {
+		upper = areaStatsBucket(to.Add(-time.Nanosecond))
+	}
+	return lower, upper, false
+}
+
+// GetMostReactedOpinion - 区市町村の公開中の意見のうち、リアクション数が最も多いものを取得するメソッド
+// from・toを指定した場合はその期間に投稿された意見から選ぶ。リアクションされた意見がない場合はnilを返す
+func (db *DynamoDBClient) GetMostReactedOpinion(ctx context.Context, areaCode string, from time.Time, to time.Time) (*OpinionItem, error) {
+	// publicationStatusを持たない古い意見は公開中として扱う
+	filter := hiddenFilterExpression + " AND (attribute_not_exists(publicationStatus) OR publicationStatus = :published)"
+	values := map[string]types.AttributeValue{
+		":areaCode":  &types.AttributeValueMemberS{Value: areaCode},
+		":zero":      &types.AttributeValueMemberN{Value: "0"},
+		":notHidden": hiddenFilterValue,
+		":published": &types.AttributeValueMemberS{Value: string(OpinionPublished)},
+	}
+	if !from.IsZero() {
+		filter += " AND createdDateTime >= :from"
+		values[":from"] = &types.AttributeValueMemberS{Value: from.Format(time.RFC3339)}
+	}
+	if !to.IsZero() {
+		filter += " AND createdDateTime < :to"
+		values[":to"] = &types.AttributeValueMemberS{Value: to.Format(time.RFC3339)}
+	}
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(opinionsTableName),
+		IndexName:                 aws.String(opinionsByAreaReactionIndexName),
+		KeyConditionExpression:    aws.String("areaCode = :areaCode AND reactionCount > :zero"),
+		FilterExpression:          aws.String(filter),
+		ProjectionExpression:      aws.String("id, opinion, reactionCount"),
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(false), // リアクション数の多い順
+		Limit:                     aws.Int32(mostReactedPageSize),
+	}
+
+	// 条件に合う最初の意見が最もリアクションされた意見（同数の場合はGSIの並び順で先のもの）
+	for {
+		result, err := db.Client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Items) > 0 {
+			item := result.Items[0]
+			opinion := OpinionItem{
+				ID:            item["id"].(*types.AttributeValueMemberS).Value,
+				Opinion:       item["opinion"].(*types.AttributeValueMemberS).Value,
+				ReactionCount: numberAttribute(item, "reactionCount"),
+			}
+			return &opinion, nil
+		}
+		if result.LastEvaluatedKey == nil {
+			return nil, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// AreaCountsKey - 区市町村ごとの件数のキー
+type AreaCountsKey struct {
+	AreaCode string
+	Bucket   string
+}
+
+// AddAreaCounts - 日時tに起きた増減を、全期間の合計とtを含む1時間のバケットに加える（既存データの移行・補正用）
+// 日時が記録されていない古いデータは全期間の合計にだけ加える
+func AddAreaCounts(counts map[AreaCountsKey]AreaCounts, area Area, t time.Time, add func(*AreaCounts)) {
+	if area.Code == "" {
+		return
+	}
+	buckets := []string{AreaStatsTotalBucket}
+	if !t.IsZero() {
+		buckets = append(buckets, areaStatsBucket(t))
+	}
+	for _, bucket := range buckets {
+		key := AreaCountsKey{AreaCode: area.Code, Bucket: bucket}
+		c := counts[key]
+		c.AreaCode, c.AreaName = area.Code, area.Name
+		add(&c)
+		counts[key] = c
+	}
+}
+
+// ReplaceAreaCounts - 区市町村ごとの件数を集計し直した値で置き換えるメソッド（既存データの移行・補正用）
+// countsにないバケットは削除する
+func (db *DynamoDBClient) ReplaceAreaCounts(ctx context.Context, counts map[AreaCountsKey]AreaCounts) error {
+	var stale []map[string]types.AttributeValue
+	err := db.scanAll(ctx, areaStatsTableName, "", func(item map[string]types.AttributeValue) {
+		key := AreaCountsKey{
+			AreaCode: item["areaCode"].(*types.AttributeValueMemberS).Value,
+			Bucket:   item["bucket"].(*types.AttributeValueMemberS).Value,
+		}
+		if _, ok := counts[key]; !ok {
+			stale = append(stale, map[string]types.AttributeValue{
+				"areaCode": item["areaCode"],
+				"bucket":   item["bucket"],
+			})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		_, err := db.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(areaStatsTableName),
+			Key:       key,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, c := range counts {
+		_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(areaStatsTableName),
+			Item: map[string]types.AttributeValue{
+				"areaCode":      &types.AttributeValueMemberS{Value: key.AreaCode},
+				"bucket":        &types.AttributeValueMemberS{Value: key.Bucket},
+				"areaName":      &types.AttributeValueMemberS{Value: c.AreaName},
+				"opinionCount":  &types.AttributeValueMemberN{Value: strconv.Itoa(int(c.OpinionCount))},
+				"commentCount":  &types.AttributeValueMemberN{Value: strconv.Itoa(int(c.CommentCount))},
+				"reactionCount": &types.AttributeValueMemberN{Value: strconv.Itoa(int(c.ReactionCount))},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package infra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestAreaStatsBucketRange(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	from := time.Date(2024, 4, 1, 9, 30, 0, 0, jst)
+	to := time.Date(2024, 4, 2, 9, 0, 0, 0, jst)
+
+	tests := []struct {
+		name      string
+		from, to  time.Time
+		wantLower string
+		wantUpper string
+		wantTotal bool
+	}{
+		{"whole period reads the total", time.Time{}, time.Time{}, "ALL", "ALL", true},
+		{"from is widened to the hour and to is exclusive", from, to, "H#2024-04-01T00", "H#2024-04-01T23", false},
+		{"to inside an hour includes that hour", from, to.Add(time.Minute), "H#2024-04-01T00", "H#2024-04-02T00", false},
+		{"open end", from, time.Time{}, "H#2024-04-01T00", "H#~", false},
+		{"open start", time.Time{}, to, "H#", "H#2024-04-01T23", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper, total := areaStatsBucketRange(tt.from, tt.to)
+			if lower != tt.wantLower || upper != tt.wantUpper || total != tt.wantTotal {
+				t.Errorf("areaStatsBucketRange() = %q, %q, %v, want %q, %q, %v", lower, upper, total, tt.wantLower, tt.wantUpper, tt.wantTotal)
+			}
+			// 開始・終了を指定しない範囲も、1時間のバケットを全て含み全期間の合計を含まない
+			if !total && (AreaStatsTotalBucket >= lower && AreaStatsTotalBucket <= upper) {
+				t.Errorf("range %q..%q includes the total bucket", lower, upper)
+			}
+		})
+	}
+}
+
+func TestAreaStatsUpdates(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+
+	if got := areaStatsUpdates(Area{}, "opinionCount", "1", at); len(got) != 0 {
+		t.Errorf("updates for an opinion outside every area = %d, want 0", len(got))
+	}
+
+	got := areaStatsUpdates(Area{Code: "13101", Name: "千代田区"}, "commentCount", "-1", at)
+	var buckets []string
+	for _, item := range got {
+		update := item.Update
+		buckets = append(buckets, update.Key["bucket"].(*types.AttributeValueMemberS).Value)
+		if name := update.ExpressionAttributeNames["#count"]; name != "commentCount" {
+			t.Errorf("#count = %q, want commentCount", name)
+		}
+		if delta := update.ExpressionAttributeValues[":delta"].(*types.AttributeValueMemberN).Value; delta != "-1" {
+			t.Errorf(":delta = %q, want -1", delta)
+		}
+	}
+	if len(buckets) != 2 || buckets[0] != "ALL" || buckets[1] != "H#2024-04-01T09" {
+		t.Errorf("buckets = %v, want [ALL H#2024-04-01T09]", buckets)
+	}
+}
+
+func TestAddAreaCounts(t *testing.T) {
+	area := Area{Code: "13101", Name: "千代田区"}
+	at := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	counts := make(map[AreaCountsKey]AreaCounts)
+	opinion := func(c *AreaCounts) { c.OpinionCount++ }
+
+	AddAreaCounts(counts, area, at, opinion)
+	AddAreaCounts(counts, area, at.Add(10*time.Minute), opinion)
+	// 日時のない古いデータは全期間の合計にだけ数える
+	AddAreaCounts(counts, area, time.Time{}, opinion)
+	// エリア外の意見は数えない
+	AddAreaCounts(counts, Area{}, at, opinion)
+
+	if got := counts[AreaCountsKey{AreaCode: "13101", Bucket: "ALL"}].OpinionCount; got != 3 {
+		t.Errorf("total = %d, want 3", got)
+	}
+	if got := counts[AreaCountsKey{AreaCode: "13101", Bucket: "H#2024-04-01T09"}].OpinionCount; got != 2 {
+		t.Errorf("hour = %d, want 2", got)
+	}
+	if len(counts) != 2 {
+		t.Errorf("buckets = %d, want 2", len(counts))
+	}
+}
@@ -0,0 +1,368 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// attachmentsTableName - 意見の添付ファイルを管理するテーブル（キーは(opinionId, attachmentId)）
+const attachmentsTableName = "attachments"
+
+// AttachmentStatus - 添付ファイルの状態
+type AttachmentStatus string
+
+const (
+	AttachmentPending  AttachmentStatus = "pending"  // アップロードURLを発行済み
+	AttachmentUploaded AttachmentStatus = "uploaded" // アップロード後の検証が完了し、意見に紐づけ済み
+	AttachmentRejected AttachmentStatus = "rejected" // 検証に失敗し、オブジェクトを削除済み
+)
+
+// AttachmentItem - attachmentsテーブルの1行
+type AttachmentItem struct {
+	OpinionID       string
+	AttachmentID    string
+	MailAddress     string // アップロードしたユーザー（意見の投稿者）
+	ObjectKey       string
+	ContentType     string // 申告されたContent-Type
+	Size            int64  // 申告されたサイズ（バイト）
+	Status          AttachmentStatus
+	CreatedDateTime time.Time
+	Variants        map[string]string // 変換後の画像の名前とオブジェクトキー（変換前は空）
+}
+
+// OpinionAttachment - 意見に紐づけた添付ファイル（意見の項目のattachments属性に保持する）
+type OpinionAttachment struct {
+	ID          string            `json:"AttachmentId"`
+	ObjectKey   string            `json:"-"` // アップロードされたファイル（配信しない）
+	Variants    map[string]string `json:"-"` // 変換後の画像の名前とオブジェクトキー（変換前は空）
+	ContentType string            `json:"ContentType"`
+	URL         string            `json:"URL"`        // 表示用のURL（app層で設定する）
+	Thumbnails  map[string]string `json:"Thumbnails"` // サムネイルの名前とURL（app層で設定する）
+}
+
+// ErrAttachmentNotFound - 指定された添付ファイルが存在しない
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// GetOpinion - 意見を1件取得するメソッド
+func (db *DynamoDBClient) GetOpinion(ctx context.Context, opinionId string) (OpinionItem, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+	})
+	if err != nil {
+		return OpinionItem{}, err
+	}
+	if result.Item == nil {
+		return OpinionItem{}, ErrOpinionNotFound
+	}
+	return opinionFromItem(result.Item), nil
+}
+
+// CountAttachments - 意見の添付ファイル数を取得するメソッド
+// 検証に失敗したものと、since以前に発行してアップロードされなかったものは数えない
+func (db *DynamoDBClient) CountAttachments(ctx context.Context, opinionId string, since time.Time) (int, error) {
+	result, err := db.Client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(attachmentsTableName),
+		KeyConditionExpression: aws.String("opinionId = :opinionId"),
+		FilterExpression:       aws.String("#status = :uploaded OR (#status = :pending AND createdDateTime > :since)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":opinionId": &types.AttributeValueMemberS{Value: opinionId},
+			":uploaded":  &types.AttributeValueMemberS{Value: string(AttachmentUploaded)},
+			":pending":   &types.AttributeValueMemberS{Value: string(AttachmentPending)},
+			":since":     &types.AttributeValueMemberS{Value: since.Format(time.RFC3339)},
+		},
+		Select: types.SelectCount,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.Count), nil
+}
+
+// SaveAttachment - アップロードURLを発行した添付ファイルを保存するメソッド
+func (db *DynamoDBClient) SaveAttachment(ctx context.Context, attachment AttachmentItem) error {
+	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(attachmentsTableName),
+		Item: map[string]types.AttributeValue{
+			"opinionId":       &types.AttributeValueMemberS{Value: attachment.OpinionID},
+			"attachmentId":    &types.AttributeValueMemberS{Value: attachment.AttachmentID},
+			"mailAddress":     &types.AttributeValueMemberS{Value: attachment.MailAddress},
+			"objectKey":       &types.AttributeValueMemberS{Value: attachment.ObjectKey},
+			"contentType":     &types.AttributeValueMemberS{Value: attachment.ContentType},
+			"size":            &types.AttributeValueMemberN{Value: strconv.FormatInt(attachment.Size, 10)},
+			"status":          &types.AttributeValueMemberS{Value: string(AttachmentPending)},
+			"createdDateTime": &types.AttributeValueMemberS{Value: attachment.CreatedDateTime.Format(time.RFC3339)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(attachmentId)"),
+	})
+	return err
+}
+
+// GetAttachment - 添付ファイルを1件取得するメソッド
+func (db *DynamoDBClient) GetAttachment(ctx context.Context, opinionId string, attachmentId string) (AttachmentItem, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(attachmentsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId":    &types.AttributeValueMemberS{Value: opinionId},
+			"attachmentId": &types.AttributeValueMemberS{Value: attachmentId},
+		},
+	})
+	if err != nil {
+		return AttachmentItem{}, err
+	}
+	if result.Item == nil {
+		return AttachmentItem{}, ErrAttachmentNotFound
+	}
+	return attachmentFromItem(result.Item), nil
+}
+
+func attachmentFromItem(item map[string]types.AttributeValue) AttachmentItem {
+	var attachment AttachmentItem
+	attachment.OpinionID = item["opinionId"].(*types.AttributeValueMemberS).Value
+	attachment.AttachmentID = item["attachmentId"].(*types.AttributeValueMemberS).Value
+	attachment.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
+	attachment.ObjectKey = item["objectKey"].(*types.AttributeValueMemberS).Value
+	attachment.ContentType = item["contentType"].(*types.AttributeValueMemberS).Value
+	attachment.Size, _ = strconv.ParseInt(item["size"].(*types.AttributeValueMemberN).Value, 10, 64)
+	attachment.Status = AttachmentStatus(item["status"].(*types.AttributeValueMemberS).Value)
+	attachment.CreatedDateTime, _ = time.Parse(time.RFC3339, item["createdDateTime"].(*types.AttributeValueMemberS).Value)
+	attachment.Variants = variantsFromAttribute(item["variants"])
+	return attachment
+}
+
+// variantsAttribute - 変換後の画像のオブジェクトキーをMap型の属性に変換する
+func variantsAttribute(variants map[string]string) *types.AttributeValueMemberM {
+	m := make(map[string]types.AttributeValue, len(variants))
+	for name, key := range variants {
+		m[name] = &types.AttributeValueMemberS{Value: key}
+	}
+	return &types.AttributeValueMemberM{Value: m}
+}
+
+func variantsFromAttribute(attribute types.AttributeValue) map[string]string {
+	m, ok := attribute.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil
+	}
+	variants := make(map[string]string, len(m.Value))
+	for name, v := range m.Value {
+		if key, ok := v.(*types.AttributeValueMemberS); ok {
+			variants[name] = key.Value
+		}
+	}
+	return variants
+}
+
+// CompleteAttachment - 検証が完了した添付ファイルを意見に紐づけるメソッド
+// 添付ファイルの状態の更新と意見への追加を同じトランザクションで行い、二重に追加しない。
+// 読み込んだ後に画像の変換が完了した場合は、変換後の画像を含めて紐づけ直す。
+// 紐づけた時点の添付ファイルを返す
+func (db *DynamoDBClient) CompleteAttachment(ctx context.Context, attachment AttachmentItem) (AttachmentItem, error) {
+	for {
+		err := db.completeAttachment(ctx, attachment)
+		if isConditionFailedAt(err, 1) {
+			return AttachmentItem{}, ErrOpinionNotFound
+		}
+		if !isConditionFailedAt(err, 0) {
+			attachment.Status = AttachmentUploaded
+			return attachment, err
+		}
+		// 状態または変換後の画像が読み込んだ時点から変わっている
+		current, err := db.GetAttachment(ctx, attachment.OpinionID, attachment.AttachmentID)
+		if err != nil {
+			return AttachmentItem{}, err
+		}
+		if current.Status != AttachmentPending {
+			// 既に紐づけ済み（同じリクエストの再送）
+			return current, nil
+		}
+		attachment = current
+	}
+}
+
+func (db *DynamoDBClient) completeAttachment(ctx context.Context, attachment AttachmentItem) error {
+	entry := map[string]types.AttributeValue{
+		"attachmentId": &types.AttributeValueMemberS{Value: attachment.AttachmentID},
+		"objectKey":    &types.AttributeValueMemberS{Value: attachment.ObjectKey},
+		"contentType":  &types.AttributeValueMemberS{Value: attachment.ContentType},
+	}
+	condition := "#status = :pending AND attribute_not_exists(variants)"
+	if len(attachment.Variants) > 0 {
+		entry["variants"] = variantsAttribute(attachment.Variants)
+		condition = "#status = :pending AND attribute_exists(variants)"
+	}
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Update: &types.Update{
+				TableName: aws.String(attachmentsTableName),
+				Key: map[string]types.AttributeValue{
+					"opinionId":    &types.AttributeValueMemberS{Value: attachment.OpinionID},
+					"attachmentId": &types.AttributeValueMemberS{Value: attachment.AttachmentID},
+				},
+				ConditionExpression: aws.String(condition),
+				UpdateExpression:    aws.String("SET #status = :uploaded"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":pending":  &types.AttributeValueMemberS{Value: string(AttachmentPending)},
+					":uploaded": &types.AttributeValueMemberS{Value: string(AttachmentUploaded)},
+				},
+			}},
+			{Update: &types.Update{
+				TableName: aws.String(opinionsTableName),
+				Key: map[string]types.AttributeValue{
+					"id": &types.AttributeValueMemberS{Value: attachment.OpinionID},
+				},
+				ConditionExpression: aws.String(opinionExistsCondition),
+				UpdateExpression:    aws.String("SET attachments = list_append(if_not_exists(attachments, :empty), :attachment)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":empty":      &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+					":attachment": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberM{Value: entry}}},
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// SetAttachmentVariants - 変換後の画像を添付ファイルに記録するメソッド
+// 既に意見に紐づけ済みの場合は、意見のattachments属性にも記録する。
+// 添付ファイルが存在しない、または検証に失敗していた場合はErrAttachmentNotFoundを返す
+func (db *DynamoDBClient) SetAttachmentVariants(ctx context.Context, opinionId string, attachmentId string, variants map[string]string) error {
+	result, err := db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(attachmentsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId":    &types.AttributeValueMemberS{Value: opinionId},
+			"attachmentId": &types.AttributeValueMemberS{Value: attachmentId},
+		},
+		ConditionExpression: aws.String("attribute_exists(attachmentId) AND #status <> :rejected"),
+		UpdateExpression:    aws.String("SET variants = :variants"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":variants": variantsAttribute(variants),
+			":rejected": &types.AttributeValueMemberS{Value: string(AttachmentRejected)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrAttachmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// 紐づけ前の場合はCompleteAttachmentが変換後の画像を含めて紐づける
+	if AttachmentStatus(result.Attributes["status"].(*types.AttributeValueMemberS).Value) != AttachmentUploaded {
+		return nil
+	}
+	return db.setOpinionAttachmentVariants(ctx, opinionId, attachmentId, variants)
+}
+
+// setOpinionAttachmentVariants - 意見のattachments属性の該当する添付ファイルに変換後の画像を記録する
+func (db *DynamoDBClient) setOpinionAttachmentVariants(ctx context.Context, opinionId string, attachmentId string, variants map[string]string) error {
+	for {
+		opinion, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(opinionsTableName),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: opinionId},
+			},
+			ConsistentRead:       aws.Bool(true),
+			ProjectionExpression: aws.String("attachments"),
+		})
+		if err != nil {
+			return err
+		}
+		index := slices.IndexFunc(attachmentsFromItem(opinion.Item), func(a OpinionAttachment) bool {
+			return a.ID == attachmentId
+		})
+		if index < 0 {
+			// 意見が削除された、または添付ファイルが外された
+			return nil
+		}
+
+		// 読み込んだ後に添付ファイルの位置が変わっていない場合のみ更新する
+		path := "attachments[" + strconv.Itoa(index) + "]"
+		_, err = db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(opinionsTableName),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: opinionId},
+			},
+			ConditionExpression: aws.String(path + ".attachmentId = :attachmentId"),
+			UpdateExpression:    aws.String("SET " + path + ".variants = :variants"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":attachmentId": &types.AttributeValueMemberS{Value: attachmentId},
+				":variants":     variantsAttribute(variants),
+			},
+		})
+		var conditionFailed *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionFailed) {
+			return err
+		}
+	}
+}
+
+// RejectAttachment - 検証に失敗した添付ファイルを記録するメソッド
+// 既に変換後の画像を記録していた場合は、削除できるように記録した添付ファイルを返す
+func (db *DynamoDBClient) RejectAttachment(ctx context.Context, opinionId string, attachmentId string) (AttachmentItem, error) {
+	result, err := db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(attachmentsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId":    &types.AttributeValueMemberS{Value: opinionId},
+			"attachmentId": &types.AttributeValueMemberS{Value: attachmentId},
+		},
+		ConditionExpression: aws.String("attribute_exists(attachmentId)"),
+		UpdateExpression:    aws.String("SET #status = :rejected"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":rejected": &types.AttributeValueMemberS{Value: string(AttachmentRejected)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return AttachmentItem{}, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return AttachmentItem{}, err
+	}
+	return attachmentFromItem(result.Attributes), nil
+}
+
+// attachmentsFromItem - 意見の項目から紐づけ済みの添付ファイルを取得する
+func attachmentsFromItem(item map[string]types.AttributeValue) []OpinionAttachment {
+	attachments := []OpinionAttachment{}
+	list, ok := item["attachments"].(*types.AttributeValueMemberL)
+	if !ok {
+		return attachments
+	}
+	for _, v := range list.Value {
+		m, ok := v.(*types.AttributeValueMemberM)
+		if !ok {
+			continue
+		}
+		attachments = append(attachments, OpinionAttachment{
+			ID:          m.Value["attachmentId"].(*types.AttributeValueMemberS).Value,
+			ObjectKey:   m.Value["objectKey"].(*types.AttributeValueMemberS).Value,
+			Variants:    variantsFromAttribute(m.Value["variants"]),
+			ContentType: m.Value["contentType"].(*types.AttributeValueMemberS).Value,
+		})
+	}
+	return attachments
+}
@@ -0,0 +1,37 @@
+package infra
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// categoriesTableName - カテゴリーごとの意見数を保持するテーブル（キーはcode）
+const categoriesTableName = "categories"
+
+// categoryCountUpdate - トランザクション内でカテゴリーの意見数を増減する
+func categoryCountUpdate(category string, delta string) types.TransactWriteItem {
+	return types.TransactWriteItem{Update: &types.Update{
+		TableName: aws.String(categoriesTableName),
+		Key: map[string]types.AttributeValue{
+			"code": &types.AttributeValueMemberS{Value: category},
+		},
+		UpdateExpression: aws.String("ADD opinionCount :delta"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: delta},
+		},
+	}}
+}
+
+// GetCategoryCounts - カテゴリーごとの意見数を取得するメソッド（意見がないカテゴリーは含まない）
+func (db *DynamoDBClient) GetCategoryCounts(ctx context.Context) (map[string]int32, error) {
+	counts := make(map[string]int32)
+	err := db.scanAll(ctx, categoriesTableName, "", func(item map[string]types.AttributeValue) {
+		counts[item["code"].(*types.AttributeValueMemberS).Value] = numberAttribute(item, "opinionCount")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
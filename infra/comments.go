@@ -0,0 +1,260 @@
+package infra
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxCommentDepth - 返信できる深さ（意見へのコメントを0として、返信の返信の返信まで）
+const MaxCommentDepth = 3
+
+// commentRepliesIndexName - 返信先のコメントごとに返信を投稿日時順に取得するGSI
+// 意見へのコメントはparentCommentIdを持たないため、このGSIには含まれない
+const commentRepliesIndexName = "parentCommentId-createdDateTime-index"
+
+var (
+	// ErrCommentNotFound - 指定されたコメントが存在しない
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrCommentDepthExceeded - 返信できる深さを超えている
+	ErrCommentDepthExceeded = errors.New("comment depth exceeded")
+)
+
+// CommentPage - 返信一覧の1ページ
+type CommentPage struct {
+	Comments   []CommentItem
+	NextCursor string // 次のページがない場合は空
+}
+
+// commentCursor - 次のページの開始位置（最後に評価した返信のキー）
+type commentCursor struct {
+	ParentCommentID string `json:"p"`
+	CommentID       string `json:"id"`
+	CreatedDateTime string `json:"t"`
+}
+
+// commentReplyCountUpdate - トランザクション内で返信先のコメントが存在する場合のみ返信数を増減する
+func commentReplyCountUpdate(opinionId string, commentId string, delta string) types.TransactWriteItem {
+	return types.TransactWriteItem{Update: &types.Update{
+		TableName: aws.String(commentsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId": &types.AttributeValueMemberS{Value: opinionId},
+			"commentId": &types.AttributeValueMemberS{Value: commentId},
+		},
+		ConditionExpression: aws.String("attribute_exists(commentId)"),
+		UpdateExpression:    aws.String("ADD replyCount :delta"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: delta},
+		},
+	}}
+}
+
+// GetCommentItem - コメントを1件取得するメソッド
+func (db *DynamoDBClient) GetCommentItem(ctx context.Context, opinionId string, commentId string) (CommentItem, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(commentsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId": &types.AttributeValueMemberS{Value: opinionId},
+			"commentId": &types.AttributeValueMemberS{Value: commentId},
+		},
+	})
+	if err != nil {
+		return CommentItem{}, err
+	}
+	if result.Item == nil {
+		return CommentItem{}, ErrCommentNotFound
+	}
+	return commentFromItem(result.Item), nil
+}
+
+// GetCommentReplies - コメントへの返信を投稿日時順に1ページ取得するメソッド
+// 非表示のコメントへの返信は取得できず、非表示の返信は含めない
+func (db *DynamoDBClient) GetCommentReplies(ctx context.Context, opinionId string, parentCommentId string, limit int32, cursor string) (CommentPage, error) {
+	parent, err := db.GetCommentItem(ctx, opinionId, parentCommentId)
+	if err != nil {
+		return CommentPage{}, err
+	}
+	if parent.Hidden {
+		return CommentPage{}, ErrCommentNotFound
+	}
+	startKey, err := decodeCommentCursor(cursor, opinionId, parentCommentId)
+	if err != nil {
+		return CommentPage{}, err
+	}
+
+	result, err := db.Client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(commentsTableName),
+		IndexName:              aws.String(commentRepliesIndexName),
+		KeyConditionExpression: aws.String("parentCommentId = :parentCommentId"),
+		FilterExpression:       aws.String(hiddenFilterExpression),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":parentCommentId": &types.AttributeValueMemberS{Value: parentCommentId},
+			":notHidden":       hiddenFilterValue,
+		},
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(limit),
+	})
+	if err != nil {
+		log.Printf("DynamoDB Query failed: %v", err)
+		return CommentPage{}, err
+	}
+
+	page := CommentPage{Comments: make([]CommentItem, 0, len(result.Items))}
+	for _, item := range result.Items {
+		page.Comments = append(page.Comments, commentFromItem(item))
+	}
+	// 非表示の返信を除いた結果が空でも続きがあるため、最後に評価したキーから次のページを始める
+	if result.LastEvaluatedKey != nil {
+		page.NextCursor, err = encodeCommentCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return CommentPage{}, err
+		}
+	}
+	return page, nil
+}
+
+func encodeCommentCursor(key map[string]types.AttributeValue) (string, error) {
+	b, err := json.Marshal(commentCursor{
+		ParentCommentID: key["parentCommentId"].(*types.AttributeValueMemberS).Value,
+		CommentID:       key["commentId"].(*types.AttributeValueMemberS).Value,
+		CreatedDateTime: key["createdDateTime"].(*types.AttributeValueMemberS).Value,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCommentCursor - カーソルをQueryのExclusiveStartKeyに変換する（空の場合は先頭から）
+func decodeCommentCursor(cursor string, opinionId string, parentCommentId string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c commentCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ParentCommentID != parentCommentId || c.CommentID == "" || c.CreatedDateTime == "" {
+		return nil, ErrInvalidCursor
+	}
+	return map[string]types.AttributeValue{
+		"opinionId":       &types.AttributeValueMemberS{Value: opinionId},
+		"commentId":       &types.AttributeValueMemberS{Value: c.CommentID},
+		"parentCommentId": &types.AttributeValueMemberS{Value: c.ParentCommentID},
+		"createdDateTime": &types.AttributeValueMemberS{Value: c.CreatedDateTime},
+	}, nil
+}
+
+// commentReactionsTableName - コメントへのユーザーごとのリアクション状態を管理するテーブル（キーは(commentId, mailAddress)）
+const commentReactionsTableName = "commentReactions"
+
+// SaveCommentReaction - コメントへのリアクションを保存するメソッド
+// 状態が変わる場合のみコメントのリアクション数を増減する
+func (db *DynamoDBClient) SaveCommentReaction(ctx context.Context, opinionId string, commentId string, mailAddress string, isReactioned bool) (Reaction, error) {
+	reaction := Reaction{Type: DefaultReactionType, IsReactioned: isReactioned}
+
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: commentReactionTransactItems(opinionId, commentId, mailAddress, isReactioned, time.Now()),
+	})
+	if isConditionFailedAt(err, 0) {
+		return Reaction{}, ErrCommentNotFound
+	}
+	if isConditionFailedAt(err, 1) {
+		// 既に同じ状態のため何もしない
+		return reaction, nil
+	}
+	if err != nil {
+		return Reaction{}, err
+	}
+	return reaction, nil
+}
+
+// commentReactionTransactItems - コメントへのリアクションを保存するトランザクションの項目
+// 先頭がコメントのリアクション数の増減（コメントが存在しない場合に失敗する）、2番目がリアクションの更新（既に同じ状態の場合に失敗する）
+func commentReactionTransactItems(opinionId string, commentId string, mailAddress string, isReactioned bool, now time.Time) []types.TransactWriteItem {
+	reactionUpdate := &types.Update{
+		TableName: aws.String(commentReactionsTableName),
+		Key: map[string]types.AttributeValue{
+			"commentId":   &types.AttributeValueMemberS{Value: commentId},
+			"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
+		},
+		UpdateExpression: aws.String("SET opinionId = :opinionId, isReactioned = :isReactioned, reactedDateTime = :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":opinionId":    &types.AttributeValueMemberS{Value: opinionId},
+			":isReactioned": &types.AttributeValueMemberBOOL{Value: isReactioned},
+			":now":          &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		},
+	}
+	// 状態が変わる場合のみ更新する条件（式で使わない値を渡すとDynamoDBが拒否するため、条件で使う値だけを設定する）
+	delta := "1"
+	if isReactioned {
+		reactionUpdate.ConditionExpression = aws.String("attribute_not_exists(isReactioned) OR isReactioned = :false")
+		reactionUpdate.ExpressionAttributeValues[":false"] = &types.AttributeValueMemberBOOL{Value: false}
+	} else {
+		delta = "-1"
+		reactionUpdate.ConditionExpression = aws.String("isReactioned = :true")
+		reactionUpdate.ExpressionAttributeValues[":true"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+
+	return []types.TransactWriteItem{
+		// コメントが存在する場合のみカウントを増減する
+		{Update: &types.Update{
+			TableName: aws.String(commentsTableName),
+			Key: map[string]types.AttributeValue{
+				"opinionId": &types.AttributeValueMemberS{Value: opinionId},
+				"commentId": &types.AttributeValueMemberS{Value: commentId},
+			},
+			ConditionExpression: aws.String("attribute_exists(commentId)"),
+			UpdateExpression:    aws.String("ADD reactionCount :delta"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":delta": &types.AttributeValueMemberN{Value: delta},
+			},
+		}},
+		{Update: reactionUpdate},
+	}
+}
+
+// SetCommentReactionStates - コメントごとの自分のリアクション状態をBatchGetItemでまとめて取得し、IsReactionedに設定するメソッド
+// 返信（Replies）も含めて設定する
+func (db *DynamoDBClient) SetCommentReactionStates(ctx context.Context, comments []CommentItem, mailAddress string) error {
+	var keys []batchGetKey
+	var collect func([]CommentItem)
+	collect = func(comments []CommentItem) {
+		for _, comment := range comments {
+			keys = append(keys, batchGetKey{table: commentReactionsTableName, key: map[string]types.AttributeValue{
+				"commentId":   &types.AttributeValueMemberS{Value: comment.CommentID},
+				"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
+			}})
+			collect(comment.Replies)
+		}
+	}
+	collect(comments)
+
+	reacted := make(map[string]bool, len(keys))
+	err := db.batchGetAll(ctx, keys, func(_ string, item map[string]types.AttributeValue) {
+		if v, ok := item["isReactioned"].(*types.AttributeValueMemberBOOL); ok && v.Value {
+			reacted[item["commentId"].(*types.AttributeValueMemberS).Value] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	var set func([]CommentItem)
+	set = func(comments []CommentItem) {
+		for i := range comments {
+			comments[i].IsReactioned = reacted[comments[i].CommentID]
+			set(comments[i].Replies)
+		}
+	}
+	set(comments)
+	return nil
+}
@@ -0,0 +1,49 @@
+package infra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestCommentReactionTransactItems(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		isReactioned  bool
+		wantDelta     string
+		wantCondition string
+	}{
+		{"react", true, "1", "attribute_not_exists(isReactioned) OR isReactioned = :false"},
+		{"unreact", false, "-1", "isReactioned = :true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := commentReactionTransactItems("1", "c1", "a@example.com", tt.isReactioned, now)
+			if len(items) != 2 {
+				t.Fatalf("items = %d, want 2", len(items))
+			}
+			checkExpressionValues(t, items)
+
+			// 先頭はコメントが存在する場合のみリアクション数を増減する項目（失敗時にErrCommentNotFoundを返すため順番を変えない）
+			count := items[0].Update
+			if aws.ToString(count.TableName) != commentsTableName {
+				t.Errorf("items[0] table = %s, want %s", aws.ToString(count.TableName), commentsTableName)
+			}
+			if got := count.ExpressionAttributeValues[":delta"].(*types.AttributeValueMemberN).Value; got != tt.wantDelta {
+				t.Errorf(":delta = %q, want %q", got, tt.wantDelta)
+			}
+
+			reaction := items[1].Update
+			if aws.ToString(reaction.TableName) != commentReactionsTableName {
+				t.Errorf("items[1] table = %s, want %s", aws.ToString(reaction.TableName), commentReactionsTableName)
+			}
+			if got := aws.ToString(reaction.ConditionExpression); got != tt.wantCondition {
+				t.Errorf("condition = %q, want %q", got, tt.wantCondition)
+			}
+		})
+	}
+}
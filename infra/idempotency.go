@@ -0,0 +1,163 @@
+package infra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// idempotencyKeysTableName - Idempotency-Keyごとに最初のレスポンスを保存するテーブル（キーはidempotencyKey、expiresAtをTTLに設定する）
+const idempotencyKeysTableName = "idempotencyKeys"
+
+// idempotencyLockDuration - 処理中の予約を有効とする時間（Lambdaがタイムアウトした場合に予約が残り続けないようにする）
+// 処理中のリクエストの予約を期限切れとして奪わないよう、Lambdaのタイムアウトの上限（15分）以上にする
+const idempotencyLockDuration = 15 * time.Minute
+
+// ErrIdempotencyReservationLost - 予約の期限が切れ、別のリクエストが予約し直した（または保存済み）
+var ErrIdempotencyReservationLost = errors.New("idempotency reservation lost")
+
+// idempotencyReservedCondition - 自分の予約が処理中のまま残っていることを確認する条件式
+const idempotencyReservedCondition = "reservation = :reservation AND attribute_not_exists(responseStatus)"
+
+// IdempotencyRecord - Idempotency-Keyの保存内容（ResponseStatusが0の場合は処理中）
+type IdempotencyRecord struct {
+	Fingerprint    string
+	ResponseStatus int
+	Headers        http.Header
+	Body           []byte
+}
+
+// ReserveIdempotencyKey - キーを予約するメソッド
+// 予約できた場合は予約を識別するトークンを、既に使われている場合は保存されている内容を返す。
+// 期限切れのキーと、ロックの期限が過ぎた処理中のキーは予約し直す
+func (db *DynamoDBClient) ReserveIdempotencyKey(ctx context.Context, key string, fingerprint string, ttl time.Duration) (string, *IdempotencyRecord, error) {
+	now := time.Now()
+	nowValue := &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)}
+	reservation := uuid.New().String()
+	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(idempotencyKeysTableName),
+		Item: map[string]types.AttributeValue{
+			"idempotencyKey": &types.AttributeValueMemberS{Value: key},
+			"fingerprint":    &types.AttributeValueMemberS{Value: fingerprint},
+			"reservation":    &types.AttributeValueMemberS{Value: reservation},
+			"lockedUntil":    &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(idempotencyLockDuration).Unix(), 10)},
+			"expiresAt":      &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(ttl).Unix(), 10)},
+		},
+		ConditionExpression: aws.String(
+			"attribute_not_exists(idempotencyKey) OR expiresAt < :now OR (attribute_not_exists(responseStatus) AND lockedUntil < :now)",
+		),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":now": nowValue},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err == nil {
+		return reservation, nil, nil
+	}
+	if !errors.As(err, &conditionFailed) {
+		return "", nil, err
+	}
+
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(idempotencyKeysTableName),
+		Key:            map[string]types.AttributeValue{"idempotencyKey": &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if result.Item == nil {
+		// 予約の失敗と取得の間に削除された（Releaseされた）場合は処理中として扱い、再試行させる
+		return "", &IdempotencyRecord{Fingerprint: fingerprint}, nil
+	}
+	record, err := idempotencyRecordFromItem(result.Item)
+	return "", record, err
+}
+
+// CompleteIdempotencyKey - 予約したキーにレスポンスを保存するメソッド
+// 予約の期限が切れて別のリクエストが予約し直した場合は、そのリクエストの分を上書きせずにErrIdempotencyReservationLostを返す
+func (db *DynamoDBClient) CompleteIdempotencyKey(ctx context.Context, key string, reservation string, record IdempotencyRecord, ttl time.Duration) error {
+	input, err := idempotencyCompleteInput(key, reservation, record, ttl, time.Now())
+	if err != nil {
+		return err
+	}
+	_, err = db.Client.PutItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrIdempotencyReservationLost
+	}
+	return err
+}
+
+// idempotencyCompleteInput - レスポンスを保存するPutItemの入力（自分の予約が処理中のまま残っている場合のみ保存する）
+func idempotencyCompleteInput(key string, reservation string, record IdempotencyRecord, ttl time.Duration, now time.Time) (*dynamodb.PutItemInput, error) {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return nil, err
+	}
+	body := record.Body
+	if body == nil {
+		body = []byte{}
+	}
+	return &dynamodb.PutItemInput{
+		TableName: aws.String(idempotencyKeysTableName),
+		Item: map[string]types.AttributeValue{
+			"idempotencyKey": &types.AttributeValueMemberS{Value: key},
+			"fingerprint":    &types.AttributeValueMemberS{Value: record.Fingerprint},
+			"reservation":    &types.AttributeValueMemberS{Value: reservation},
+			"responseStatus": &types.AttributeValueMemberN{Value: strconv.Itoa(record.ResponseStatus)},
+			"headers":        &types.AttributeValueMemberS{Value: string(headers)},
+			"body":           &types.AttributeValueMemberB{Value: body},
+			"expiresAt":      &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(ttl).Unix(), 10)},
+		},
+		ConditionExpression: aws.String(idempotencyReservedCondition),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":reservation": &types.AttributeValueMemberS{Value: reservation},
+		},
+	}, nil
+}
+
+// ReleaseIdempotencyKey - 処理中のキーの予約を取り消すメソッド
+// 保存済みのレスポンスと、期限切れ後に別のリクエストが予約し直したものは削除しない
+func (db *DynamoDBClient) ReleaseIdempotencyKey(ctx context.Context, key string, reservation string) error {
+	_, err := db.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(idempotencyKeysTableName),
+		Key:                 map[string]types.AttributeValue{"idempotencyKey": &types.AttributeValueMemberS{Value: key}},
+		ConditionExpression: aws.String(idempotencyReservedCondition),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":reservation": &types.AttributeValueMemberS{Value: reservation},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+func idempotencyRecordFromItem(item map[string]types.AttributeValue) (*IdempotencyRecord, error) {
+	record := &IdempotencyRecord{}
+	if v, ok := item["fingerprint"].(*types.AttributeValueMemberS); ok {
+		record.Fingerprint = v.Value
+	}
+	status, ok := item["responseStatus"].(*types.AttributeValueMemberN)
+	if !ok {
+		return record, nil
+	}
+	record.ResponseStatus, _ = strconv.Atoi(status.Value)
+	if v, ok := item["headers"].(*types.AttributeValueMemberS); ok {
+		if err := json.Unmarshal([]byte(v.Value), &record.Headers); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := item["body"].(*types.AttributeValueMemberB); ok {
+		record.Body = v.Value
+	}
+	return record, nil
+}
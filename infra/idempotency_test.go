@@ -0,0 +1,49 @@
+package infra
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestIdempotencyLockDuration(t *testing.T) {
+	// 処理中のリクエストの予約を期限切れとして奪わないよう、Lambdaのタイムアウトの上限以上にする
+	if maxLambdaTimeout := 15 * time.Minute; idempotencyLockDuration < maxLambdaTimeout {
+		t.Errorf("idempotencyLockDuration = %v, want at least %v", idempotencyLockDuration, maxLambdaTimeout)
+	}
+}
+
+func TestIdempotencyCompleteInput(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	record := IdempotencyRecord{Fingerprint: "f1", ResponseStatus: 201, Headers: http.Header{"Content-Type": {"application/json"}}}
+
+	input, err := idempotencyCompleteInput("k1", "r1", record, time.Hour, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 自分の予約が処理中のまま残っている場合のみ保存する
+	if got := aws.ToString(input.ConditionExpression); got != idempotencyReservedCondition {
+		t.Errorf("condition = %q, want %q", got, idempotencyReservedCondition)
+	}
+	if got := input.ExpressionAttributeValues[":reservation"].(*types.AttributeValueMemberS).Value; got != "r1" {
+		t.Errorf(":reservation = %q, want r1", got)
+	}
+	checkExpressionValues(t, []types.TransactWriteItem{{Put: &types.Put{
+		ConditionExpression:       input.ConditionExpression,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}}})
+
+	// 保存後も予約のトークンを残す
+	if got := input.Item["reservation"].(*types.AttributeValueMemberS).Value; got != "r1" {
+		t.Errorf("reservation = %q, want r1", got)
+	}
+	if body, ok := input.Item["body"].(*types.AttributeValueMemberB); !ok || body.Value == nil {
+		t.Errorf("body = %v, want an empty binary for a response without a body", input.Item["body"])
+	}
+	if got := input.Item["expiresAt"].(*types.AttributeValueMemberN).Value; got != "1711967400" {
+		t.Errorf("expiresAt = %s, want 1711967400", got)
+	}
+}
@@ -0,0 +1,447 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+// moderationActionsTableName - モデレーターの操作履歴を保存するテーブル（キーは(targetId, actionId)で、actionIdは操作日時順）
+const moderationActionsTableName = "moderationActions"
+
+// userStatusesTableName - 利用停止中のユーザーを保存するテーブル（キーはmailAddress。利用中のユーザーは項目を持たない）
+const userStatusesTableName = "userStatuses"
+
+// ModerationActionType - モデレーターの操作の種類
+type ModerationActionType string
+
+const (
+	ModerationHide    ModerationActionType = "hide"
+	ModerationUnhide  ModerationActionType = "unhide"
+	ModerationDelete  ModerationActionType = "delete"
+	ModerationApprove ModerationActionType = "approve" // 承認待ちの意見の公開
+	ModerationReject  ModerationActionType = "reject"  // 承認待ちの意見の却下
+	ModerationStatus  ModerationActionType = "status"  // ユーザーの利用状態の変更
+)
+
+// ReportTargetUser - 操作履歴の対象がユーザーの場合の種類
+const ReportTargetUser ReportTargetType = "user"
+
+// ModerationAction - モデレーターの操作履歴
+type ModerationAction struct {
+	TargetType    ReportTargetType
+	OpinionID     string `json:"OpinionId,omitempty"`
+	CommentID     string `json:"CommentId,omitempty"`
+	MailAddress   string `json:",omitempty"` // ユーザーの利用状態を変更した場合の対象のユーザー
+	Action        ModerationActionType
+	Status        UserStatusType `json:",omitempty"` // ユーザーの利用状態を変更した場合の変更後の状態
+	Actor         string         // 操作したモデレーター
+	Note          string         `json:",omitempty"`
+	Content       string         `json:",omitempty"` // 削除した場合の削除前の本文
+	ActedDateTime time.Time
+}
+
+// Report - ユーザーごとの通報
+type Report struct {
+	MailAddress     string
+	Reason          string
+	Detail          string `json:",omitempty"`
+	CreatedDateTime time.Time
+}
+
+// ModerationHistory - 意見・コメントへの通報とモデレーターの操作履歴（それぞれ日時順）
+type ModerationHistory struct {
+	Reports []Report
+	Actions []ModerationAction
+}
+
+// UserStatusType - ユーザーの利用状態
+type UserStatusType string
+
+const (
+	UserActive    UserStatusType = "active"
+	UserSuspended UserStatusType = "suspended" // 期限まで投稿・リアクション・通報ができない
+	UserBanned    UserStatusType = "banned"    // 無期限に投稿・リアクション・通報ができない
+)
+
+// UserStatus - ユーザーの利用状態
+type UserStatus struct {
+	MailAddress     string
+	Status          UserStatusType
+	Until           time.Time `json:",omitzero"` // 利用停止の期限（suspendedの場合のみ）
+	Note            string    `json:",omitempty"`
+	UpdatedBy       string    `json:",omitempty"`
+	UpdatedDateTime time.Time `json:",omitzero"`
+}
+
+// historyID - 操作履歴のキー（対象のid。ユーザーの場合はメールアドレスと区別できる接頭辞を付ける）
+func (a ModerationAction) historyID() string {
+	switch a.TargetType {
+	case ReportTargetUser:
+		return "user#" + a.MailAddress
+	case ReportTargetComment:
+		return a.CommentID
+	}
+	return a.OpinionID
+}
+
+// SaveModerationAction - モデレーターの操作履歴を保存するメソッド
+func (db *DynamoDBClient) SaveModerationAction(ctx context.Context, action ModerationAction) error {
+	item := map[string]types.AttributeValue{
+		"targetId": &types.AttributeValueMemberS{Value: action.historyID()},
+		// 同じ日時の操作でも重複しないようにUUIDを付ける
+		"actionId":      &types.AttributeValueMemberS{Value: action.ActedDateTime.UTC().Format(time.RFC3339Nano) + "#" + uuid.New().String()},
+		"targetType":    &types.AttributeValueMemberS{Value: string(action.TargetType)},
+		"action":        &types.AttributeValueMemberS{Value: string(action.Action)},
+		"actor":         &types.AttributeValueMemberS{Value: action.Actor},
+		"actedDateTime": &types.AttributeValueMemberS{Value: action.ActedDateTime.Format(time.RFC3339)},
+	}
+	optional := map[string]string{
+		"opinionId":   action.OpinionID,
+		"commentId":   action.CommentID,
+		"mailAddress": action.MailAddress,
+		"status":      string(action.Status),
+		"note":        action.Note,
+		"content":     action.Content,
+	}
+	for name, value := range optional {
+		if value != "" {
+			item[name] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(moderationActionsTableName),
+		Item:      item,
+	})
+	return err
+}
+
+// SetHidden - 意見・コメントを非表示・表示にするメソッド
+// モデレーターが判断した対象はモデレーションキューから外し、通報数による自動の非表示の対象にもしない
+func (db *DynamoDBClient) SetHidden(ctx context.Context, target ReportTarget, hidden bool) error {
+	tableName, key, existsCondition := target.table()
+	_, err := db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(tableName),
+		Key:                 key,
+		ConditionExpression: aws.String(existsCondition),
+		UpdateExpression:    aws.String("SET hidden = :hidden, hiddenDateTime = :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hidden": &types.AttributeValueMemberBOOL{Value: hidden},
+			":now":    &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return target.notFound()
+	}
+	if err != nil {
+		return err
+	}
+	return db.resolveReportedItem(ctx, target, &hidden)
+}
+
+// SetOpinionStatus - 意見の公開状態を変更し、モデレーションキューから取り除くメソッド
+// 公開する場合は非表示も解除する（自動フィルターが検出して非表示で保存した意見をモデレーターが承認した場合など）
+func (db *DynamoDBClient) SetOpinionStatus(ctx context.Context, opinionId string, status OpinionStatus) error {
+	target := OpinionReportTarget(opinionId)
+	tableName, key, existsCondition := target.table()
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(tableName),
+		Key:                 key,
+		ConditionExpression: aws.String(existsCondition),
+		UpdateExpression:    aws.String("SET publicationStatus = :status, statusDateTime = :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: string(status)},
+			":now":    &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		},
+	}
+	var hidden *bool
+	if status == OpinionPublished {
+		hidden = aws.Bool(false)
+		input.UpdateExpression = aws.String("SET publicationStatus = :status, statusDateTime = :now, hidden = :hidden")
+		input.ExpressionAttributeValues[":hidden"] = &types.AttributeValueMemberBOOL{Value: false}
+	}
+	_, err := db.Client.UpdateItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return target.notFound()
+	}
+	if err != nil {
+		return err
+	}
+	return db.resolveReportedItem(ctx, target, hidden)
+}
+
+// resolveReportedItem - モデレーションキューの項目を対応済みにする（通報されていない対象は何もしない）
+// hiddenがnilの場合（削除した場合）は非表示の状態を更新しない
+func (db *DynamoDBClient) resolveReportedItem(ctx context.Context, target ReportTarget, hidden *bool) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(moderationQueueTableName),
+		Key: map[string]types.AttributeValue{
+			"targetId": &types.AttributeValueMemberS{Value: target.id()},
+		},
+		ConditionExpression: aws.String("attribute_exists(targetId)"),
+		UpdateExpression:    aws.String("REMOVE queueKey"),
+	}
+	if hidden != nil {
+		input.UpdateExpression = aws.String("SET hidden = :hidden REMOVE queueKey")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":hidden": &types.AttributeValueMemberBOOL{Value: *hidden},
+		}
+	}
+	_, err := db.Client.UpdateItem(ctx, input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// DeleteOpinion - 意見を削除するメソッド
+// カテゴリー・区市町村の意見数を減らし、削除前の意見を返す。コメント・リアクションは意見とともに取得できなくなるため残す
+func (db *DynamoDBClient) DeleteOpinion(ctx context.Context, opinionId string) (OpinionItem, error) {
+	opinion, err := db.GetOpinion(ctx, opinionId)
+	if err != nil {
+		return OpinionItem{}, err
+	}
+
+	transactItems := []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName: aws.String(opinionsTableName),
+			Key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: opinionId},
+			},
+			ConditionExpression: aws.String(opinionExistsCondition),
+		}},
+	}
+	if opinion.Category != "" {
+		transactItems = append(transactItems, categoryCountUpdate(opinion.Category, "-1"))
+	}
+	area := Area{Code: opinion.AreaCode, Name: opinion.AreaName}
+	transactItems = append(transactItems, areaStatsUpdates(area, "opinionCount", "-1", opinion.CreatedDateTime)...)
+	_, err = db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	if isConditionFailedAt(err, 0) {
+		return OpinionItem{}, ErrOpinionNotFound
+	}
+	if err != nil {
+		return OpinionItem{}, err
+	}
+
+	if err := db.resolveReportedItem(ctx, OpinionReportTarget(opinionId), nil); err != nil {
+		return OpinionItem{}, err
+	}
+	return opinion, nil
+}
+
+// DeleteComment - コメントを削除するメソッド
+// 意見・区市町村のコメント数と返信先の返信数を減らし、削除前のコメントを返す。
+// 削除したコメントへの返信は残す（返信先が見つからないコメントとして扱われる）
+func (db *DynamoDBClient) DeleteComment(ctx context.Context, opinionId string, commentId string) (CommentItem, error) {
+	comment, err := db.GetCommentItem(ctx, opinionId, commentId)
+	if err != nil {
+		return CommentItem{}, err
+	}
+
+	transactItems := []types.TransactWriteItem{
+		{Delete: &types.Delete{
+			TableName: aws.String(commentsTableName),
+			Key: map[string]types.AttributeValue{
+				"opinionId": &types.AttributeValueMemberS{Value: opinionId},
+				"commentId": &types.AttributeValueMemberS{Value: commentId},
+			},
+			ConditionExpression: aws.String("attribute_exists(commentId)"),
+		}},
+		opinionCountUpdate(opinionId, "commentCount", "-1"),
+	}
+	if comment.ParentCommentID != "" {
+		// 返信先が削除済みの場合は減らす対象がない
+		_, err := db.GetCommentItem(ctx, opinionId, comment.ParentCommentID)
+		if err != nil && !errors.Is(err, ErrCommentNotFound) {
+			return CommentItem{}, err
+		}
+		if err == nil {
+			transactItems = append(transactItems, commentReplyCountUpdate(opinionId, comment.ParentCommentID, "-1"))
+		}
+	}
+	area, err := db.opinionArea(ctx, opinionId)
+	if err != nil {
+		return CommentItem{}, err
+	}
+	transactItems = append(transactItems, areaStatsUpdates(area, "commentCount", "-1", comment.CreatedDateTime)...)
+	_, err = db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	if isConditionFailedAt(err, 0) || isConditionFailedAt(err, 2) {
+		return CommentItem{}, ErrCommentNotFound
+	}
+	if isConditionFailedAt(err, 1) {
+		return CommentItem{}, ErrOpinionNotFound
+	}
+	if err != nil {
+		return CommentItem{}, err
+	}
+	if err := db.refreshHotScore(ctx, opinionId); err != nil {
+		log.Printf("failed to refresh hot score of %s: %v", opinionId, err)
+	}
+
+	if err := db.resolveReportedItem(ctx, CommentReportTarget(opinionId, commentId), nil); err != nil {
+		return CommentItem{}, err
+	}
+	return comment, nil
+}
+
+// GetModerationHistory - 意見・コメントへの通報とモデレーターの操作履歴を取得するメソッド
+// 削除済みの対象の履歴も取得できる
+func (db *DynamoDBClient) GetModerationHistory(ctx context.Context, target ReportTarget) (ModerationHistory, error) {
+	history := ModerationHistory{Reports: []Report{}, Actions: []ModerationAction{}}
+
+	err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(reportsTableName),
+		KeyConditionExpression: aws.String("targetId = :targetId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":targetId": &types.AttributeValueMemberS{Value: target.id()},
+		},
+	}, func(item map[string]types.AttributeValue) {
+		history.Reports = append(history.Reports, reportFromItem(item))
+	})
+	if err != nil {
+		return ModerationHistory{}, err
+	}
+	// 通報のキーはメールアドレスのため日時順に並べ替える
+	slices.SortStableFunc(history.Reports, func(a, b Report) int {
+		return a.CreatedDateTime.Compare(b.CreatedDateTime)
+	})
+
+	err = db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(moderationActionsTableName),
+		KeyConditionExpression: aws.String("targetId = :targetId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":targetId": &types.AttributeValueMemberS{Value: target.id()},
+		},
+	}, func(item map[string]types.AttributeValue) {
+		history.Actions = append(history.Actions, moderationActionFromItem(item))
+	})
+	if err != nil {
+		return ModerationHistory{}, err
+	}
+	return history, nil
+}
+
+// reportFromItem - reportsテーブルの項目をReportに変換する
+func reportFromItem(item map[string]types.AttributeValue) Report {
+	report := Report{
+		MailAddress: item["mailAddress"].(*types.AttributeValueMemberS).Value,
+		Reason:      item["reason"].(*types.AttributeValueMemberS).Value,
+	}
+	if detail, ok := item["detail"].(*types.AttributeValueMemberS); ok {
+		report.Detail = detail.Value
+	}
+	report.CreatedDateTime, _ = time.Parse(time.RFC3339, item["createdDateTime"].(*types.AttributeValueMemberS).Value)
+	return report
+}
+
+// moderationActionFromItem - moderationActionsテーブルの項目をModerationActionに変換する
+func moderationActionFromItem(item map[string]types.AttributeValue) ModerationAction {
+	action := ModerationAction{
+		TargetType: ReportTargetType(item["targetType"].(*types.AttributeValueMemberS).Value),
+		Action:     ModerationActionType(item["action"].(*types.AttributeValueMemberS).Value),
+		Actor:      item["actor"].(*types.AttributeValueMemberS).Value,
+	}
+	action.ActedDateTime, _ = time.Parse(time.RFC3339, item["actedDateTime"].(*types.AttributeValueMemberS).Value)
+	optional := map[string]*string{
+		"opinionId":   &action.OpinionID,
+		"commentId":   &action.CommentID,
+		"mailAddress": &action.MailAddress,
+		"note":        &action.Note,
+		"content":     &action.Content,
+	}
+	for name, field := range optional {
+		if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+			*field = v.Value
+		}
+	}
+	if status, ok := item["status"].(*types.AttributeValueMemberS); ok {
+		action.Status = UserStatusType(status.Value)
+	}
+	return action
+}
+
+// GetUserStatus - ユーザーの利用状態を取得するメソッド
+// 項目がない場合と、利用停止の期限を過ぎている場合は利用中として返す
+func (db *DynamoDBClient) GetUserStatus(ctx context.Context, mailAddress string) (UserStatus, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(userStatusesTableName),
+		Key: map[string]types.AttributeValue{
+			"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
+		},
+	})
+	if err != nil {
+		return UserStatus{}, err
+	}
+	active := UserStatus{MailAddress: mailAddress, Status: UserActive}
+	if result.Item == nil {
+		return active, nil
+	}
+
+	status := UserStatus{
+		MailAddress: mailAddress,
+		Status:      UserStatusType(result.Item["status"].(*types.AttributeValueMemberS).Value),
+	}
+	if until, ok := result.Item["until"].(*types.AttributeValueMemberS); ok {
+		status.Until, _ = time.Parse(time.RFC3339, until.Value)
+	}
+	if status.Status == UserSuspended && !status.Until.After(time.Now()) {
+		return active, nil
+	}
+	if note, ok := result.Item["note"].(*types.AttributeValueMemberS); ok {
+		status.Note = note.Value
+	}
+	if updatedBy, ok := result.Item["updatedBy"].(*types.AttributeValueMemberS); ok {
+		status.UpdatedBy = updatedBy.Value
+	}
+	if updated, ok := result.Item["updatedDateTime"].(*types.AttributeValueMemberS); ok {
+		status.UpdatedDateTime, _ = time.Parse(time.RFC3339, updated.Value)
+	}
+	return status, nil
+}
+
+// SetUserStatus - ユーザーの利用状態を変更するメソッド（利用中に戻す場合は項目を削除する）
+func (db *DynamoDBClient) SetUserStatus(ctx context.Context, status UserStatus) error {
+	key := map[string]types.AttributeValue{
+		"mailAddress": &types.AttributeValueMemberS{Value: status.MailAddress},
+	}
+	if status.Status == UserActive {
+		_, err := db.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(userStatusesTableName),
+			Key:       key,
+		})
+		return err
+	}
+
+	item := map[string]types.AttributeValue{
+		"mailAddress":     key["mailAddress"],
+		"status":          &types.AttributeValueMemberS{Value: string(status.Status)},
+		"updatedBy":       &types.AttributeValueMemberS{Value: status.UpdatedBy},
+		"updatedDateTime": &types.AttributeValueMemberS{Value: status.UpdatedDateTime.Format(time.RFC3339)},
+	}
+	if !status.Until.IsZero() {
+		item["until"] = &types.AttributeValueMemberS{Value: status.Until.Format(time.RFC3339)}
+	}
+	if status.Note != "" {
+		item["note"] = &types.AttributeValueMemberS{Value: status.Note}
+	}
+	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(userStatusesTableName),
+		Item:      item,
+	})
+	return err
+}
@@ -0,0 +1,302 @@
+package infra
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OpinionSort - 意見一覧の並び順
+type OpinionSort string
+
+const (
+	SortNew       OpinionSort = "new"       // 新着順
+	SortTop       OpinionSort = "top"       // リアクション数順
+	SortHot       OpinionSort = "hot"       // 時間減衰を考慮した人気順
+	SortDiscussed OpinionSort = "discussed" // コメント数順
+)
+
+// opinionListKey - 全ての意見に共通のパーティションキー（並び替え用のGSIで全件を1つのパーティションとして扱う）
+const opinionListKey = "opinions"
+
+// opinionSortIndex - 並び順ごとのGSIとソートキー
+type opinionSortIndex struct {
+	indexName string
+	attribute string
+	numeric   bool
+}
+
+var opinionSortIndexes = map[OpinionSort]opinionSortIndex{
+	SortNew:       {indexName: "listKey-createdDateTime-index", attribute: "createdDateTime"},
+	SortTop:       {indexName: "listKey-reactionCount-index", attribute: "reactionCount", numeric: true},
+	SortHot:       {indexName: "listKey-hotScore-index", attribute: "hotScore", numeric: true},
+	SortDiscussed: {indexName: "listKey-commentCount-index", attribute: "commentCount", numeric: true},
+}
+
+// ParseOpinionSort - 並び順の文字列を検証する
+func ParseOpinionSort(value string) (OpinionSort, bool) {
+	_, ok := opinionSortIndexes[OpinionSort(value)]
+	return OpinionSort(value), ok
+}
+
+// ErrInvalidCursor - ページングのカーソルが不正（改ざん・並び順の不一致など）
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OpinionPage - 並び替えた意見一覧の1ページ
+type OpinionPage struct {
+	Opinions   []OpinionItem
+	NextCursor string // 次のページがない場合は空
+}
+
+// opinionCursor - 次のページの開始位置（最後に返した意見のキー）
+type opinionCursor struct {
+	Sort  OpinionSort `json:"s"`
+	ID    string      `json:"id"`
+	Value string      `json:"v"`
+}
+
+// ListOpinions - 並び順のGSIをQueryして意見一覧の1ページを取得するメソッド
+// 絞り込み条件を指定した場合もlimit件に達するまで続けて取得する
+func (db *DynamoDBClient) ListOpinions(ctx context.Context, filter OpinionFilter, sort OpinionSort, limit int32, cursor string) (OpinionPage, error) {
+	index, ok := opinionSortIndexes[sort]
+	if !ok {
+		return OpinionPage{}, ErrInvalidCursor
+	}
+	startKey, err := decodeOpinionCursor(cursor, sort, index)
+	if err != nil {
+		return OpinionPage{}, err
+	}
+
+	page := OpinionPage{Opinions: []OpinionItem{}}
+	var last map[string]types.AttributeValue
+	for {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(opinionsTableName),
+			IndexName:              aws.String(index.indexName),
+			KeyConditionExpression: aws.String("listKey = :listKey"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":listKey": &types.AttributeValueMemberS{Value: opinionListKey},
+			},
+			ScanIndexForward:  aws.Bool(false), // 降順
+			ExclusiveStartKey: startKey,
+			Limit:             aws.Int32(limit - int32(len(page.Opinions))),
+		}
+		// 絞り込み条件の指定
+		var filterValues map[string]types.AttributeValue
+		input.FilterExpression, filterValues = filter.expression()
+		maps.Copy(input.ExpressionAttributeValues, filterValues)
+
+		result, err := db.Client.Query(ctx, input)
+		if err != nil {
+			log.Printf("DynamoDB Query failed: %v", err)
+			return OpinionPage{}, err
+		}
+		for _, item := range result.Items {
+			page.Opinions = append(page.Opinions, opinionFromItem(item))
+			last = item
+		}
+
+		// 最後まで取得した場合は次のページはない
+		if result.LastEvaluatedKey == nil {
+			return page, nil
+		}
+		if int32(len(page.Opinions)) >= limit {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	page.NextCursor, err = encodeOpinionCursor(sort, index, last)
+	if err != nil {
+		return OpinionPage{}, err
+	}
+	return page, nil
+}
+
+func encodeOpinionCursor(sort OpinionSort, index opinionSortIndex, item map[string]types.AttributeValue) (string, error) {
+	c := opinionCursor{Sort: sort, ID: item["id"].(*types.AttributeValueMemberS).Value}
+	switch v := item[index.attribute].(type) {
+	case *types.AttributeValueMemberS:
+		c.Value = v.Value
+	case *types.AttributeValueMemberN:
+		c.Value = v.Value
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeOpinionCursor - カーソルをQueryのExclusiveStartKeyに変換する（空の場合は先頭から）
+func decodeOpinionCursor(cursor string, sort OpinionSort, index opinionSortIndex) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c opinionCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	key := map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: c.ID},
+		"listKey": &types.AttributeValueMemberS{Value: opinionListKey},
+	}
+	if index.numeric {
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		key[index.attribute] = &types.AttributeValueMemberN{Value: c.Value}
+	} else {
+		key[index.attribute] = &types.AttributeValueMemberS{Value: c.Value}
+	}
+	return key, nil
+}
+
+// hotScoreHalfLife - 反応数が10倍の意見と同じスコアになる投稿日時の差（秒）
+const hotScoreHalfLife = 45000
+
+// hotScoreEpoch - スコアの基準日時（スコアの桁数を抑えるため）
+var hotScoreEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// HotScore - 時間減衰を考慮した人気度
+// 投稿日時に比例する基準値に反応数（リアクション数+コメント数）の対数を加える。
+// 新しい意見ほど基準値が大きいため、古い意見のスコアを定期的に再計算しなくても相対的に減衰する。
+func HotScore(reactionCount, commentCount int32, createdDateTime time.Time) float64 {
+	engagement := math.Max(float64(reactionCount+commentCount), 1)
+	return math.Log10(engagement) + createdDateTime.Sub(hotScoreEpoch).Seconds()/hotScoreHalfLife
+}
+
+func hotScoreAttribute(score float64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatFloat(score, 'f', 7, 64)}
+}
+
+// refreshHotScore - 意見のカウントからhotScoreを再計算するメソッド
+// カウントを読んだ後に他のリクエストが更新した場合は、そのリクエストの再計算に任せる
+func (db *DynamoDBClient) refreshHotScore(ctx context.Context, opinionId string) error {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		ConsistentRead:       aws.Bool(true),
+		ProjectionExpression: aws.String("id, createdDateTime, reactionCount, commentCount"),
+	})
+	if err != nil {
+		return err
+	}
+	if result.Item == nil {
+		return nil
+	}
+	opinion := result.Item
+
+	var createdDateTime time.Time
+	if v, ok := opinion["createdDateTime"].(*types.AttributeValueMemberS); ok {
+		createdDateTime, _ = time.Parse(time.RFC3339, v.Value)
+	}
+	score := HotScore(numberAttribute(opinion, "reactionCount"), numberAttribute(opinion, "commentCount"), createdDateTime)
+
+	// 読んだ時点からカウントが変わっていない場合のみ更新する
+	condition := "id = :id"
+	values := map[string]types.AttributeValue{
+		":id":    &types.AttributeValueMemberS{Value: opinionId},
+		":score": hotScoreAttribute(score),
+	}
+	for _, name := range []string{"reactionCount", "commentCount"} {
+		if v, ok := opinion[name]; ok {
+			condition += " AND " + name + " = :" + name
+			values[":"+name] = v
+		} else {
+			condition += " AND attribute_not_exists(" + name + ")"
+		}
+	}
+	_, err = db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		ConditionExpression:       aws.String(condition),
+		UpdateExpression:          aws.String("SET hotScore = :score"),
+		ExpressionAttributeValues: values,
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// 重複投稿の検出に使うGSI（いずれもソートキーはcreatedDateTimeで、recentOpinionProjectionの属性を射影する）
+const (
+	// opinionsByUserIndexName - 投稿者ごとに意見を投稿日時順に取得するGSI
+	opinionsByUserIndexName = "mailAddress-createdDateTime-index"
+	// opinionsByGeohashIndexName - 投稿位置のジオハッシュのセルごとに意見を投稿日時順に取得するGSI
+	// geohash属性を持たない（導入前に投稿された）意見は含まれない
+	opinionsByGeohashIndexName = "geohash-createdDateTime-index"
+)
+
+// recentOpinionProjection - 重複投稿の検出に必要な属性
+const recentOpinionProjection = "id, mailAddress, opinion, latitude, longitude, createdDateTime, publicationStatus, hidden"
+
+// ListRecentOpinionsByUser - ユーザーがsinceより後に投稿した意見を新しい順に取得するメソッド（重複投稿の検出用）
+// 非表示・未公開の意見も含める。本文・位置・公開状態など重複の判定に必要な属性のみ返す
+func (db *DynamoDBClient) ListRecentOpinionsByUser(ctx context.Context, mailAddress string, since time.Time) ([]OpinionItem, error) {
+	return db.listRecentOpinions(ctx, opinionsByUserIndexName, "mailAddress", mailAddress, since)
+}
+
+// ListRecentOpinionsNear - 指定したジオハッシュのセルでsinceより後に投稿された意見を新しい順に取得するメソッド（類似した意見の検出用）
+// 非表示・未公開の意見も含める。本文・位置・公開状態など重複の判定に必要な属性のみ返す
+func (db *DynamoDBClient) ListRecentOpinionsNear(ctx context.Context, geohashes []string, since time.Time) ([]OpinionItem, error) {
+	opinions := []OpinionItem{}
+	for _, geohash := range geohashes {
+		cell, err := db.listRecentOpinions(ctx, opinionsByGeohashIndexName, "geohash", geohash, since)
+		if err != nil {
+			return nil, err
+		}
+		opinions = append(opinions, cell...)
+	}
+	sort.SliceStable(opinions, func(i, j int) bool {
+		return opinions[i].CreatedDateTime.After(opinions[j].CreatedDateTime)
+	})
+	return opinions, nil
+}
+
+// listRecentOpinions - GSIのパーティションからsinceより後に投稿された意見を新しい順に取得する
+func (db *DynamoDBClient) listRecentOpinions(ctx context.Context, indexName string, keyAttribute string, key string, since time.Time) ([]OpinionItem, error) {
+	opinions := []OpinionItem{}
+	err := db.queryAll(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(opinionsTableName),
+		IndexName:              aws.String(indexName),
+		KeyConditionExpression: aws.String("#key = :key AND createdDateTime > :since"),
+		ProjectionExpression:   aws.String(recentOpinionProjection),
+		ExpressionAttributeNames: map[string]string{
+			"#key": keyAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":key":   &types.AttributeValueMemberS{Value: key},
+			":since": &types.AttributeValueMemberS{Value: since.Format(time.RFC3339)},
+		},
+		ScanIndexForward: aws.Bool(false), // 降順
+	}, func(item map[string]types.AttributeValue) {
+		opinions = append(opinions, opinionFromItem(item))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return opinions, nil
+}
@@ -0,0 +1,159 @@
+package infra
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestOpinionCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		sort OpinionSort
+		item map[string]types.AttributeValue
+	}{
+		{SortNew, map[string]types.AttributeValue{
+			"id":              &types.AttributeValueMemberS{Value: "00000000-0000-0000-0000-000000000001"},
+			"listKey":         &types.AttributeValueMemberS{Value: opinionListKey},
+			"createdDateTime": &types.AttributeValueMemberS{Value: "2024-04-01T09:30:00+09:00"},
+		}},
+		{SortTop, map[string]types.AttributeValue{
+			"id":            &types.AttributeValueMemberS{Value: "00000000-0000-0000-0000-000000000002"},
+			"listKey":       &types.AttributeValueMemberS{Value: opinionListKey},
+			"reactionCount": &types.AttributeValueMemberN{Value: "42"},
+		}},
+		{SortHot, map[string]types.AttributeValue{
+			"id":       &types.AttributeValueMemberS{Value: "00000000-0000-0000-0000-000000000003"},
+			"listKey":  &types.AttributeValueMemberS{Value: opinionListKey},
+			"hotScore": &types.AttributeValueMemberN{Value: "-123.4567890"},
+		}},
+		{SortDiscussed, map[string]types.AttributeValue{
+			"id":           &types.AttributeValueMemberS{Value: "00000000-0000-0000-0000-000000000004"},
+			"listKey":      &types.AttributeValueMemberS{Value: opinionListKey},
+			"commentCount": &types.AttributeValueMemberN{Value: "0"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			index := opinionSortIndexes[tt.sort]
+			cursor, err := encodeOpinionCursor(tt.sort, index, tt.item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := decodeOpinionCursor(cursor, tt.sort, index)
+			if err != nil {
+				t.Fatalf("decodeOpinionCursor() error = %v", err)
+			}
+			if len(key) != len(tt.item) {
+				t.Fatalf("key = %v, want %v", key, tt.item)
+			}
+			for name, want := range tt.item {
+				if !attributeEqual(key[name], want) {
+					t.Errorf("key[%s] = %#v, want %#v", name, key[name], want)
+				}
+			}
+		})
+	}
+}
+
+func attributeEqual(a, b types.AttributeValue) bool {
+	switch a := a.(type) {
+	case *types.AttributeValueMemberS:
+		b, ok := b.(*types.AttributeValueMemberS)
+		return ok && a.Value == b.Value
+	case *types.AttributeValueMemberN:
+		b, ok := b.(*types.AttributeValueMemberN)
+		return ok && a.Value == b.Value
+	}
+	return false
+}
+
+func TestDecodeOpinionCursorRejectsInvalidCursors(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	topCursor, err := encodeOpinionCursor(SortTop, opinionSortIndexes[SortTop], map[string]types.AttributeValue{
+		"id":            &types.AttributeValueMemberS{Value: "1"},
+		"reactionCount": &types.AttributeValueMemberN{Value: "3"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   OpinionSort
+	}{
+		{"not base64", "!!!", SortNew},
+		{"not json", encode("cursor"), SortNew},
+		{"cursor of another sort", topCursor, SortNew},
+		{"missing id", encode(`{"sort":"new","value":"2024-04-01T00:00:00Z"}`), SortNew},
+		{"non numeric value for a numeric sort", encode(`{"sort":"top","id":"1","value":"many"}`), SortTop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeOpinionCursor(tt.cursor, tt.sort, opinionSortIndexes[tt.sort]); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeOpinionCursor() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+
+	key, err := decodeOpinionCursor("", SortNew, opinionSortIndexes[SortNew])
+	if err != nil || key != nil {
+		t.Errorf("empty cursor = %v, %v, want the first page", key, err)
+	}
+}
+
+func TestHotScore(t *testing.T) {
+	created := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := created.Add(hotScoreHalfLife * time.Second)
+
+	tests := []struct {
+		name    string
+		higher  float64
+		lower   float64
+		epsilon float64 // 0の場合は厳密に大きいこと
+	}{
+		{"more engagement ranks higher", HotScore(10, 0, created), HotScore(1, 0, created), 0},
+		{"comments count as engagement", HotScore(0, 5, created), HotScore(0, 0, created), 0},
+		{"newer ranks higher with the same engagement", HotScore(3, 3, later), HotScore(3, 3, created), 0},
+		{"10x engagement equals one half life", HotScore(100, 0, created), HotScore(10, 0, later), 1e-9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.epsilon > 0 {
+				if math.Abs(tt.higher-tt.lower) > tt.epsilon {
+					t.Errorf("scores differ: %v and %v", tt.higher, tt.lower)
+				}
+				return
+			}
+			if !(tt.higher > tt.lower) {
+				t.Errorf("%v is not higher than %v", tt.higher, tt.lower)
+			}
+		})
+	}
+
+	// 反応がない意見と1件の意見は同じ（対数の下限）
+	if HotScore(0, 0, created) != HotScore(1, 0, created) {
+		t.Error("zero engagement is not clamped to one")
+	}
+	if got := HotScore(0, 0, hotScoreEpoch); got != 0 {
+		t.Errorf("HotScore at the epoch = %v, want 0", got)
+	}
+}
+
+func TestRecentOpinionProjection(t *testing.T) {
+	// opinionFromItemが必須とする属性と、重複の判定に使う属性が射影されていること
+	projected := map[string]bool{}
+	for _, name := range strings.Split(recentOpinionProjection, ",") {
+		projected[strings.TrimSpace(name)] = true
+	}
+	for _, name := range []string{"id", "mailAddress", "latitude", "longitude", "opinion", "createdDateTime", "publicationStatus", "hidden"} {
+		if !projected[name] {
+			t.Errorf("%s is not projected", name)
+		}
+	}
+}
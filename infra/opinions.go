@@ -2,9 +2,11 @@ package infra
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -19,10 +21,87 @@ type Coordinate struct {
 }
 
 type OpinionItem struct {
-	ID          string
-	MailAddress string
-	Coordinate  Coordinate `json:"Coordinate"`
-	Opinion     string
+	ID              string
+	MailAddress     string
+	Coordinate      Coordinate `json:"Coordinate"`
+	Opinion         string
+	CreatedDateTime time.Time
+	AreaCode        string // 区市町村コード（エリア外の場合は空）
+	AreaName        string
+	ReactionCount   int32               // リアクション数（SaveReactionで増減する）
+	ReactionCounts  map[string]int32    // 種類ごとのリアクション数（デフォルトの種類を含む）
+	CommentCount    int32               // コメント数（SaveCommentで増やす）
+	Category        string              // カテゴリー（未指定の場合は空）
+	Tags            []string            // 本文から抽出したハッシュタグ（#を除き正規化したもの）
+	Attachments     []OpinionAttachment // 紐づけ済みの添付ファイル（CompleteAttachmentで追加する）
+	Status          OpinionStatus       // 公開状態（事前モデレーションの場合はモデレーターが承認するまでpending）
+	Hidden          bool                `json:"-"` // 通報・モデレーターの判断で非表示になっているかどうか
+}
+
+// OpinionStatus - 意見の公開状態
+type OpinionStatus string
+
+const (
+	OpinionPending   OpinionStatus = "pending"   // モデレーターの承認待ち（投稿者のみ閲覧できる）
+	OpinionPublished OpinionStatus = "published" // 公開中
+	OpinionRejected  OpinionStatus = "rejected"  // モデレーターが却下した（投稿者のみ閲覧できる）
+)
+
+// IsPublic - 投稿者以外にも表示する意見かどうか
+func (o OpinionItem) IsPublic() bool {
+	return o.Status == OpinionPublished && !o.Hidden
+}
+
+// Area - 意見の投稿位置が属する区市町村
+type Area struct {
+	Code string
+	Name string
+}
+
+// OpinionFilter - 意見一覧の絞り込み条件（ゼロ値の項目は絞り込まない）
+type OpinionFilter struct {
+	AreaCode string
+	Category string
+	Tag      string // 正規化したハッシュタグ（#を除く）
+	// 非表示・未公開の意見も含めるかどうか（モデレーション・メンテナンス用。通常の一覧・エクスポートでは含めない）
+	IncludeHidden bool
+	// 指定した場合はこのユーザーが投稿した未公開（承認待ち・却下）の意見も含める
+	ViewerMailAddress string
+}
+
+// expression - 絞り込み条件をFilterExpressionに変換する（条件がない場合はnil）
+func (f OpinionFilter) expression() (*string, map[string]types.AttributeValue) {
+	var conditions []string
+	values := make(map[string]types.AttributeValue)
+	if f.AreaCode != "" {
+		conditions = append(conditions, "areaCode = :areaCode")
+		values[":areaCode"] = &types.AttributeValueMemberS{Value: f.AreaCode}
+	}
+	if f.Category != "" {
+		conditions = append(conditions, "category = :category")
+		values[":category"] = &types.AttributeValueMemberS{Value: f.Category}
+	}
+	if f.Tag != "" {
+		conditions = append(conditions, "contains(tags, :tag)")
+		values[":tag"] = &types.AttributeValueMemberS{Value: f.Tag}
+	}
+	if !f.IncludeHidden {
+		// publicationStatusを持たない古い意見は公開中として扱う
+		visible := "(" + hiddenFilterExpression + " AND (attribute_not_exists(publicationStatus) OR publicationStatus = :published))"
+		values[":notHidden"] = hiddenFilterValue
+		values[":published"] = &types.AttributeValueMemberS{Value: string(OpinionPublished)}
+		if f.ViewerMailAddress != "" {
+			visible = "(" + visible + " OR (mailAddress = :viewer AND publicationStatus IN (:pending, :rejected)))"
+			values[":viewer"] = &types.AttributeValueMemberS{Value: f.ViewerMailAddress}
+			values[":pending"] = &types.AttributeValueMemberS{Value: string(OpinionPending)}
+			values[":rejected"] = &types.AttributeValueMemberS{Value: string(OpinionRejected)}
+		}
+		conditions = append(conditions, visible)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+	return aws.String(strings.Join(conditions, " AND ")), values
 }
 
 type CommentItem struct {
@@ -31,35 +110,193 @@ type CommentItem struct {
 	MailAddress     string
 	Comment         string
 	CreatedDateTime time.Time
+	ParentCommentID string        `json:",omitempty"` // 返信先のコメントID（意見へのコメントの場合は空）
+	Depth           int32         // 返信の深さ（意見へのコメントは0）
+	ReplyCount      int32         // 返信数（SaveCommentで増やす）
+	ReactionCount   int32         // リアクション数（SaveCommentReactionで増減する）
+	IsReactioned    bool          // 自分がリアクション済かどうか（メールアドレスを指定して取得した場合のみ設定する）
+	Replies         []CommentItem `json:",omitempty"` // ツリー表示の場合の返信（app層で設定する）
+	Hidden          bool          `json:"-"`          // 通報・モデレーターの判断で非表示になっているかどうか
 }
 
 type Reaction struct {
-	IsReactioned bool `json:"IsReactioned"`
+	Type         string `json:"Type"`
+	IsReactioned bool   `json:"IsReactioned"`
+}
+
+// ReactionItem - reactionsテーブルの1行（ユーザーごとのリアクション状態）
+type ReactionItem struct {
+	OpinionID       string
+	MailAddress     string
+	IsReactioned    bool      // デフォルトの種類のリアクション状態
+	Types           []string  // デフォルト以外でリアクション済の種類
+	ReactedDateTime time.Time // 最終更新日時（古いリアクションには存在しない）
 }
 
 type ReactionInfo struct {
-	IsReactioned  bool  `json:"IsReactioned"`
-	ReactionCount int32 `json:"ReactionCount"`
+	OpinionID     string           `json:"OpinionId"`
+	IsReactioned  bool             `json:"IsReactioned"`
+	ReactionCount int32            `json:"ReactionCount"`
+	Counts        map[string]int32 `json:"Counts"`    // 種類ごとのリアクション数
+	Reactions     []string         `json:"Reactions"` // 自分がリアクション済の種類
+}
+
+// OpinionCounts - 意見ごとのコメント数・リアクション数
+type OpinionCounts struct {
+	CommentCount  int32
+	ReactionCount int32
+	TypeCounts    map[string]int32 // デフォルト以外の種類ごとのリアクション数
+}
+
+// Counts - 意見に保持しているコメント数・リアクション数（SaveComment・SaveReactionで更新される）
+func (o OpinionItem) Counts() OpinionCounts {
+	counts := OpinionCounts{CommentCount: o.CommentCount, ReactionCount: o.ReactionCount}
+	for reactionType, count := range o.ReactionCounts {
+		if reactionType == DefaultReactionType {
+			continue
+		}
+		if counts.TypeCounts == nil {
+			counts.TypeCounts = make(map[string]int32)
+		}
+		counts.TypeCounts[reactionType] = count
+	}
+	return counts
+}
+
+// DefaultReactionType - デフォルトのリアクションの種類
+// 従来の真偽値のリアクションはこの種類として扱い、isReactioned/reactionCount属性に保存する。
+// それ以外の種類はreactionTypes(文字列セット)とreactionCount_<種類>属性に保存する。
+const DefaultReactionType = "like"
+
+// reactionCountAttribute - 種類ごとのリアクション数を保持する意見の属性名
+func reactionCountAttribute(reactionType string) string {
+	if reactionType == DefaultReactionType {
+		return "reactionCount"
+	}
+	return reactionCountPrefix + reactionType
 }
 
+const reactionCountPrefix = "reactionCount_"
+
 const opinionsTableName = "opinions"
 const commentsTableName = "comments"
 const reactionsTableName = "reactions"
 
-func (db *DynamoDBClient) SaveOpinion(ctx context.Context, mailAddress string, latitude, longitude float64, opinion string) (string, error) {
+// ErrOpinionNotFound - 指定された意見が存在しない
+var ErrOpinionNotFound = errors.New("opinion not found")
+
+// opinionExistsCondition - 意見が存在することを確認する条件式
+const opinionExistsCondition = "attribute_exists(id)"
+
+// isConditionFailedAt - トランザクションのindex番目の条件チェックが失敗したかどうか
+func isConditionFailedAt(err error, index int) bool {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) || len(canceled.CancellationReasons) <= index {
+		return false
+	}
+	return aws.ToString(canceled.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
+
+// opinionCountUpdate - トランザクション内で意見が存在する場合のみカウントを増減する
+func opinionCountUpdate(opinionId string, attribute string, delta string) types.TransactWriteItem {
+	return types.TransactWriteItem{Update: &types.Update{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		ConditionExpression: aws.String(opinionExistsCondition),
+		UpdateExpression:    aws.String("ADD #count :delta"),
+		ExpressionAttributeNames: map[string]string{
+			"#count": attribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: delta},
+		},
+	}}
+}
+
+// OpinionExists - 意見が存在するかを確認するメソッド
+func (db *DynamoDBClient) OpinionExists(ctx context.Context, opinionId string) (bool, error) {
+	result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		ProjectionExpression: aws.String("id"),
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.Item != nil, nil
+}
+
+// SaveOpinion - 意見をDynamoDBに保存するメソッド
+// moderationFlagsを指定した場合は非表示で保存し、モデレーションキューに入れる。
+// statusがpendingの場合もモデレーターの承認を待つためにモデレーションキューに入れる
+func (db *DynamoDBClient) SaveOpinion(ctx context.Context, mailAddress string, latitude, longitude float64, geohash string, opinion string, area Area, category string, tags []string, status OpinionStatus, moderationFlags []string) (string, error) {
 	id := uuid.New().String()
+	now := time.Now()
 
 	item := map[string]types.AttributeValue{
-		"id":          &types.AttributeValueMemberS{Value: id},
-		"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
-		"latitude":    &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", latitude)},
-		"longitude":   &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", longitude)},
-		"opinion":     &types.AttributeValueMemberS{Value: opinion},
+		"id":              &types.AttributeValueMemberS{Value: id},
+		"mailAddress":     &types.AttributeValueMemberS{Value: mailAddress},
+		"latitude":        &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", latitude)},
+		"longitude":       &types.AttributeValueMemberN{Value: fmt.Sprintf("%f", longitude)},
+		"opinion":         &types.AttributeValueMemberS{Value: opinion},
+		"createdDateTime": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		// 近くで投稿された意見を取得するGSIのキー
+		"geohash": &types.AttributeValueMemberS{Value: geohash},
+		// 並び替え用のGSIのキー（カウントは0から始める）
+		"listKey":       &types.AttributeValueMemberS{Value: opinionListKey},
+		"reactionCount": &types.AttributeValueMemberN{Value: "0"},
+		"commentCount":  &types.AttributeValueMemberN{Value: "0"},
+		"hotScore":      hotScoreAttribute(HotScore(0, 0, now)),
+		// statusは予約語のため別の属性名にする
+		"publicationStatus": &types.AttributeValueMemberS{Value: string(status)},
+	}
+	// エリア外の意見には区市町村の属性を持たせない
+	if area.Code != "" {
+		item["areaCode"] = &types.AttributeValueMemberS{Value: area.Code}
+		item["areaName"] = &types.AttributeValueMemberS{Value: area.Name}
+	}
+	// 空の文字列セットは保存できないため、ハッシュタグがない場合は属性を持たせない
+	if len(tags) > 0 {
+		item["tags"] = &types.AttributeValueMemberSS{Value: tags}
 	}
 
-	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(opinionsTableName),
-		Item:      item,
+	transactItems := []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName: aws.String(opinionsTableName),
+			Item:      item,
+		}},
+	}
+	// カテゴリーを指定した場合は、カテゴリーごとの意見数を同じトランザクションで増やす
+	if category != "" {
+		item["category"] = &types.AttributeValueMemberS{Value: category}
+		transactItems = append(transactItems, categoryCountUpdate(category, "1"))
+	}
+	// 区市町村ごとの意見数も同じトランザクションで増やす（エリア外の意見は数えない）
+	transactItems = append(transactItems, areaStatsUpdates(area, "opinionCount", "1", now)...)
+	// 自動フィルターが検出した意見は非表示で保存し、承認待ちの意見とともに同じトランザクションでモデレーションキューに入れる
+	if len(moderationFlags) > 0 {
+		holdItem(item, now.Format(time.RFC3339))
+	}
+	if len(moderationFlags) > 0 || status == OpinionPending {
+		transactItems = append(transactItems, moderationHoldUpdate(OpinionReportTarget(id), moderationFlags, now.Format(time.RFC3339)))
+	}
+
+	if len(transactItems) == 1 {
+		_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(opinionsTableName),
+			Item:      item,
+		})
+		if err != nil {
+			return "", err
+		}
+		return id, nil
+	}
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
 	})
 	if err != nil {
 		return "", err
@@ -69,8 +306,23 @@ func (db *DynamoDBClient) SaveOpinion(ctx context.Context, mailAddress string, l
 }
 
 // GetOpinions - ユーザーの意見を取得するメソッド
-func (db *DynamoDBClient) GetOpinions(ctx context.Context) ([]OpinionItem, error) {
+func (db *DynamoDBClient) GetOpinions(ctx context.Context, filter OpinionFilter) ([]OpinionItem, error) {
 	var allOpinions []OpinionItem
+
+	err := db.ScanOpinions(ctx, filter, func(opinion OpinionItem) error {
+		allOpinions = append(allOpinions, opinion)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allOpinions, nil
+}
+
+// ScanOpinions - 意見をページ単位で取得し、1件ずつfnに渡すメソッド
+// 全件をメモリに載せずに処理したい場合（エクスポートなど）に利用する
+func (db *DynamoDBClient) ScanOpinions(ctx context.Context, filter OpinionFilter, fn func(OpinionItem) error) error {
 	var lastEvaluatedKey map[string]types.AttributeValue
 
 	for {
@@ -78,36 +330,22 @@ func (db *DynamoDBClient) GetOpinions(ctx context.Context) ([]OpinionItem, error
 			TableName:         aws.String(opinionsTableName),
 			ExclusiveStartKey: lastEvaluatedKey,
 		}
+		// 絞り込み条件の指定
+		input.FilterExpression, input.ExpressionAttributeValues = filter.expression()
 
 		result, err := db.Client.Scan(ctx, input)
 		if err != nil {
 			log.Printf("DynamoDB Scan failed: %v", err)
-			return nil, err
+			return err
 		}
 
 		// DynamoDBから返された各項目をOpinion構造体にデコード
-		var opinionsPage []OpinionItem
 		for _, item := range result.Items {
-			var opinion OpinionItem
-			opinion.ID = item["id"].(*types.AttributeValueMemberS).Value
-			opinion.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
-			opinion.Coordinate.Latitude, _ = strconv.ParseFloat(item["latitude"].(*types.AttributeValueMemberN).Value, 64)
-			opinion.Coordinate.Longitude, _ = strconv.ParseFloat(item["longitude"].(*types.AttributeValueMemberN).Value, 64)
-			opinion.Opinion = item["opinion"].(*types.AttributeValueMemberS).Value
-			if err != nil {
-				log.Printf("Failed to unmarshal DynamoDB item: %v", err)
-				return nil, err
+			if err := fn(opinionFromItem(item)); err != nil {
+				return err
 			}
-			opinionsPage = append(opinionsPage, opinion)
-		}
-		if err != nil {
-			log.Printf("Failed to unmarshal DynamoDB items: %v", err)
-			return nil, err
 		}
 
-		// 取得した項目をすべてOpinionsリストに追加
-		allOpinions = append(allOpinions, opinionsPage...)
-
 		// LastEvaluatedKeyがnilでない場合、再度取得
 		if result.LastEvaluatedKey == nil {
 			break
@@ -115,35 +353,147 @@ func (db *DynamoDBClient) GetOpinions(ctx context.Context) ([]OpinionItem, error
 		lastEvaluatedKey = result.LastEvaluatedKey
 	}
 
-	return allOpinions, nil
+	return nil
+}
+
+// opinionFromItem - DynamoDBの項目をOpinionItemに変換する
+func opinionFromItem(item map[string]types.AttributeValue) OpinionItem {
+	var opinion OpinionItem
+	opinion.ID = item["id"].(*types.AttributeValueMemberS).Value
+	opinion.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
+	opinion.Coordinate.Latitude, _ = strconv.ParseFloat(item["latitude"].(*types.AttributeValueMemberN).Value, 64)
+	opinion.Coordinate.Longitude, _ = strconv.ParseFloat(item["longitude"].(*types.AttributeValueMemberN).Value, 64)
+	opinion.Opinion = item["opinion"].(*types.AttributeValueMemberS).Value
+	// createdDateTimeは後から追加した属性のため、古い意見には存在しない
+	if createdDateTime, ok := item["createdDateTime"].(*types.AttributeValueMemberS); ok {
+		opinion.CreatedDateTime, _ = time.Parse(time.RFC3339, createdDateTime.Value)
+	}
+	if areaCode, ok := item["areaCode"].(*types.AttributeValueMemberS); ok {
+		opinion.AreaCode = areaCode.Value
+		opinion.AreaName = item["areaName"].(*types.AttributeValueMemberS).Value
+	}
+	opinion.ReactionCount = numberAttribute(item, "reactionCount")
+	opinion.ReactionCounts = reactionCountsFromItem(item)
+	opinion.CommentCount = numberAttribute(item, "commentCount")
+	if category, ok := item["category"].(*types.AttributeValueMemberS); ok {
+		opinion.Category = category.Value
+	}
+	opinion.Tags = []string{}
+	if tags, ok := item["tags"].(*types.AttributeValueMemberSS); ok {
+		opinion.Tags = tags.Value
+	}
+	opinion.Attachments = attachmentsFromItem(item)
+	opinion.Status = OpinionPublished
+	if status, ok := item["publicationStatus"].(*types.AttributeValueMemberS); ok {
+		opinion.Status = OpinionStatus(status.Value)
+	}
+	if hidden, ok := item["hidden"].(*types.AttributeValueMemberBOOL); ok {
+		opinion.Hidden = hidden.Value
+	}
+	return opinion
+}
+
+// UpdateOpinionArea - 意見の区市町村を更新するメソッド（既存データへの付与用）
+func (db *DynamoDBClient) UpdateOpinionArea(ctx context.Context, opinionId string, area Area) error {
+	_, err := db.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+		UpdateExpression: aws.String("SET areaCode = :areaCode, areaName = :areaName"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":areaCode": &types.AttributeValueMemberS{Value: area.Code},
+			":areaName": &types.AttributeValueMemberS{Value: area.Name},
+		},
+	})
+	return err
 }
 
 // SaveComment - コメントをDynamoDBに保存するメソッド
-func (db *DynamoDBClient) SaveComment(ctx context.Context, opinionId string, mailAddress string, comment string) (string, error) {
+// parentCommentIdを指定した場合はそのコメントへの返信として保存し、返信先の返信数を増やす。
+// moderationFlagsを指定した場合は非表示で保存し、モデレーションキューに入れる
+func (db *DynamoDBClient) SaveComment(ctx context.Context, opinionId string, mailAddress string, comment string, parentCommentId string, moderationFlags []string) (string, error) {
 	commentId := uuid.New().String()
+	now := time.Now()
 
 	item := map[string]types.AttributeValue{
 		"opinionId":       &types.AttributeValueMemberS{Value: opinionId},
 		"commentId":       &types.AttributeValueMemberS{Value: commentId},
 		"mailAddress":     &types.AttributeValueMemberS{Value: mailAddress},
 		"comment":         &types.AttributeValueMemberS{Value: comment},
-		"createdDateTime": &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+		"createdDateTime": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		"replyCount":      &types.AttributeValueMemberN{Value: "0"},
+		"reactionCount":   &types.AttributeValueMemberN{Value: "0"},
 	}
 
-	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(commentsTableName),
-		Item:      item,
+	// 意見が存在する場合のみコメントを保存し、意見のコメント数と急上昇の集計を増やす
+	transactItems := []types.TransactWriteItem{
+		opinionCountUpdate(opinionId, "commentCount", "1"),
+		{Put: &types.Put{
+			TableName: aws.String(commentsTableName),
+			Item:      item,
+		}},
+	}
+	if parentCommentId != "" {
+		parent, err := db.GetCommentItem(ctx, opinionId, parentCommentId)
+		if err != nil {
+			return "", err
+		}
+		// 非表示のコメントには返信できない
+		if parent.Hidden {
+			return "", ErrCommentNotFound
+		}
+		depth := parent.Depth + 1
+		if depth > MaxCommentDepth {
+			return "", ErrCommentDepthExceeded
+		}
+		item["parentCommentId"] = &types.AttributeValueMemberS{Value: parentCommentId}
+		item["depth"] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(depth))}
+		transactItems = append(transactItems, commentReplyCountUpdate(opinionId, parentCommentId, "1"))
+	}
+	// 自動フィルターが検出したコメントは非表示で保存し、同じトランザクションでモデレーションキューに入れる
+	if len(moderationFlags) > 0 {
+		holdItem(item, now.Format(time.RFC3339))
+		transactItems = append(transactItems, moderationHoldUpdate(CommentReportTarget(opinionId, commentId), moderationFlags, now.Format(time.RFC3339)))
+	}
+	// 意見の区市町村のコメント数も同じトランザクションで増やす
+	area, err := db.opinionArea(ctx, opinionId)
+	if err != nil {
+		return "", err
+	}
+	transactItems = append(transactItems, areaStatsUpdates(area, "commentCount", "1", now)...)
+	_, err = db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: append(transactItems, opinionActivityUpdates(opinionId, "comments", "1", now)...),
 	})
+	if isConditionFailedAt(err, 0) {
+		return "", ErrOpinionNotFound
+	}
+	if isConditionFailedAt(err, 2) {
+		return "", ErrCommentNotFound
+	}
 	if err != nil {
 		return "", err
 	}
+	if err := db.refreshHotScore(ctx, opinionId); err != nil {
+		log.Printf("failed to refresh hot score of %s: %v", opinionId, err)
+	}
 
 	return commentId, nil
 }
 
 // GetComment - OpinionIDに紐づくコメントをDynamoDBから取得するメソッド
+// 非表示のコメントは含めない
 func (db *DynamoDBClient) GetComment(ctx context.Context, opinionId string) ([]CommentItem, error) {
-	var comments []CommentItem
+	exists, err := db.OpinionExists(ctx, opinionId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrOpinionNotFound
+	}
+
+	// コメントがない場合もnullではなく空配列を返す
+	comments := []CommentItem{}
 	var lastEvaluatedKey map[string]types.AttributeValue
 
 	for {
@@ -151,8 +501,10 @@ func (db *DynamoDBClient) GetComment(ctx context.Context, opinionId string) ([]C
 			TableName:              aws.String(commentsTableName),
 			IndexName:              aws.String("opinionId-createdDateTime-index"), // GSI名を指定
 			KeyConditionExpression: aws.String("opinionId = :opinionId"),
+			FilterExpression:       aws.String(hiddenFilterExpression),
 			ExpressionAttributeValues: map[string]types.AttributeValue{
 				":opinionId": &types.AttributeValueMemberS{Value: opinionId},
+				":notHidden": hiddenFilterValue,
 			},
 			ExclusiveStartKey: lastEvaluatedKey,
 		}
@@ -165,14 +517,7 @@ func (db *DynamoDBClient) GetComment(ctx context.Context, opinionId string) ([]C
 
 		// DynamoDBから返された各項目をComment構造体にデコード
 		for _, item := range result.Items {
-			var comment CommentItem
-			comment.ID = item["opinionId"].(*types.AttributeValueMemberS).Value
-			comment.CommentID = item["commentId"].(*types.AttributeValueMemberS).Value
-			comment.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
-			comment.Comment = item["comment"].(*types.AttributeValueMemberS).Value
-			comment.CreatedDateTime, _ = time.Parse(time.RFC3339, item["createdDateTime"].(*types.AttributeValueMemberS).Value)
-
-			comments = append(comments, comment)
+			comments = append(comments, commentFromItem(item))
 		}
 
 		// LastEvaluatedKeyがnilでない場合、再度取得
@@ -185,27 +530,183 @@ func (db *DynamoDBClient) GetComment(ctx context.Context, opinionId string) ([]C
 	return comments, nil
 }
 
+// ScanComments - 全コメントをページ単位で取得し、1件ずつfnに渡すメソッド
+func (db *DynamoDBClient) ScanComments(ctx context.Context, fn func(CommentItem) error) error {
+	var fnErr error
+	err := db.scanAll(ctx, commentsTableName, "", func(item map[string]types.AttributeValue) {
+		if fnErr != nil {
+			return
+		}
+		fnErr = fn(commentFromItem(item))
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// commentFromItem - DynamoDBの項目をCommentItemに変換する
+func commentFromItem(item map[string]types.AttributeValue) CommentItem {
+	var comment CommentItem
+	comment.ID = item["opinionId"].(*types.AttributeValueMemberS).Value
+	comment.CommentID = item["commentId"].(*types.AttributeValueMemberS).Value
+	comment.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
+	comment.Comment = item["comment"].(*types.AttributeValueMemberS).Value
+	comment.CreatedDateTime, _ = time.Parse(time.RFC3339, item["createdDateTime"].(*types.AttributeValueMemberS).Value)
+	// 返信機能の導入前のコメントは意見へのコメントとして扱う
+	if parentCommentId, ok := item["parentCommentId"].(*types.AttributeValueMemberS); ok {
+		comment.ParentCommentID = parentCommentId.Value
+	}
+	comment.Depth = numberAttribute(item, "depth")
+	comment.ReplyCount = numberAttribute(item, "replyCount")
+	comment.ReactionCount = numberAttribute(item, "reactionCount")
+	if hidden, ok := item["hidden"].(*types.AttributeValueMemberBOOL); ok {
+		comment.Hidden = hidden.Value
+	}
+	return comment
+}
+
+// ScanReactions - 全リアクションをページ単位で取得し、1件ずつfnに渡すメソッド
+func (db *DynamoDBClient) ScanReactions(ctx context.Context, fn func(ReactionItem) error) error {
+	var fnErr error
+	err := db.scanAll(ctx, reactionsTableName, "opinionId, mailAddress, isReactioned, reactedDateTime", func(item map[string]types.AttributeValue) {
+		if fnErr != nil {
+			return
+		}
+		fnErr = fn(reactionFromItem(item))
+	})
+	if err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// reactionFromItem - DynamoDBの項目をReactionItemに変換する
+func reactionFromItem(item map[string]types.AttributeValue) ReactionItem {
+	var reaction ReactionItem
+	reaction.OpinionID = item["opinionId"].(*types.AttributeValueMemberS).Value
+	reaction.MailAddress = item["mailAddress"].(*types.AttributeValueMemberS).Value
+	if isReactioned, ok := item["isReactioned"].(*types.AttributeValueMemberBOOL); ok {
+		reaction.IsReactioned = isReactioned.Value
+	}
+	if reactionTypes, ok := item["reactionTypes"].(*types.AttributeValueMemberSS); ok {
+		reaction.Types = reactionTypes.Value
+	}
+	if reactedDateTime, ok := item["reactedDateTime"].(*types.AttributeValueMemberS); ok {
+		reaction.ReactedDateTime, _ = time.Parse(time.RFC3339, reactedDateTime.Value)
+	}
+	return reaction
+}
+
 // SaveReaction - リアクションをDynamoDBに保存(更新)するメソッド
-func (db *DynamoDBClient) SaveReaction(ctx context.Context, opinionId string, mailAddress string, isReactioned bool) (Reaction, error) {
-	item := map[string]types.AttributeValue{
-		"opinionId":    &types.AttributeValueMemberS{Value: opinionId},
-		"mailAddress":  &types.AttributeValueMemberS{Value: mailAddress},
-		"isReactioned": &types.AttributeValueMemberBOOL{Value: isReactioned},
+// リアクション状態が変わる場合のみ、意見の種類ごとのカウントを同じトランザクションで増減する
+// （同じ状態へのPUTを繰り返してもカウントは変わらない）
+func (db *DynamoDBClient) SaveReaction(ctx context.Context, opinionId string, mailAddress string, reactionType string, isReactioned bool) (Reaction, error) {
+	reaction := Reaction{Type: reactionType, IsReactioned: isReactioned}
+	now := time.Now()
+
+	// 区市町村ごとのリアクション数はデフォルトの種類のリアクションだけを数える
+	var area Area
+	if reactionType == DefaultReactionType {
+		var err error
+		area, err = db.opinionArea(ctx, opinionId)
+		if err != nil {
+			return Reaction{}, err
+		}
 	}
+	transactItems := reactionTransactItems(opinionId, mailAddress, reactionType, isReactioned, area, now)
 
-	_, err := db.Client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(reactionsTableName),
-		Item:      item,
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
 	})
+	if isConditionFailedAt(err, 0) {
+		return Reaction{}, ErrOpinionNotFound
+	}
+	if isConditionFailedAt(err, 1) {
+		// 既に同じ状態のため何もしない
+		return reaction, nil
+	}
 	if err != nil {
 		return Reaction{}, err
 	}
-	return Reaction{IsReactioned: isReactioned}, nil
+	// hotScoreはデフォルトの種類のリアクション数から計算する
+	if reactionType == DefaultReactionType {
+		if err := db.refreshHotScore(ctx, opinionId); err != nil {
+			log.Printf("failed to refresh hot score of %s: %v", opinionId, err)
+		}
+	}
+	return reaction, nil
+}
+
+// reactionTransactItems - リアクションを保存するトランザクションの項目
+// 先頭が意見のカウントの増減（意見が存在しない場合に失敗する）、2番目がリアクションの更新（既に同じ状態の場合に失敗する）
+func reactionTransactItems(opinionId string, mailAddress string, reactionType string, isReactioned bool, area Area, now time.Time) []types.TransactWriteItem {
+	delta := "1"
+	if !isReactioned {
+		delta = "-1"
+	}
+	reactionUpdate := &types.Update{
+		TableName: aws.String(reactionsTableName),
+		Key: map[string]types.AttributeValue{
+			"opinionId":   &types.AttributeValueMemberS{Value: opinionId},
+			"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			// reactedDateTimeは期間ごとの集計に使う
+			":now": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		},
+	}
+	// 状態が変わる場合のみ更新する条件（式で使わない値を渡すとDynamoDBが拒否するため、条件で使う値だけを設定する）
+	if reactionType == DefaultReactionType {
+		if isReactioned {
+			reactionUpdate.ConditionExpression = aws.String("attribute_not_exists(isReactioned) OR isReactioned = :false")
+			reactionUpdate.ExpressionAttributeValues[":false"] = &types.AttributeValueMemberBOOL{Value: false}
+		} else {
+			reactionUpdate.ConditionExpression = aws.String("isReactioned = :true")
+			reactionUpdate.ExpressionAttributeValues[":true"] = &types.AttributeValueMemberBOOL{Value: true}
+		}
+		reactionUpdate.UpdateExpression = aws.String("SET isReactioned = :isReactioned, reactedDateTime = :now")
+		reactionUpdate.ExpressionAttributeValues[":isReactioned"] = &types.AttributeValueMemberBOOL{Value: isReactioned}
+	} else {
+		reactionUpdate.ExpressionAttributeValues[":type"] = &types.AttributeValueMemberS{Value: reactionType}
+		reactionUpdate.ExpressionAttributeValues[":types"] = &types.AttributeValueMemberSS{Value: []string{reactionType}}
+		if isReactioned {
+			reactionUpdate.ConditionExpression = aws.String("NOT contains(reactionTypes, :type)")
+			reactionUpdate.UpdateExpression = aws.String("ADD reactionTypes :types SET reactedDateTime = :now")
+		} else {
+			reactionUpdate.ConditionExpression = aws.String("contains(reactionTypes, :type)")
+			reactionUpdate.UpdateExpression = aws.String("DELETE reactionTypes :types SET reactedDateTime = :now")
+		}
+	}
+
+	transactItems := append([]types.TransactWriteItem{
+		// 意見が存在する場合のみカウントを増減する
+		opinionCountUpdate(opinionId, reactionCountAttribute(reactionType), delta),
+		{Update: reactionUpdate},
+	}, opinionActivityUpdates(opinionId, "reactions", delta, now)...)
+	if reactionType == DefaultReactionType {
+		transactItems = append(transactItems, areaStatsUpdates(area, "reactionCount", delta, now)...)
+	}
+	return transactItems
 }
 
-// SaveReaction - リアクション情報をDynamoDBから取得するメソッド
+// GetReactionInfo - リアクション情報をDynamoDBから取得するメソッド
 func (db *DynamoDBClient) GetReactionInfo(ctx context.Context, opinionId string, mailAddress string) (ReactionInfo, error) {
-	// IsReactionedの取得
+	// リアクション数の取得（意見に保持しているカウントを使う）
+	opinionResult, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
+		},
+	})
+	if err != nil {
+		return ReactionInfo{}, err
+	}
+	if opinionResult.Item == nil {
+		return ReactionInfo{}, ErrOpinionNotFound
+	}
+
+	// 自分のリアクション状態の取得
 	isReactionedInput := &dynamodb.GetItemInput{
 		TableName: aws.String(reactionsTableName),
 		Key: map[string]types.AttributeValue{
@@ -219,34 +720,250 @@ func (db *DynamoDBClient) GetReactionInfo(ctx context.Context, opinionId string,
 		return ReactionInfo{}, err
 	}
 
-	var isReactioned bool
-	if result.Item != nil {
-		isReactioned = result.Item["isReactioned"].(*types.AttributeValueMemberBOOL).Value
+	return reactionInfoFromItems(opinionId, opinionResult.Item, result.Item), nil
+}
+
+// maxBatchGetKeys - BatchGetItemの1リクエストで取得できるキーの上限
+const maxBatchGetKeys = 100
+
+// GetReactionInfos - 複数の意見のリアクション情報をまとめて取得するメソッド
+// 意見とリアクションをBatchGetItemで取得し、存在しない意見は結果に含めない
+func (db *DynamoDBClient) GetReactionInfos(ctx context.Context, opinionIds []string, mailAddress string) (map[string]ReactionInfo, error) {
+	opinions := make(map[string]map[string]types.AttributeValue, len(opinionIds))
+	reactions := make(map[string]map[string]types.AttributeValue, len(opinionIds))
+
+	// 意見とリアクションのキーを交互に並べ、上限ごとに分割して取得する
+	keys := make([]batchGetKey, 0, len(opinionIds)*2)
+	for _, opinionId := range opinionIds {
+		keys = append(keys,
+			batchGetKey{table: opinionsTableName, key: map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: opinionId},
+			}},
+			batchGetKey{table: reactionsTableName, key: map[string]types.AttributeValue{
+				"opinionId":   &types.AttributeValueMemberS{Value: opinionId},
+				"mailAddress": &types.AttributeValueMemberS{Value: mailAddress},
+			}},
+		)
+	}
+	err := db.batchGetAll(ctx, keys, func(tableName string, item map[string]types.AttributeValue) {
+		switch tableName {
+		case opinionsTableName:
+			opinions[item["id"].(*types.AttributeValueMemberS).Value] = item
+		case reactionsTableName:
+			reactions[item["opinionId"].(*types.AttributeValueMemberS).Value] = item
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// ReactionCountの取得
-	reactionCountInput := &dynamodb.QueryInput{
-		TableName:              aws.String(reactionsTableName),
-		KeyConditionExpression: aws.String("opinionId = :opinionId"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":opinionId": &types.AttributeValueMemberS{Value: opinionId},
+	infos := make(map[string]ReactionInfo, len(opinions))
+	for opinionId, opinion := range opinions {
+		infos[opinionId] = reactionInfoFromItems(opinionId, opinion, reactions[opinionId])
+	}
+	return infos, nil
+}
+
+// reactionInfoFromItems - 意見の項目と自分のリアクションの項目（nilの場合は未リアクション）からリアクション情報を作る
+func reactionInfoFromItems(opinionId string, opinion map[string]types.AttributeValue, reaction map[string]types.AttributeValue) ReactionInfo {
+	info := ReactionInfo{
+		OpinionID:     opinionId,
+		ReactionCount: numberAttribute(opinion, "reactionCount"),
+		Counts:        reactionCountsFromItem(opinion),
+		Reactions:     []string{},
+	}
+	if reaction != nil {
+		r := reactionFromItem(reaction)
+		info.IsReactioned = r.IsReactioned
+		if r.IsReactioned {
+			info.Reactions = append(info.Reactions, DefaultReactionType)
+		}
+		info.Reactions = append(info.Reactions, r.Types...)
+	}
+	return info
+}
+
+// reactionCountsFromItem - 意見の項目から種類ごとのリアクション数を取得する
+func reactionCountsFromItem(item map[string]types.AttributeValue) map[string]int32 {
+	counts := map[string]int32{
+		DefaultReactionType: numberAttribute(item, "reactionCount"),
+	}
+	for name := range item {
+		if reactionType, ok := strings.CutPrefix(name, reactionCountPrefix); ok {
+			counts[reactionType] = numberAttribute(item, name)
+		}
+	}
+	return counts
+}
+
+// numberAttribute - 数値の属性を取得する（存在しない場合は0）
+func numberAttribute(item map[string]types.AttributeValue, name string) int32 {
+	v, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(v.Value, 10, 32)
+	return int32(n)
+}
+
+// SetOpinionCounts - 意見のカウントを集計値で上書きするメソッド（既存データの移行・補正用）
+// 並び替え用のGSIのキー（listKey, hotScore）もあわせて設定する
+func (db *DynamoDBClient) SetOpinionCounts(ctx context.Context, opinionId string, counts OpinionCounts, createdDateTime time.Time) error {
+	updateExpression := "SET reactionCount = :reactionCount, commentCount = :commentCount, listKey = :listKey, hotScore = :hotScore"
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{
+		":reactionCount": &types.AttributeValueMemberN{Value: strconv.Itoa(int(counts.ReactionCount))},
+		":commentCount":  &types.AttributeValueMemberN{Value: strconv.Itoa(int(counts.CommentCount))},
+		":listKey":       &types.AttributeValueMemberS{Value: opinionListKey},
+		":hotScore":      hotScoreAttribute(HotScore(counts.ReactionCount, counts.CommentCount, createdDateTime)),
+	}
+	i := 0
+	for reactionType, count := range counts.TypeCounts {
+		name := fmt.Sprintf("#type%d", i)
+		value := fmt.Sprintf(":type%d", i)
+		updateExpression += fmt.Sprintf(", %s = %s", name, value)
+		names[name] = reactionCountAttribute(reactionType)
+		values[value] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(count))}
+		i++
+	}
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(opinionsTableName),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: opinionId},
 		},
+		ConditionExpression:       aws.String(opinionExistsCondition),
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: values,
+	}
+	if len(names) > 0 {
+		input.ExpressionAttributeNames = names
 	}
+	_, err := db.Client.UpdateItem(ctx, input)
+	return err
+}
+
+// GetOpinionCounts - 全意見のコメント数・リアクション数を意見IDごとに集計するメソッド
+func (db *DynamoDBClient) GetOpinionCounts(ctx context.Context) (map[string]OpinionCounts, error) {
+	counts := make(map[string]OpinionCounts)
 
-	reactionCountResult, err := db.Client.Query(ctx, reactionCountInput)
+	// コメント数の集計
+	err := db.scanAll(ctx, commentsTableName, "opinionId", func(item map[string]types.AttributeValue) {
+		opinionId := item["opinionId"].(*types.AttributeValueMemberS).Value
+		c := counts[opinionId]
+		c.CommentCount++
+		counts[opinionId] = c
+	})
 	if err != nil {
-		return ReactionInfo{}, err
+		return nil, err
 	}
 
-	var reactionCount int32
-	for _, item := range reactionCountResult.Items {
-		if item["isReactioned"].(*types.AttributeValueMemberBOOL).Value {
-			reactionCount++
+	// リアクション数の集計
+	err = db.scanAll(ctx, reactionsTableName, "opinionId, mailAddress, isReactioned, reactionTypes", func(item map[string]types.AttributeValue) {
+		reaction := reactionFromItem(item)
+		c := counts[reaction.OpinionID]
+		if reaction.IsReactioned {
+			c.ReactionCount++
 		}
+		for _, reactionType := range reaction.Types {
+			if c.TypeCounts == nil {
+				c.TypeCounts = make(map[string]int32)
+			}
+			c.TypeCounts[reactionType]++
+		}
+		counts[reaction.OpinionID] = c
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// batchGetKey - BatchGetItemで取得する項目のテーブル名とキー
+type batchGetKey struct {
+	table string
+	key   map[string]types.AttributeValue
+}
+
+// batchGetAll - BatchGetItemで全てのキーの項目を取得し、見つかった項目ごとにfnを呼び出す
+// 上限ごとにリクエストを分割し、UnprocessedKeysはバックオフしながら再取得する
+func (db *DynamoDBClient) batchGetAll(ctx context.Context, keys []batchGetKey, fn func(tableName string, item map[string]types.AttributeValue)) error {
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		requestItems := make(map[string]types.KeysAndAttributes)
+		for _, k := range keys[start:min(start+maxBatchGetKeys, len(keys))] {
+			r := requestItems[k.table]
+			r.Keys = append(r.Keys, k.key)
+			requestItems[k.table] = r
+		}
+
+		for attempt := 0; len(requestItems) > 0; attempt++ {
+			if attempt > 0 {
+				if attempt > maxBatchGetRetries {
+					return errors.New("BatchGetItem: unprocessed keys remain after retries")
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(batchGetBackoff(attempt)):
+				}
+			}
+
+			result, err := db.Client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: requestItems,
+			})
+			if err != nil {
+				log.Printf("DynamoDB BatchGetItem failed: %v", err)
+				return err
+			}
+			for tableName, items := range result.Responses {
+				for _, item := range items {
+					fn(tableName, item)
+				}
+			}
+			requestItems = result.UnprocessedKeys
+		}
+	}
+	return nil
+}
+
+// maxBatchGetRetries - UnprocessedKeysを再取得する最大回数
+const maxBatchGetRetries = 5
+
+// batchGetBackoff - 再取得までの待ち時間（50ms, 100ms, 200ms...）
+func batchGetBackoff(attempt int) time.Duration {
+	return 50 * time.Millisecond << (attempt - 1)
+}
+
+// scanAll - テーブルを最後までScanし、各項目をfnに渡すメソッド
+// projectionが空の場合は全属性を取得する
+func (db *DynamoDBClient) scanAll(ctx context.Context, tableName string, projection string, fn func(map[string]types.AttributeValue)) error {
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		input := &dynamodb.ScanInput{
+			TableName:         aws.String(tableName),
+			ExclusiveStartKey: lastEvaluatedKey,
+		}
+		if projection != "" {
+			input.ProjectionExpression = aws.String(projection)
+		}
+
+		result, err := db.Client.Scan(ctx, input)
+		if err != nil {
+			log.Printf("DynamoDB Scan failed: %v", err)
+			return err
+		}
+
+		for _, item := range result.Items {
+			fn(item)
+		}
+
+		// LastEvaluatedKeyがnilでない場合、再度取得
+		if result.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = result.LastEvaluatedKey
 	}
 
-	return ReactionInfo{
-		IsReactioned:  isReactioned,
-		ReactionCount: reactionCount,
-	}, nil
+	return nil
 }
@@ -0,0 +1,161 @@
+package infra
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestOpinionItemCounts(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		want OpinionCounts
+	}{
+		{
+			name: "no counters (posted before counting)",
+			item: map[string]types.AttributeValue{},
+			want: OpinionCounts{},
+		},
+		{
+			name: "default reaction and comments",
+			item: map[string]types.AttributeValue{
+				"reactionCount": &types.AttributeValueMemberN{Value: "3"},
+				"commentCount":  &types.AttributeValueMemberN{Value: "2"},
+			},
+			want: OpinionCounts{ReactionCount: 3, CommentCount: 2},
+		},
+		{
+			name: "custom reaction types",
+			item: map[string]types.AttributeValue{
+				"reactionCount":                      &types.AttributeValueMemberN{Value: "1"},
+				reactionCountAttribute("agree"):      &types.AttributeValueMemberN{Value: "4"},
+				reactionCountAttribute("interested"): &types.AttributeValueMemberN{Value: "0"},
+			},
+			want: OpinionCounts{ReactionCount: 1, TypeCounts: map[string]int32{"agree": 4, "interested": 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := map[string]types.AttributeValue{
+				"id":          &types.AttributeValueMemberS{Value: "1"},
+				"mailAddress": &types.AttributeValueMemberS{Value: "a@example.com"},
+				"latitude":    &types.AttributeValueMemberN{Value: "35.6"},
+				"longitude":   &types.AttributeValueMemberN{Value: "139.7"},
+				"opinion":     &types.AttributeValueMemberS{Value: "opinion"},
+			}
+			for name, value := range tt.item {
+				item[name] = value
+			}
+			if got := opinionFromItem(item).Counts(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Counts() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// checkExpressionValues - トランザクションの各項目で、宣言した値・属性名を全て式で使い、式で使う値・属性名を全て宣言しているか確認する
+// （使わない値を渡すとDynamoDBがValidationExceptionで拒否する）
+func checkExpressionValues(t *testing.T, items []types.TransactWriteItem) {
+	t.Helper()
+	placeholder := regexp.MustCompile(`[:#][A-Za-z0-9_]+`)
+	for i, item := range items {
+		var expressions []*string
+		var values map[string]types.AttributeValue
+		var names map[string]string
+		switch {
+		case item.Update != nil:
+			expressions = []*string{item.Update.UpdateExpression, item.Update.ConditionExpression}
+			values, names = item.Update.ExpressionAttributeValues, item.Update.ExpressionAttributeNames
+		case item.Put != nil:
+			expressions = []*string{item.Put.ConditionExpression}
+			values, names = item.Put.ExpressionAttributeValues, item.Put.ExpressionAttributeNames
+		case item.Delete != nil:
+			expressions = []*string{item.Delete.ConditionExpression}
+			values, names = item.Delete.ExpressionAttributeValues, item.Delete.ExpressionAttributeNames
+		case item.ConditionCheck != nil:
+			expressions = []*string{item.ConditionCheck.ConditionExpression}
+			values, names = item.ConditionCheck.ExpressionAttributeValues, item.ConditionCheck.ExpressionAttributeNames
+		}
+
+		used := make(map[string]bool)
+		for _, expression := range expressions {
+			for _, p := range placeholder.FindAllString(aws.ToString(expression), -1) {
+				used[p] = true
+			}
+		}
+		declared := make(map[string]bool)
+		for name := range values {
+			declared[name] = true
+		}
+		for name := range names {
+			declared[name] = true
+		}
+		for name := range declared {
+			if !used[name] {
+				t.Errorf("item %d declares %s but does not use it", i, name)
+			}
+		}
+		for name := range used {
+			if !declared[name] {
+				t.Errorf("item %d uses %s but does not declare it", i, name)
+			}
+		}
+	}
+}
+
+func TestReactionTransactItems(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+	area := Area{Code: "13101", Name: "千代田区"}
+
+	tests := []struct {
+		name         string
+		reactionType string
+		isReactioned bool
+		wantCount    string // 意見のカウントの属性名
+		wantDelta    string
+		wantItems    int
+	}{
+		// 意見のカウント・リアクション・急上昇の集計2件・区市町村の集計2件
+		{"react", DefaultReactionType, true, "reactionCount", "1", 6},
+		{"unreact", DefaultReactionType, false, "reactionCount", "-1", 6},
+		// 区市町村の集計はデフォルトの種類だけを数える
+		{"react with a custom type", "agree", true, reactionCountAttribute("agree"), "1", 4},
+		{"unreact with a custom type", "agree", false, reactionCountAttribute("agree"), "-1", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := reactionTransactItems("1", "a@example.com", tt.reactionType, tt.isReactioned, area, now)
+			if len(items) != tt.wantItems {
+				t.Fatalf("items = %d, want %d", len(items), tt.wantItems)
+			}
+			checkExpressionValues(t, items)
+
+			// 先頭は意見が存在する場合のみカウントを増減する項目（失敗時にErrOpinionNotFoundを返すため順番を変えない）
+			count := items[0].Update
+			if aws.ToString(count.TableName) != opinionsTableName || aws.ToString(count.ConditionExpression) != opinionExistsCondition {
+				t.Errorf("items[0] = %s %q, want the opinion count update", aws.ToString(count.TableName), aws.ToString(count.ConditionExpression))
+			}
+			if got := count.ExpressionAttributeNames["#count"]; got != tt.wantCount {
+				t.Errorf("#count = %q, want %q", got, tt.wantCount)
+			}
+			for i, item := range items {
+				if i == 1 {
+					continue
+				}
+				if got := item.Update.ExpressionAttributeValues[":delta"].(*types.AttributeValueMemberN).Value; got != tt.wantDelta {
+					t.Errorf("items[%d] :delta = %q, want %q", i, got, tt.wantDelta)
+				}
+			}
+
+			// 2番目は状態が変わる場合のみ成功するリアクションの更新
+			if reaction := items[1].Update; aws.ToString(reaction.TableName) != reactionsTableName || reaction.ConditionExpression == nil {
+				t.Errorf("items[1] = %s, want a conditional reaction update", aws.ToString(reaction.TableName))
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// rateLimitsTableName - レート制限のトークンバケットを保存するテーブル（キーはbucketKey、expiresAtをTTLに設定する）
+// Lambdaのインスタンスをまたいで制限するため、バケットはDynamoDBに持つ
+const rateLimitsTableName = "rateLimits"
+
+// maxTakeTokenAttempts - 同じバケットへの同時更新で条件付き書き込みが失敗した場合に再試行する回数
+const maxTakeTokenAttempts = 3
+
+// TokenBucket - トークンバケット（最大Capacity個のトークンを持ち、PerごとにCapacity個の割合で補充される）
+type TokenBucket struct {
+	Key      string
+	Capacity int
+	Per      time.Duration
+}
+
+// rate - 1秒あたりの補充数
+func (b TokenBucket) rate() float64 {
+	return float64(b.Capacity) / b.Per.Seconds()
+}
+
+// tokensAt - 保存されたトークン数に、最後の更新からnowまでに補充された分を加える（Capacityを超えない）
+func (b TokenBucket) tokensAt(stored float64, updatedAt time.Time, now time.Time) float64 {
+	elapsed := math.Max(now.Sub(updatedAt).Seconds(), 0)
+	return math.Min(float64(b.Capacity), stored+elapsed*b.rate())
+}
+
+// waitFor - トークンが1つ補充されるまでの時間
+func (b TokenBucket) waitFor(tokens float64) time.Duration {
+	return time.Duration((1 - tokens) / b.rate() * float64(time.Second))
+}
+
+// TakeTokens - 全てのバケットからトークンを1つずつ取り出すメソッド
+// いずれかのバケットが空の場合はどのバケットからも取り出さず、全てのバケットにトークンが補充されるまでの時間を返す（取り出せた場合は0）。
+// 取り出しは1つのトランザクションで書き込むため、一部のバケットだけが消費されることはない
+func (db *DynamoDBClient) TakeTokens(ctx context.Context, buckets []TokenBucket) (time.Duration, error) {
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	for attempt := 0; attempt < maxTakeTokenAttempts; attempt++ {
+		now := time.Now()
+		var wait time.Duration
+		puts := make([]types.TransactWriteItem, 0, len(buckets))
+		for _, bucket := range buckets {
+			bucketKey := &types.AttributeValueMemberS{Value: bucket.Key}
+			result, err := db.Client.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName:      aws.String(rateLimitsTableName),
+				Key:            map[string]types.AttributeValue{"bucketKey": bucketKey},
+				ConsistentRead: aws.Bool(true),
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			// バケットがない（TTLで削除された）場合は満杯として扱う
+			tokens := float64(bucket.Capacity)
+			var version int64
+			condition := "attribute_not_exists(bucketKey)"
+			values := map[string]types.AttributeValue{}
+			if result.Item != nil {
+				stored, _ := strconv.ParseFloat(result.Item["tokens"].(*types.AttributeValueMemberN).Value, 64)
+				updatedAt, _ := strconv.ParseInt(result.Item["updatedAt"].(*types.AttributeValueMemberN).Value, 10, 64)
+				version, _ = strconv.ParseInt(result.Item["version"].(*types.AttributeValueMemberN).Value, 10, 64)
+				tokens = bucket.tokensAt(stored, time.UnixMilli(updatedAt), now)
+				condition = "version = :version"
+				values[":version"] = result.Item["version"]
+			}
+			if tokens < 1 {
+				wait = max(wait, bucket.waitFor(tokens))
+				continue
+			}
+			tokens--
+
+			// 満杯に戻るまでの時間が過ぎたバケットは削除してよい
+			refill := time.Duration((float64(bucket.Capacity) - tokens) / bucket.rate() * float64(time.Second))
+			put := &types.Put{
+				TableName: aws.String(rateLimitsTableName),
+				Item: map[string]types.AttributeValue{
+					"bucketKey": bucketKey,
+					"tokens":    &types.AttributeValueMemberN{Value: strconv.FormatFloat(tokens, 'f', -1, 64)},
+					"updatedAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.UnixMilli(), 10)},
+					"version":   &types.AttributeValueMemberN{Value: strconv.FormatInt(version+1, 10)},
+					"expiresAt": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(refill).Unix()+1, 10)},
+				},
+				ConditionExpression: aws.String(condition),
+			}
+			if len(values) > 0 {
+				put.ExpressionAttributeValues = values
+			}
+			puts = append(puts, types.TransactWriteItem{Put: put})
+		}
+		// 空のバケットがあればどのバケットも消費しない
+		if wait > 0 {
+			return wait, nil
+		}
+
+		_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: puts})
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			// 他のリクエストが先にバケットを更新したため、読み直して再試行する
+			continue
+		}
+		return 0, err
+	}
+
+	// 更新の競合が続く場合は同じバケットに多数のリクエストが集中しているため、少し待たせる
+	return time.Second, nil
+}
@@ -0,0 +1,34 @@
+package infra
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := TokenBucket{Key: "k", Capacity: 10, Per: 10 * time.Second} // 1秒に1つ補充
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		stored    float64
+		updatedAt time.Time
+		want      float64
+	}{
+		{"no time elapsed", 3, now, 3},
+		{"refilled by elapsed time", 3, now.Add(-2500 * time.Millisecond), 5.5},
+		{"capped at capacity", 3, now.Add(-time.Hour), 10},
+		{"clock skew does not remove tokens", 3, now.Add(time.Minute), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bucket.tokensAt(tt.stored, tt.updatedAt, now); got != tt.want {
+				t.Errorf("tokensAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := bucket.waitFor(0.25); got != 750*time.Millisecond {
+		t.Errorf("waitFor(0.25) = %v, want 750ms", got)
+	}
+}
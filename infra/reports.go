@@ -0,0 +1,415 @@
+package infra
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// reportsTableName - ユーザーごとの通報を保存するテーブル（キーは(targetId, mailAddress)で、1人1件まで）
+const reportsTableName = "reports"
+
+// moderationQueueTableName - 通報された意見・コメントごとの集計（モデレーションキュー）を保存するテーブル（キーはtargetId）
+const moderationQueueTableName = "moderationQueue"
+
+// moderationQueueIndexName - 対応待ちの項目を最終通報日時順に取得するGSI
+// 対応待ちの項目のみqueueKeyを持つ（対応済みの項目はqueueKeyを削除してGSIから外す）
+const moderationQueueIndexName = "queueKey-lastReportedDateTime-index"
+
+// moderationQueueKey - 対応待ちの項目に共通のパーティションキー
+const moderationQueueKey = "open"
+
+// reasonCountPrefix - 通報理由ごとの件数を保持するモデレーションキューの属性名の接頭辞
+const reasonCountPrefix = "reasonCount_"
+
+// ErrAlreadyReported - 同じユーザーが同じ意見・コメントを既に通報している
+var ErrAlreadyReported = errors.New("already reported")
+
+// ReportTargetType - 通報の対象の種類
+type ReportTargetType string
+
+const (
+	ReportTargetOpinion ReportTargetType = "opinion"
+	ReportTargetComment ReportTargetType = "comment"
+)
+
+// ReportTarget - 通報の対象（コメントの場合はCommentIDを持つ）
+type ReportTarget struct {
+	Type      ReportTargetType
+	OpinionID string
+	CommentID string
+}
+
+// OpinionReportTarget - 意見を通報の対象にする
+func OpinionReportTarget(opinionId string) ReportTarget {
+	return ReportTarget{Type: ReportTargetOpinion, OpinionID: opinionId}
+}
+
+// CommentReportTarget - コメントを通報の対象にする
+func CommentReportTarget(opinionId string, commentId string) ReportTarget {
+	return ReportTarget{Type: ReportTargetComment, OpinionID: opinionId, CommentID: commentId}
+}
+
+// id - 通報・モデレーションキューのキー（意見ID・コメントIDはどちらもUUIDのため重複しない）
+func (t ReportTarget) id() string {
+	if t.Type == ReportTargetComment {
+		return t.CommentID
+	}
+	return t.OpinionID
+}
+
+// table - 対象の意見・コメントを保存するテーブルとキー、存在を確認する条件式
+func (t ReportTarget) table() (string, map[string]types.AttributeValue, string) {
+	if t.Type == ReportTargetComment {
+		return commentsTableName, map[string]types.AttributeValue{
+			"opinionId": &types.AttributeValueMemberS{Value: t.OpinionID},
+			"commentId": &types.AttributeValueMemberS{Value: t.CommentID},
+		}, "attribute_exists(commentId)"
+	}
+	return opinionsTableName, map[string]types.AttributeValue{
+		"id": &types.AttributeValueMemberS{Value: t.OpinionID},
+	}, opinionExistsCondition
+}
+
+// notFound - 対象が存在しない場合のエラー
+func (t ReportTarget) notFound() error {
+	if t.Type == ReportTargetComment {
+		return ErrCommentNotFound
+	}
+	return ErrOpinionNotFound
+}
+
+// ReportedItem - モデレーションキューの項目（通報された意見・コメントと通報の集計）
+type ReportedItem struct {
+	TargetType            ReportTargetType
+	OpinionID             string `json:"OpinionId"`
+	CommentID             string `json:"CommentId,omitempty"`
+	MailAddress           string // 投稿者のメールアドレス
+	Content               string // 意見・コメントの本文（削除済みの場合は空）
+	ReportCount           int32
+	ReasonCounts          map[string]int32 // 通報理由ごとの件数
+	Hidden                bool             // 非表示になっているかどうか
+	Status                OpinionStatus    `json:",omitempty"` // 意見の公開状態（コメントの場合は空）
+	FilterFlags           []string         `json:",omitempty"` // 投稿時の自動フィルターが検出した内容の種類
+	FirstReportedDateTime time.Time
+	LastReportedDateTime  time.Time
+}
+
+// ReportedItemPage - モデレーションキューの1ページ
+type ReportedItemPage struct {
+	Items      []ReportedItem
+	NextCursor string // 次のページがない場合は空
+}
+
+// reportCursor - 次のページの開始位置（最後に評価した項目のキー）
+type reportCursor struct {
+	ID               string `json:"id"`
+	ReportedDateTime string `json:"t"`
+}
+
+// hiddenFilterExpression - 非表示の意見・コメントを除く条件式（hiddenを持たない項目は表示する）
+const hiddenFilterExpression = "(attribute_not_exists(hidden) OR hidden = :notHidden)"
+
+// hiddenFilterValue - hiddenFilterExpressionの値
+var hiddenFilterValue = &types.AttributeValueMemberBOOL{Value: false}
+
+// SaveReport - 意見・コメントへの通報を保存するメソッド
+// 同じユーザー（reporter。呼び出し側でアクセストークンから確認した通報者）の通報は1件までとし、対象の通報数とモデレーションキューの集計を増やす。
+// 通報数がhideThreshold以上になった場合は対象を非表示にし、非表示にしたかどうかを返す
+// （モデレーターが表示に戻した対象は再び自動で非表示にしない）
+func (db *DynamoDBClient) SaveReport(ctx context.Context, target ReportTarget, reporter string, reason string, detail string, hideThreshold int32) (bool, error) {
+	now := time.Now().Format(time.RFC3339)
+	tableName, key, existsCondition := target.table()
+
+	report := map[string]types.AttributeValue{
+		"targetId":        &types.AttributeValueMemberS{Value: target.id()},
+		"mailAddress":     &types.AttributeValueMemberS{Value: reporter},
+		"targetType":      &types.AttributeValueMemberS{Value: string(target.Type)},
+		"opinionId":       &types.AttributeValueMemberS{Value: target.OpinionID},
+		"reason":          &types.AttributeValueMemberS{Value: reason},
+		"createdDateTime": &types.AttributeValueMemberS{Value: now},
+	}
+	queueValues := map[string]types.AttributeValue{
+		":one":        &types.AttributeValueMemberN{Value: "1"},
+		":targetType": &types.AttributeValueMemberS{Value: string(target.Type)},
+		":opinionId":  &types.AttributeValueMemberS{Value: target.OpinionID},
+		":queueKey":   &types.AttributeValueMemberS{Value: moderationQueueKey},
+		":now":        &types.AttributeValueMemberS{Value: now},
+	}
+	queueUpdate := "SET targetType = :targetType, opinionId = :opinionId, queueKey = :queueKey, " +
+		"firstReportedDateTime = if_not_exists(firstReportedDateTime, :now), lastReportedDateTime = :now " +
+		"ADD reportCount :one, #reasonCount :one"
+	if target.Type == ReportTargetComment {
+		report["commentId"] = &types.AttributeValueMemberS{Value: target.CommentID}
+		queueUpdate = "SET commentId = :commentId, " + strings.TrimPrefix(queueUpdate, "SET ")
+		queueValues[":commentId"] = &types.AttributeValueMemberS{Value: target.CommentID}
+	}
+	if detail != "" {
+		report["detail"] = &types.AttributeValueMemberS{Value: detail}
+	}
+
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			// 対象が存在する場合のみ通報数を増やす
+			{Update: &types.Update{
+				TableName:           aws.String(tableName),
+				Key:                 key,
+				ConditionExpression: aws.String(existsCondition),
+				UpdateExpression:    aws.String("ADD reportCount :one"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":one": &types.AttributeValueMemberN{Value: "1"},
+				},
+			}},
+			// 同じユーザーの通報は1件まで
+			{Put: &types.Put{
+				TableName:           aws.String(reportsTableName),
+				Item:                report,
+				ConditionExpression: aws.String("attribute_not_exists(mailAddress)"),
+			}},
+			{Update: &types.Update{
+				TableName: aws.String(moderationQueueTableName),
+				Key: map[string]types.AttributeValue{
+					"targetId": &types.AttributeValueMemberS{Value: target.id()},
+				},
+				UpdateExpression: aws.String(queueUpdate),
+				ExpressionAttributeNames: map[string]string{
+					"#reasonCount": reasonCountPrefix + reason,
+				},
+				ExpressionAttributeValues: queueValues,
+			}},
+		},
+	})
+	if isConditionFailedAt(err, 0) {
+		return false, target.notFound()
+	}
+	if isConditionFailedAt(err, 1) {
+		return false, ErrAlreadyReported
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return db.hideReportedItem(ctx, target, hideThreshold)
+}
+
+// hideReportedItem - 通報数が閾値以上で、まだ非表示・表示の判断をしていない対象を非表示にするメソッド
+func (db *DynamoDBClient) hideReportedItem(ctx context.Context, target ReportTarget, hideThreshold int32) (bool, error) {
+	tableName, key, _ := target.table()
+	now := &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)}
+	_, err := db.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Update: &types.Update{
+				TableName:           aws.String(tableName),
+				Key:                 key,
+				ConditionExpression: aws.String("reportCount >= :threshold AND attribute_not_exists(hidden)"),
+				UpdateExpression:    aws.String("SET hidden = :hidden, hiddenDateTime = :now"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":threshold": &types.AttributeValueMemberN{Value: strconv.Itoa(int(hideThreshold))},
+					":hidden":    &types.AttributeValueMemberBOOL{Value: true},
+					":now":       now,
+				},
+			}},
+			{Update: &types.Update{
+				TableName: aws.String(moderationQueueTableName),
+				Key: map[string]types.AttributeValue{
+					"targetId": &types.AttributeValueMemberS{Value: target.id()},
+				},
+				UpdateExpression: aws.String("SET hidden = :hidden"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":hidden": &types.AttributeValueMemberBOOL{Value: true},
+				},
+			}},
+		},
+	})
+	if isConditionFailedAt(err, 0) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	log.Printf("%s %s: hidden by reports", target.Type, target.id())
+	return true, nil
+}
+
+// moderationHoldUpdate - 投稿時にモデレーターの確認を待つ意見・コメントをモデレーションキューに入れる更新
+// flagsには自動フィルターが検出した内容の種類を渡す（検出した対象は保存時に非表示にする）。
+// 事前モデレーションで承認待ちの意見はflagsなしで入れる。いずれも通報数は増やさない
+func moderationHoldUpdate(target ReportTarget, flags []string, now string) types.TransactWriteItem {
+	values := map[string]types.AttributeValue{
+		":targetType": &types.AttributeValueMemberS{Value: string(target.Type)},
+		":opinionId":  &types.AttributeValueMemberS{Value: target.OpinionID},
+		":queueKey":   &types.AttributeValueMemberS{Value: moderationQueueKey},
+		":now":        &types.AttributeValueMemberS{Value: now},
+		":hidden":     &types.AttributeValueMemberBOOL{Value: len(flags) > 0},
+	}
+	update := "SET targetType = :targetType, opinionId = :opinionId, queueKey = :queueKey, hidden = :hidden, " +
+		"firstReportedDateTime = :now, lastReportedDateTime = :now"
+	// 空の文字列セットは保存できないため、検出した内容がない場合は属性を持たせない
+	if len(flags) > 0 {
+		update += ", filterFlags = :flags"
+		values[":flags"] = &types.AttributeValueMemberSS{Value: flags}
+	}
+	if target.Type == ReportTargetComment {
+		update += ", commentId = :commentId"
+		values[":commentId"] = &types.AttributeValueMemberS{Value: target.CommentID}
+	}
+	return types.TransactWriteItem{Update: &types.Update{
+		TableName: aws.String(moderationQueueTableName),
+		Key: map[string]types.AttributeValue{
+			"targetId": &types.AttributeValueMemberS{Value: target.id()},
+		},
+		UpdateExpression:          aws.String(update),
+		ExpressionAttributeValues: values,
+	}}
+}
+
+// holdItem - 自動フィルターが検出した意見・コメントを非表示の状態で保存するための属性を設定する
+func holdItem(item map[string]types.AttributeValue, now string) {
+	item["hidden"] = &types.AttributeValueMemberBOOL{Value: true}
+	item["hiddenDateTime"] = &types.AttributeValueMemberS{Value: now}
+}
+
+// ListReportedItems - 対応待ちの通報された意見・コメントを最終通報日時の新しい順に1ページ取得するメソッド
+func (db *DynamoDBClient) ListReportedItems(ctx context.Context, limit int32, cursor string) (ReportedItemPage, error) {
+	startKey, err := decodeReportCursor(cursor)
+	if err != nil {
+		return ReportedItemPage{}, err
+	}
+
+	result, err := db.Client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(moderationQueueTableName),
+		IndexName:              aws.String(moderationQueueIndexName),
+		KeyConditionExpression: aws.String("queueKey = :queueKey"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":queueKey": &types.AttributeValueMemberS{Value: moderationQueueKey},
+		},
+		ScanIndexForward:  aws.Bool(false), // 降順
+		ExclusiveStartKey: startKey,
+		Limit:             aws.Int32(limit),
+	})
+	if err != nil {
+		log.Printf("DynamoDB Query failed: %v", err)
+		return ReportedItemPage{}, err
+	}
+
+	page := ReportedItemPage{Items: make([]ReportedItem, 0, len(result.Items))}
+	keys := make([]batchGetKey, 0, len(result.Items))
+	for _, item := range result.Items {
+		reported := reportedItemFromItem(item)
+		page.Items = append(page.Items, reported)
+		target := ReportTarget{Type: reported.TargetType, OpinionID: reported.OpinionID, CommentID: reported.CommentID}
+		tableName, key, _ := target.table()
+		keys = append(keys, batchGetKey{table: tableName, key: key})
+	}
+
+	// 対象の本文と投稿者を取得する（削除された対象は本文を空のまま返す）
+	contents := make(map[string]map[string]types.AttributeValue, len(keys))
+	err = db.batchGetAll(ctx, keys, func(tableName string, item map[string]types.AttributeValue) {
+		if tableName == commentsTableName {
+			contents[item["commentId"].(*types.AttributeValueMemberS).Value] = item
+		} else {
+			contents[item["id"].(*types.AttributeValueMemberS).Value] = item
+		}
+	})
+	if err != nil {
+		return ReportedItemPage{}, err
+	}
+	for i, reported := range page.Items {
+		content, ok := contents[reported.CommentID]
+		if reported.TargetType == ReportTargetOpinion {
+			content, ok = contents[reported.OpinionID]
+		}
+		if !ok {
+			continue
+		}
+		page.Items[i].MailAddress = content["mailAddress"].(*types.AttributeValueMemberS).Value
+		if reported.TargetType == ReportTargetComment {
+			page.Items[i].Content = content["comment"].(*types.AttributeValueMemberS).Value
+		} else {
+			opinion := opinionFromItem(content)
+			page.Items[i].Content = opinion.Opinion
+			page.Items[i].Status = opinion.Status
+		}
+	}
+
+	if result.LastEvaluatedKey != nil {
+		page.NextCursor, err = encodeReportCursor(result.LastEvaluatedKey)
+		if err != nil {
+			return ReportedItemPage{}, err
+		}
+	}
+	return page, nil
+}
+
+// reportedItemFromItem - モデレーションキューの項目をReportedItemに変換する
+func reportedItemFromItem(item map[string]types.AttributeValue) ReportedItem {
+	reported := ReportedItem{
+		TargetType:   ReportTargetType(item["targetType"].(*types.AttributeValueMemberS).Value),
+		OpinionID:    item["opinionId"].(*types.AttributeValueMemberS).Value,
+		ReportCount:  numberAttribute(item, "reportCount"),
+		ReasonCounts: make(map[string]int32),
+	}
+	if commentId, ok := item["commentId"].(*types.AttributeValueMemberS); ok {
+		reported.CommentID = commentId.Value
+	}
+	if hidden, ok := item["hidden"].(*types.AttributeValueMemberBOOL); ok {
+		reported.Hidden = hidden.Value
+	}
+	if v, ok := item["firstReportedDateTime"].(*types.AttributeValueMemberS); ok {
+		reported.FirstReportedDateTime, _ = time.Parse(time.RFC3339, v.Value)
+	}
+	if v, ok := item["lastReportedDateTime"].(*types.AttributeValueMemberS); ok {
+		reported.LastReportedDateTime, _ = time.Parse(time.RFC3339, v.Value)
+	}
+	if flags, ok := item["filterFlags"].(*types.AttributeValueMemberSS); ok {
+		reported.FilterFlags = flags.Value
+		sort.Strings(reported.FilterFlags)
+	}
+	for name := range item {
+		if reason, ok := strings.CutPrefix(name, reasonCountPrefix); ok {
+			reported.ReasonCounts[reason] = numberAttribute(item, name)
+		}
+	}
+	return reported
+}
+
+func encodeReportCursor(key map[string]types.AttributeValue) (string, error) {
+	b, err := json.Marshal(reportCursor{
+		ID:               key["targetId"].(*types.AttributeValueMemberS).Value,
+		ReportedDateTime: key["lastReportedDateTime"].(*types.AttributeValueMemberS).Value,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeReportCursor - カーソルをQueryのExclusiveStartKeyに変換する（空の場合は先頭から）
+func decodeReportCursor(cursor string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c reportCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" || c.ReportedDateTime == "" {
+		return nil, ErrInvalidCursor
+	}
+	return map[string]types.AttributeValue{
+		"targetId":             &types.AttributeValueMemberS{Value: c.ID},
+		"queueKey":             &types.AttributeValueMemberS{Value: moderationQueueKey},
+		"lastReportedDateTime": &types.AttributeValueMemberS{Value: c.ReportedDateTime},
+	}, nil
+}
@@ -0,0 +1,185 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Client - 添付ファイルを保存するS3バケットのクライアント
+type S3Client struct {
+	Client  *s3.Client
+	presign *s3.PresignClient
+	Bucket  string
+	// 公開URLのベース（CloudFrontなど）。空の場合は署名付きGET URLを発行する
+	publicBaseURL string
+}
+
+// ConnectS3Service creates a S3 client for the attachment bucket
+// 環境変数ATTACHMENT_BUCKETが未指定の場合はnilを返す（添付ファイル機能を無効にする）
+// ATTACHMENT_BASE_URLで公開する場合は、変換後の画像（opinions/）のみを公開し、
+// 位置情報などのメタデータを含むアップロードされたファイル（uploads/）は公開しないこと
+// S3_ENDPOINTを指定するとS3互換のローカル環境（MinIOなど）にパス形式で接続する
+func ConnectS3Service() *S3Client {
+	bucket := os.Getenv("ATTACHMENT_BUCKET")
+	if bucket == "" {
+		return nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// クライアント作成
+	svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Client{
+		Client:        svc,
+		presign:       s3.NewPresignClient(svc),
+		Bucket:        bucket,
+		publicBaseURL: strings.TrimSuffix(os.Getenv("ATTACHMENT_BASE_URL"), "/"),
+	}
+}
+
+// PresignedRequest - クライアントがS3へ直接送るリクエスト
+type PresignedRequest struct {
+	URL       string
+	Method    string
+	Headers   map[string]string // 署名に含めたため、そのまま送る必要があるヘッダー
+	ExpiresAt time.Time
+}
+
+// PresignPut - オブジェクトをアップロードする署名付きPUT URLを発行する
+// Content-TypeとContent-Lengthを署名に含め、申告と異なるファイルをアップロードできないようにする
+func (c *S3Client) PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (PresignedRequest, error) {
+	req, err := c.presign.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(c.Bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return PresignedRequest{}, err
+	}
+
+	headers := map[string]string{
+		"Content-Type": contentType,
+	}
+	return PresignedRequest{
+		URL:       req.URL,
+		Method:    req.Method,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// ObjectURL - オブジェクトを表示するURL（公開URLのベースがなければ署名付きGET URL）
+func (c *S3Client) ObjectURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if c.publicBaseURL != "" {
+		return c.publicBaseURL + "/" + key, nil
+	}
+	req, err := c.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// ErrObjectNotFound - オブジェクトがアップロードされていない
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo - アップロードされたオブジェクトのメタデータ
+type ObjectInfo struct {
+	ContentType string
+	Size        int64
+}
+
+// HeadObject - オブジェクトのメタデータを取得する
+func (c *S3Client) HeadObject(ctx context.Context, key string) (ObjectInfo, error) {
+	result, err := c.Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *s3types.NotFound
+	if errors.As(err, &notFound) {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		ContentType: aws.ToString(result.ContentType),
+		Size:        aws.ToInt64(result.ContentLength),
+	}, nil
+}
+
+// ReadObjectHead - オブジェクトの先頭nバイトを読み込む（ファイル形式の判定用）
+func (c *S3Client) ReadObjectHead(ctx context.Context, key string, n int64) ([]byte, error) {
+	result, err := c.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+		Range:  aws.String("bytes=0-" + strconv.FormatInt(n-1, 10)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+	return io.ReadAll(io.LimitReader(result.Body, n))
+}
+
+// GetObject - オブジェクトを読み込む（maxBytesを超える部分は読み込まない）
+func (c *S3Client) GetObject(ctx context.Context, key string, maxBytes int64) ([]byte, error) {
+	result, err := c.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+	return io.ReadAll(io.LimitReader(result.Body, maxBytes))
+}
+
+// PutObject - オブジェクトを保存する
+func (c *S3Client) PutObject(ctx context.Context, key string, contentType string, data []byte) error {
+	_, err := c.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.Bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(data),
+	})
+	return err
+}
+
+// DeleteObject - オブジェクトを削除する
+func (c *S3Client) DeleteObject(ctx context.Context, key string) error {
+	_, err := c.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
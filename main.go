@@ -7,14 +7,19 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 
 	openapi "user-backend/docs/gen/go"
 	app "user-backend/app"
+	geo "user-backend/geo"
 	infra "user-backend/infra"
+	textfilter "user-backend/textfilter"
 )
 
 // ResponseWriterラッパー
@@ -84,20 +89,220 @@ func proxyEventToHTTPRequest(req events.APIGatewayV2HTTPRequest) (*http.Request,
 	for k, v := range req.Headers {
 		httpReq.Header.Set(k, v)
 	}
+	// API GatewayのJWTオーソライザーが検証したクレームを渡す（管理APIの認可に使う）
+	// ヘッダーと異なりクライアントが偽装できないため、オーソライザーを通ったリクエストのみクレームを持つ
+	if authorizer := req.RequestContext.Authorizer; authorizer != nil && authorizer.JWT != nil {
+		httpReq = httpReq.WithContext(openapi.WithClaims(httpReq.Context(), authorizer.JWT.Claims))
+	}
+	// 送信元IPはAPI Gatewayが記録したものを使う（X-Forwarded-Forはクライアントが偽装できる）
+	httpReq.RemoteAddr = req.RequestContext.HTTP.SourceIP
+	// リクエストIDが指定されていなければAPI GatewayのリクエストIDを使う
+	if httpReq.Header.Get(openapi.RequestIDHeader) == "" && req.RequestContext.RequestID != "" {
+		httpReq.Header.Set(openapi.RequestIDHeader, req.RequestContext.RequestID)
+	}
 
 	return httpReq, nil
 }
 
-// Lambdaハンドラー
-func handler(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-	log.Printf("Incoming request: %s %s", req.RequestContext.HTTP.Method, req.RawPath)
+// サービス提供エリア（コールドスタート時に一度だけ読み込む）
+var serviceArea = loadServiceArea()
+
+// 環境変数SERVICE_AREA_GEOJSONで指定されたGeoJSONファイルからサービス提供エリアを読み込む
+// 未指定の場合はエリアを制限しない
+func loadServiceArea() geo.MultiPolygon {
+	path := os.Getenv("SERVICE_AREA_GEOJSON")
+	if path == "" {
+		return nil
+	}
+
+	area, err := geo.LoadMultiPolygonFile(path)
+	if err != nil {
+		log.Fatalf("failed to load service area: %v", err)
+	}
+	return area
+}
+
+// 区市町村の境界データ（コールドスタート時に一度だけ読み込む）
+var areaIndex = loadAreaIndex()
+
+// 環境変数AREA_GEOJSONで指定されたGeoJSONファイル、未指定の場合は同梱データから区市町村の境界を読み込む
+// 区市町村が1つもないデータでは起動しない（同梱データはイメージのビルド時に`make area-data`で生成する）
+func loadAreaIndex() *geo.AreaIndex {
+	areas, err := geo.LoadAreaIndex(os.Getenv("AREA_GEOJSON"))
+	if err != nil {
+		log.Fatalf("failed to load area data: %v", err)
+	}
+	return areas
+}
+
+// 受け付けるリアクションの種類（コールドスタート時に一度だけ読み込む）
+var reactionTypes = loadReactionTypes()
+
+// 環境変数REACTION_TYPES（カンマ区切り）から受け付けるリアクションの種類を読み込む
+// 未指定の場合はnilを返し、app.DefaultReactionTypesを使う
+func loadReactionTypes() []string {
+	value := os.Getenv("REACTION_TYPES")
+	if value == "" {
+		return nil
+	}
+	var reactionTypes []string
+	for _, reactionType := range strings.Split(value, ",") {
+		reactionType = strings.TrimSpace(reactionType)
+		if err := openapi.ValidateSchemaValue("ReactionType", "REACTION_TYPES", reactionType); err != nil {
+			log.Fatalf("invalid reaction type %q: %v", reactionType, err)
+		}
+		reactionTypes = append(reactionTypes, reactionType)
+	}
+	return reactionTypes
+}
+
+// 意見・コメントを自動で非表示にする通報数（コールドスタート時に一度だけ読み込む）
+var reportHideThreshold = loadReportHideThreshold()
+
+// 環境変数REPORT_HIDE_THRESHOLDから自動で非表示にする通報数を読み込む
+// 未指定の場合は0を返し、app.DefaultReportHideThresholdを使う
+func loadReportHideThreshold() int32 {
+	value := os.Getenv("REPORT_HIDE_THRESHOLD")
+	if value == "" {
+		return 0
+	}
+	threshold, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || threshold < 1 {
+		log.Fatalf("invalid REPORT_HIDE_THRESHOLD %q: must be a positive integer", value)
+	}
+	return int32(threshold)
+}
+
+// 区市町村別統計の集計結果のキャッシュ（コールドスタート時に作成し、インスタンス内のリクエストで共有する）
+var statsCache = app.NewStatsCache(loadStatsCacheTTL())
+
+// 環境変数STATS_CACHE_TTL（例: 10m）から集計結果を再利用する時間を読み込む
+// 未指定の場合はapp.DefaultStatsCacheTTL、0の場合はキャッシュしない
+func loadStatsCacheTTL() time.Duration {
+	value := os.Getenv("STATS_CACHE_TTL")
+	if value == "" {
+		return app.DefaultStatsCacheTTL
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		log.Fatalf("invalid STATS_CACHE_TTL %q: must be a non-negative duration", value)
+	}
+	return ttl
+}
 
+// 意見のモデレーションの方式（コールドスタート時に一度だけ読み込む）
+var moderationMode = loadModerationMode()
+
+// 環境変数MODERATION_MODEからモデレーションの方式を読み込む
+// post（投稿をすぐに公開する）・pre（モデレーターが承認した意見のみ公開する）から指定し、未指定の場合はpost
+func loadModerationMode() app.ModerationMode {
+	value := os.Getenv("MODERATION_MODE")
+	if value == "" {
+		return app.PostModeration
+	}
+	mode, err := app.ParseModerationMode(value)
+	if err != nil {
+		log.Fatalf("invalid MODERATION_MODE: %v", err)
+	}
+	return mode
+}
+
+// 意見・コメントの本文の禁止語・個人情報の検査（コールドスタート時に一度だけ読み込む）
+var contentFilter = loadContentFilter()
+
+// 環境変数NG_WORDS_FILEで指定された禁止語リスト、未指定の場合は同梱のリストから検査の設定を読み込む
+// 検出した場合の扱いはNG_WORD_ACTION（禁止語）・PII_ACTION（電話番号・メールアドレス）で
+// off・reject・mask・moderateから指定する（未指定の場合はreject）
+func loadContentFilter() *textfilter.Filter {
+	var opts []textfilter.Option
+	for _, setting := range []struct {
+		name   string
+		option func(textfilter.Action) textfilter.Option
+	}{
+		{"NG_WORD_ACTION", textfilter.WithWordAction},
+		{"PII_ACTION", textfilter.WithPIIAction},
+	} {
+		value := os.Getenv(setting.name)
+		if value == "" {
+			continue
+		}
+		action, err := textfilter.ParseAction(value)
+		if err != nil {
+			log.Fatalf("invalid %s: %v", setting.name, err)
+		}
+		opts = append(opts, setting.option(action))
+	}
+
+	filter, err := textfilter.Load(os.Getenv("NG_WORDS_FILE"), opts...)
+	if err != nil {
+		log.Fatalf("failed to load content filter: %v", err)
+	}
+	return filter
+}
+
+// 管理APIを呼び出せるロール（コールドスタート時に一度だけ読み込む）
+// 環境変数ADMIN_ROLE_CLAIM（ロールを持つJWTのクレーム）・ADMIN_ROLE（必要なロール）で変更できる
+var adminRoleClaim, adminRole = loadAdminRole()
+
+func loadAdminRole() (string, string) {
+	claim, role := os.Getenv("ADMIN_ROLE_CLAIM"), os.Getenv("ADMIN_ROLE")
+	if claim == "" {
+		claim = openapi.DefaultAdminRoleClaim
+	}
+	if role == "" {
+		role = openapi.DefaultAdminRole
+	}
+	return claim, role
+}
+
+// レート制限を有効にするかどうか（コールドスタート時に一度だけ読み込む）
+// 環境変数RATE_LIMITがoffの場合は無効にする（ローカルでの開発・負荷試験用）
+var rateLimitEnabled = os.Getenv("RATE_LIMIT") != "off"
+
+// OpenAPIで生成されたrouterを作成
+func newRouter() http.Handler {
 	// DynamoDB接続
 	dbClient := infra.ConnectDynamoDBService()
-	// OpenAPIで生成されたrouterを作成
-	opinionAPIService := app.NewOpinionService(dbClient)
+	opinionOptions := []app.OpinionServiceOption{
+		app.WithServiceArea(serviceArea),
+		app.WithAreaIndex(areaIndex),
+		app.WithContentFilter(contentFilter),
+		app.WithModerationMode(moderationMode),
+	}
+	if reactionTypes != nil {
+		opinionOptions = append(opinionOptions, app.WithReactionTypes(reactionTypes))
+	}
+	if reportHideThreshold != 0 {
+		opinionOptions = append(opinionOptions, app.WithReportHideThreshold(reportHideThreshold))
+	}
+	// 添付ファイル用のS3接続（ATTACHMENT_BUCKET未指定の場合は添付ファイルAPIを無効にする）
+	storage := infra.ConnectS3Service()
+	if storage != nil {
+		opinionOptions = append(opinionOptions, app.WithAttachmentStorage(storage))
+	}
+	opinionAPIService := app.NewOpinionService(dbClient, opinionOptions...)
 	opinionAPIController := openapi.NewOpinionAPIController(opinionAPIService)
-	router := openapi.NewRouter(opinionAPIController)
+	exportAPIService := app.NewExportService(dbClient)
+	exportAPIController := openapi.NewExportAPIController(exportAPIService)
+	statsAPIService := app.NewStatsService(dbClient, areaIndex, app.WithStatsCache(statsCache))
+	statsAPIController := openapi.NewStatsAPIController(statsAPIService)
+	adminAPIService := app.NewAdminService(dbClient, storage)
+	adminAPIController := openapi.NewAdminAPIController(adminAPIService, openapi.WithAdminAPIRole(adminRoleClaim, adminRole))
+	router := openapi.NewRouter(opinionAPIController, exportAPIController, statsAPIController, adminAPIController)
+	var handler http.Handler = router
+	if rateLimitEnabled {
+		// 投稿・リアクション・通報などはユーザーごと・送信元IPごとに回数を制限する
+		handler = openapi.RateLimiter(router, app.NewRateLimitStore(dbClient), openapi.DefaultRateLimits, openapi.DefaultErrorHandler)
+	}
+	// Idempotency-Keyを指定した書き込みの再送は最初のレスポンスを返す（再送はレート制限の対象にしない）
+	return openapi.Idempotency(handler, app.NewIdempotencyStore(dbClient), openapi.DefaultIdempotencyTTL, openapi.DefaultErrorHandler)
+}
+
+// Lambdaハンドラー
+func handler(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	log.Printf("Incoming request: %s %s", req.RequestContext.HTTP.Method, req.RawPath)
+
+	router := newRouter()
 
 	// Lambdaイベントをhttp.Requestに変換
 	httpReq, err := proxyEventToHTTPRequest(req)
@@ -136,5 +341,11 @@ func handler(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.AP
 
 
 func main() {
+	// Function URLのInvokeModeがRESPONSE_STREAMの場合はレスポンスをストリーミングする
+	// （エクスポートなど大きなレスポンスをproxyResponseWriterに溜め込まないため）
+	if os.Getenv("LAMBDA_INVOKE_MODE") == "RESPONSE_STREAM" {
+		lambda.Start(streamingHandler)
+		return
+	}
 	lambda.Start(handler)
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	openapi "user-backend/docs/gen/go"
+)
+
+// ストリーミング用ResponseWriter
+// ステータスコードとヘッダーが確定した時点でreadyを閉じ、以降のWriteはパイプ経由でLambdaへ流す
+type streamingResponseWriter struct {
+	headers    http.Header
+	pipe       *io.PipeWriter
+	statusCode int
+	// WriteHeader時点のヘッダーのコピー
+	sentHeaders map[string]string
+	ready       chan struct{}
+	once        sync.Once
+}
+
+func newStreamingResponseWriter(pipe *io.PipeWriter) *streamingResponseWriter {
+	return &streamingResponseWriter{
+		headers: make(http.Header),
+		pipe:    pipe,
+		ready:   make(chan struct{}),
+	}
+}
+
+func (w *streamingResponseWriter) Header() http.Header {
+	return w.headers
+}
+
+func (w *streamingResponseWriter) WriteHeader(statusCode int) {
+	w.once.Do(func() {
+		w.statusCode = statusCode
+		w.sentHeaders = make(map[string]string, len(w.headers))
+		for k, v := range w.headers {
+			w.sentHeaders[k] = strings.Join(v, ",")
+		}
+		close(w.ready)
+	})
+}
+
+func (w *streamingResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.pipe.Write(b)
+}
+
+// Flush - パイプは書き込みごとに読み手へ渡るため何もしない
+func (w *streamingResponseWriter) Flush() {}
+
+// streamingRequest - ストリーミング用のイベント
+// events.LambdaFunctionURLRequestのauthorizerはIAMの情報しか持たないため、
+// JWTオーソライザーが検証したクレームも受け取れるようにHTTP API v2と同じ形式のauthorizerで読み込む
+type streamingRequest struct {
+	events.LambdaFunctionURLRequest
+	RequestContext streamingRequestContext `json:"requestContext"`
+}
+
+type streamingRequestContext struct {
+	events.LambdaFunctionURLRequestContext
+	Authorizer *events.APIGatewayV2HTTPRequestContextAuthorizerDescription `json:"authorizer,omitempty"`
+}
+
+// Function URL(RESPONSE_STREAM)用Lambdaハンドラー
+func streamingHandler(ctx context.Context, req streamingRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	log.Printf("Incoming streaming request: %s %s", req.RequestContext.HTTP.Method, req.RawPath)
+
+	router := newRouter()
+
+	// Function URLのイベントはHTTP API v2と同じ形式のため、共通の変換処理を使う
+	httpReq, err := proxyEventToHTTPRequest(events.APIGatewayV2HTTPRequest{
+		RawPath:        req.RawPath,
+		RawQueryString: req.RawQueryString,
+		Headers:        req.Headers,
+		Body:           req.Body,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RequestID:  req.RequestContext.RequestID,
+			Authorizer: req.RequestContext.Authorizer,
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:   req.RequestContext.HTTP.Method,
+				SourceIP: req.RequestContext.HTTP.SourceIP,
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to convert event to http.Request: %v", err)
+		return &events.LambdaFunctionURLStreamingResponse{StatusCode: 500}, err
+	}
+	// Lambdaのコンテキストに差し替える（変換時に設定したクレームは引き継ぐ）
+	if claims := openapi.ClaimsFromContext(httpReq.Context()); claims != nil {
+		ctx = openapi.WithClaims(ctx, claims)
+	}
+	httpReq = httpReq.WithContext(ctx)
+
+	pr, pw := io.Pipe()
+	respWriter := newStreamingResponseWriter(pw)
+
+	go func() {
+		router.ServeHTTP(respWriter, httpReq)
+		// ボディを書かずに終了した場合もステータスを確定させる
+		respWriter.WriteHeader(http.StatusOK)
+		pw.Close()
+	}()
+
+	// ステータスコードとヘッダーが確定するまで待つ
+	<-respWriter.ready
+
+	log.Printf("Response status: %d", respWriter.statusCode)
+
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: respWriter.statusCode,
+		Headers:    respWriter.sentHeaders,
+		Body:       pr,
+	}, nil
+}
@@ -0,0 +1,315 @@
+// Package textfilter は投稿本文に含まれる禁止語と個人情報（電話番号・メールアドレス）を検出します。
+package textfilter
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultWords - 同梱の禁止語リスト（日本語・英語）
+//
+//go:embed data/ng_words.txt
+var defaultWords []byte
+
+// Kind - 検出した内容の種類
+type Kind string
+
+const (
+	KindNGWord      Kind = "ng_word"
+	KindPhoneNumber Kind = "phone_number"
+	KindMailAddress Kind = "mail_address"
+)
+
+// Action - 検出した場合の扱い
+type Action string
+
+const (
+	ActionOff      Action = "off"      // 検出しない
+	ActionReject   Action = "reject"   // 投稿を受け付けない
+	ActionMask     Action = "mask"     // 伏せ字にして保存する
+	ActionModerate Action = "moderate" // 保存して非表示にし、モデレーターの確認を待つ
+)
+
+// ParseAction - 文字列をActionに変換する
+func ParseAction(value string) (Action, error) {
+	switch action := Action(value); action {
+	case ActionOff, ActionReject, ActionMask, ActionModerate:
+		return action, nil
+	}
+	return "", fmt.Errorf("unknown action %q (must be off, reject, mask or moderate)", value)
+}
+
+// Match - 検出した箇所（位置は元の文字列での文字単位の位置で、Endは含まない）
+type Match struct {
+	Kind  Kind
+	Start int
+	End   int
+	Text  string
+}
+
+// Result - 検査の結果
+type Result struct {
+	Text      string  // ActionMaskの箇所を伏せ字にした本文
+	Rejected  []Match // ActionRejectの箇所
+	Moderated []Match // ActionModerateの箇所
+}
+
+// maskRune - 伏せ字に使う文字
+const maskRune = '*'
+
+var (
+	// mailAddressPattern - 正規化した本文に含まれるメールアドレス
+	mailAddressPattern = regexp.MustCompile(`[a-z0-9._%+\-]+@[a-z0-9\-]+(\.[a-z0-9\-]+)*\.[a-z]{2,}`)
+	// phoneNumberPattern - 正規化した本文に含まれる電話番号の候補（桁数はisPhoneNumberで確認する）
+	phoneNumberPattern = regexp.MustCompile(`(\+81|0)[ \-‐−ー―(]{0,2}\d{1,4}([ \-‐−ー―()]{0,2}\d{1,4}){1,3}`)
+)
+
+// word - 正規化した禁止語
+// 英数字のみの語は単語の途中に一致させない（classのassなど）。
+// それ以外の語は空白・記号を挟んで書かれても一致させる
+type word struct {
+	runes        []rune
+	wordBoundary bool
+}
+
+// Filter - 禁止語と個人情報の検出器
+type Filter struct {
+	words      []word
+	wordAction Action
+	piiAction  Action
+}
+
+// Option - Filterの設定
+type Option func(*Filter)
+
+// WithWordAction - 禁止語を検出した場合の扱いを設定する（既定はActionReject）
+func WithWordAction(action Action) Option {
+	return func(f *Filter) {
+		f.wordAction = action
+	}
+}
+
+// WithPIIAction - 電話番号・メールアドレスを検出した場合の扱いを設定する（既定はActionReject）
+func WithPIIAction(action Action) Option {
+	return func(f *Filter) {
+		f.piiAction = action
+	}
+}
+
+// New - 禁止語のリストから検出器を作成する（表記の揺れは正規化して照合するため、語は1つの表記で指定すればよい）
+func New(words []string, opts ...Option) *Filter {
+	f := &Filter{wordAction: ActionReject, piiAction: ActionReject}
+	for _, opt := range opts {
+		opt(f)
+	}
+	for _, w := range words {
+		runes := compact(normalize(w)).runes
+		if len(runes) == 0 {
+			continue
+		}
+		f.words = append(f.words, word{runes: runes, wordBoundary: isAlphanumeric(runes)})
+	}
+	return f
+}
+
+// Load - pathが指定されていればその禁止語リスト、空の場合は同梱のリストから検出器を作成する
+func Load(path string, opts ...Option) (*Filter, error) {
+	data := defaultWords
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ng word list: %w", err)
+		}
+	}
+	return New(ParseWords(data), opts...), nil
+}
+
+// ParseWords - 1行に1語の禁止語リストを読み込む（空行と#から始まる行は無視する）
+func ParseWords(data []byte) []string {
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words
+}
+
+// Check - 本文を検査し、設定に応じて伏せ字にした本文と、拒否・確認待ちにする箇所を返す
+func (f *Filter) Check(text string) Result {
+	result := Result{Text: text}
+	matches := f.Find(text)
+	masked := []rune(text)
+	isMasked := false
+	for _, m := range matches {
+		action := f.piiAction
+		if m.Kind == KindNGWord {
+			action = f.wordAction
+		}
+		switch action {
+		case ActionReject:
+			result.Rejected = append(result.Rejected, m)
+		case ActionModerate:
+			result.Moderated = append(result.Moderated, m)
+		case ActionMask:
+			for i := m.Start; i < m.End; i++ {
+				if !unicode.IsSpace(masked[i]) {
+					masked[i] = maskRune
+				}
+			}
+			isMasked = true
+		}
+	}
+	if isMasked {
+		result.Text = string(masked)
+	}
+	return result
+}
+
+// Find - 本文に含まれる禁止語・電話番号・メールアドレスを位置の順に返す（重なる箇所は先に始まるものを優先する）
+// ActionOffの種類は検出しない
+func (f *Filter) Find(text string) []Match {
+	n := normalize(text)
+	var matches []Match
+	if f.wordAction != ActionOff {
+		matches = append(matches, f.findWords(n)...)
+	}
+	if f.piiAction != ActionOff {
+		matches = append(matches, findPattern(n, mailAddressPattern, KindMailAddress, nil)...)
+		matches = append(matches, findPattern(n, phoneNumberPattern, KindPhoneNumber, isPhoneNumber)...)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Start != matches[j].Start {
+			return matches[i].Start < matches[j].Start
+		}
+		return matches[i].End > matches[j].End
+	})
+	source := []rune(text)
+	result := make([]Match, 0, len(matches))
+	end := 0
+	for _, m := range matches {
+		if m.Start < end {
+			continue
+		}
+		m.Text = string(source[m.Start:m.End])
+		result = append(result, m)
+		end = m.End
+	}
+	return result
+}
+
+// findWords - 禁止語を検出する
+func (f *Filter) findWords(n normalizedText) []Match {
+	var matches []Match
+	c := compact(n)
+	for _, w := range f.words {
+		target := c
+		if w.wordBoundary {
+			target = n
+		}
+		for i := 0; i+len(w.runes) <= len(target.runes); i++ {
+			if !hasPrefix(target.runes[i:], w.runes) {
+				continue
+			}
+			end := i + len(w.runes)
+			if w.wordBoundary && (i > 0 && isWordRune(target.runes[i-1]) || end < len(target.runes) && isWordRune(target.runes[end])) {
+				continue
+			}
+			matches = append(matches, Match{Kind: KindNGWord, Start: target.start[i], End: target.end[end-1]})
+		}
+	}
+	return matches
+}
+
+// findPattern - 正規表現に一致する箇所を検出する（validが指定されていれば、validがtrueを返す箇所のみ）
+func findPattern(n normalizedText, pattern *regexp.Regexp, kind Kind, valid func(string) bool) []Match {
+	text := string(n.runes)
+	var matches []Match
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		if valid != nil && !valid(text[loc[0]:loc[1]]) {
+			continue
+		}
+		// 数字の途中から・途中までの一致は電話番号として扱わない
+		if kind == KindPhoneNumber && (isDigitBefore(text, loc[0]) || isDigitAfter(text, loc[1])) {
+			continue
+		}
+		start := utf8.RuneCountInString(text[:loc[0]])
+		end := start + utf8.RuneCountInString(text[loc[0]:loc[1]])
+		matches = append(matches, Match{Kind: kind, Start: n.start[start], End: n.end[end-1]})
+	}
+	return matches
+}
+
+// isPhoneNumber - 国内の電話番号の桁数（0から始まる10〜11桁、+81から始まる場合は国番号を除き9〜10桁）かどうか
+func isPhoneNumber(candidate string) bool {
+	digits := 0
+	for _, r := range candidate {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if strings.HasPrefix(candidate, "+81") {
+		digits -= 2
+		return digits >= 9 && digits <= 10
+	}
+	return digits >= 10 && digits <= 11
+}
+
+func isDigitBefore(text string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(text[:i])
+	return r >= '0' && r <= '9'
+}
+
+func isDigitAfter(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return r >= '0' && r <= '9'
+}
+
+// compact - 空白・記号を除いた文字列（禁止語の間に空白や記号を挟んだ表記を検出するため）
+func compact(n normalizedText) normalizedText {
+	c := normalizedText{}
+	for i, r := range n.runes {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
+		}
+		c.runes = append(c.runes, r)
+		c.start = append(c.start, n.start[i])
+		c.end = append(c.end, n.end[i])
+	}
+	return c
+}
+
+func hasPrefix(runes []rune, prefix []rune) bool {
+	for i, r := range prefix {
+		if runes[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(runes []rune) bool {
+	for _, r := range runes {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9'
+}
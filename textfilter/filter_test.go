@@ -0,0 +1,99 @@
+package textfilter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	filter := New([]string{"ばか", "ass", "死ね"})
+	tests := []struct {
+		name string
+		text string
+		want []Match
+	}{
+		{"no match", "公園を増やしてほしい", []Match{}},
+		{"ng word", "ばかな政策", []Match{{KindNGWord, 0, 2, "ばか"}}},
+		{"katakana", "バカな政策", []Match{{KindNGWord, 0, 2, "バカ"}}},
+		{"halfwidth kana with voiced mark", "ﾊﾞｶな政策", []Match{{KindNGWord, 0, 3, "ﾊﾞｶ"}}},
+		{"separated by spaces and symbols", "し・ね、死 ね", []Match{{KindNGWord, 4, 7, "死 ね"}}},
+		{"alphanumeric word inside another word", "first class", []Match{}},
+		{"alphanumeric word on its own", "you ASS!", []Match{{KindNGWord, 4, 7, "ASS"}}},
+		{"fullwidth alphanumeric word", "ＡＳＳ", []Match{{KindNGWord, 0, 3, "ＡＳＳ"}}},
+		{"mail address", "連絡はFoo.Bar@example.co.jpまで", []Match{{KindMailAddress, 3, 24, "Foo.Bar@example.co.jp"}}},
+		{"fullwidth mail address", "ａ＠ｂ．ｊｐ", []Match{{KindMailAddress, 0, 6, "ａ＠ｂ．ｊｐ"}}},
+		{"mobile phone number", "090-1234-5678に電話", []Match{{KindPhoneNumber, 0, 13, "090-1234-5678"}}},
+		{"landline with parentheses", "03(1234)5678", []Match{{KindPhoneNumber, 0, 12, "03(1234)5678"}}},
+		{"fullwidth phone number", "０３１２３４５６７８", []Match{{KindPhoneNumber, 0, 10, "０３１２３４５６７８"}}},
+		{"international format", "+81 90 1234 5678", []Match{{KindPhoneNumber, 0, 16, "+81 90 1234 5678"}}},
+		{"too few digits", "0120-123", []Match{}},
+		{"inside a longer number", "1090-1234-5678", []Match{}},
+		{"multiple matches in order", "ばか 090-1234-5678", []Match{{KindNGWord, 0, 2, "ばか"}, {KindPhoneNumber, 3, 16, "090-1234-5678"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Find(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	const text = "ばか 090-1234-5678"
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantText      string
+		wantRejected  int
+		wantModerated int
+	}{
+		{"reject by default", nil, text, 2, 0},
+		{"mask words", []Option{WithWordAction(ActionMask)}, "** 090-1234-5678", 1, 0},
+		{"mask everything", []Option{WithWordAction(ActionMask), WithPIIAction(ActionMask)}, "** *************", 0, 0},
+		{"moderate personal information", []Option{WithPIIAction(ActionModerate)}, text, 1, 1},
+		{"off", []Option{WithWordAction(ActionOff), WithPIIAction(ActionOff)}, text, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := New([]string{"ばか"}, tt.opts...).Check(text)
+			if result.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", result.Text, tt.wantText)
+			}
+			if len(result.Rejected) != tt.wantRejected || len(result.Moderated) != tt.wantModerated {
+				t.Errorf("Rejected = %v, Moderated = %v", result.Rejected, result.Moderated)
+			}
+		})
+	}
+}
+
+func TestParseWords(t *testing.T) {
+	got := ParseWords([]byte("# comment\n\n  ばか  \nass\n"))
+	if want := []string{"ばか", "ass"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseWords() = %v, want %v", got, want)
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	for _, value := range []string{"off", "reject", "mask", "moderate"} {
+		if _, err := ParseAction(value); err != nil {
+			t.Errorf("ParseAction(%q) error = %v", value, err)
+		}
+	}
+	if _, err := ParseAction("block"); err == nil {
+		t.Error("ParseAction(block) succeeded")
+	}
+}
+
+func TestLoadBundledWords(t *testing.T) {
+	filter, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.words) == 0 {
+		t.Fatal("bundled ng word list is empty")
+	}
+	if got := filter.Find("クタバレ"); len(got) != 1 || got[0].Kind != KindNGWord {
+		t.Errorf("Find(クタバレ) = %v, want a bundled ng word", got)
+	}
+}
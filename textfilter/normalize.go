@@ -0,0 +1,91 @@
+package textfilter
+
+import "unicode"
+
+// normalizedText - 照合用に正規化した文字列と、各文字に対応する元の文字列の位置（文字単位）
+// 半角カナと濁点のように元の2文字が1文字になる場合があるため、開始位置と終了位置を持つ
+type normalizedText struct {
+	runes []rune
+	start []int
+	end   []int
+}
+
+// halfwidthKana - 半角カナ（U+FF61〜U+FF9D）に対応する全角の文字
+var halfwidthKana = []rune("。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+// normalize - 全角英数字・記号を半角に、半角カナを全角に、カタカナをひらがなにそろえ、英字を小文字にする
+// 濁点・半濁点（半角・全角・結合文字）は直前のかなと合わせて1文字にする
+func normalize(text string) normalizedText {
+	source := []rune(text)
+	n := normalizedText{
+		runes: make([]rune, 0, len(source)),
+		start: make([]int, 0, len(source)),
+		end:   make([]int, 0, len(source)),
+	}
+	for i, r := range source {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E: // 全角英数字・記号
+			r -= 0xFEE0
+		case r == 0x3000: // 全角スペース
+			r = ' '
+		case r >= 0xFF61 && r <= 0xFF9D: // 半角カナ
+			r = halfwidthKana[r-0xFF61]
+		}
+
+		if mark := soundMark(r); mark != 0 && len(n.runes) > 0 {
+			last := len(n.runes) - 1
+			if voiced, ok := voice(n.runes[last], mark); ok {
+				n.runes[last] = voiced
+				n.end[last] = i + 1
+				continue
+			}
+		}
+
+		// 照合は元の表記（カタカナ・ひらがな、大文字・小文字）によらない
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		n.runes = append(n.runes, unicode.ToLower(r))
+		n.start = append(n.start, i)
+		n.end = append(n.end, i+1)
+	}
+	return n
+}
+
+// soundMark - 濁点なら'゛'、半濁点なら'゜'、それ以外は0を返す
+func soundMark(r rune) rune {
+	switch r {
+	case 0xFF9E, 0x3099, '゛':
+		return '゛'
+	case 0xFF9F, 0x309A, '゜':
+		return '゜'
+	}
+	return 0
+}
+
+// voice - かなに濁点・半濁点を付けた文字を返す（付けられない場合はfalse）
+func voice(r rune, mark rune) (rune, bool) {
+	if r == 'ウ' && mark == '゛' {
+		return 'ヴ', true
+	}
+	if r == 'う' && mark == '゛' {
+		return 'ゔ', true
+	}
+	// カタカナはひらがなにそろえてから判定する（ひらがな・カタカナで濁音の並びは同じ）
+	offset := rune(0)
+	if r >= 'ァ' && r <= 'ヶ' {
+		offset = 'ァ' - 'ぁ'
+	}
+	base := r - offset
+	switch {
+	case mark == '゛' && base >= 'か' && base <= 'ぢ' && (base-'か')%2 == 0,
+		mark == '゛' && base >= 'つ' && base <= 'と' && (base-'つ')%2 == 0:
+		return r + 1, true
+	case base >= 'は' && base <= 'ほ' && (base-'は')%3 == 0:
+		if mark == '゛' {
+			return r + 1, true
+		}
+		return r + 2, true
+	}
+	return 0, false
+}
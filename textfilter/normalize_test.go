@@ -0,0 +1,69 @@
+package textfilter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		want      string
+		wantStart []int
+		wantEnd   []int
+	}{
+		{"fullwidth alphanumerics", "ＡＢＣ１２３", "abc123", []int{0, 1, 2, 3, 4, 5}, []int{1, 2, 3, 4, 5, 6}},
+		{"fullwidth space and symbols", "Ａ　＠！", "a @!", []int{0, 1, 2, 3}, []int{1, 2, 3, 4}},
+		{"katakana to hiragana", "カタカナ", "かたかな", []int{0, 1, 2, 3}, []int{1, 2, 3, 4}},
+		{"halfwidth kana", "ｶﾀｶﾅ", "かたかな", []int{0, 1, 2, 3}, []int{1, 2, 3, 4}},
+		{"halfwidth voiced mark", "ｶﾞｷﾞ", "がぎ", []int{0, 2}, []int{2, 4}},
+		{"halfwidth semi-voiced mark", "ﾊﾟﾋﾟ", "ぱぴ", []int{0, 2}, []int{2, 4}},
+		{"combining voiced mark", "か\u3099", "が", []int{0}, []int{2}},
+		{"standalone voiced mark", "は゛", "ば", []int{0}, []int{2}},
+		{"vu", "ｳﾞ", "ゔ", []int{0}, []int{2}},
+		{"voiced mark that cannot be combined", "あ゛", "あ゛", []int{0, 1}, []int{1, 2}},
+		{"leading voiced mark", "゛か", "゛か", []int{0, 1}, []int{1, 2}},
+		{"uppercase", "Hello", "hello", []int{0, 1, 2, 3, 4}, []int{1, 2, 3, 4, 5}},
+		{"empty", "", "", []int{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := normalize(tt.text)
+			if got := string(n.runes); got != tt.want {
+				t.Errorf("normalize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if !reflect.DeepEqual(n.start, tt.wantStart) || !reflect.DeepEqual(n.end, tt.wantEnd) {
+				t.Errorf("offsets = %v %v, want %v %v", n.start, n.end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestVoice(t *testing.T) {
+	tests := []struct {
+		r    rune
+		mark rune
+		want rune
+		ok   bool
+	}{
+		{'か', '゛', 'が', true},
+		{'ち', '゛', 'ぢ', true},
+		{'つ', '゛', 'づ', true},
+		{'と', '゛', 'ど', true},
+		{'は', '゛', 'ば', true},
+		{'ほ', '゜', 'ぽ', true},
+		{'ハ', '゜', 'パ', true},
+		{'ウ', '゛', 'ヴ', true},
+		{'が', '゛', 0, false},
+		{'か', '゜', 0, false},
+		{'な', '゛', 0, false},
+		{'a', '゛', 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := voice(tt.r, tt.mark)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("voice(%q, %q) = %q, %v, want %q, %v", tt.r, tt.mark, got, ok, tt.want, tt.ok)
+		}
+	}
+}
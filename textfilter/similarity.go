@@ -0,0 +1,31 @@
+package textfilter
+
+// Similarity - 2つの本文の類似度（0〜1）を文字のバイグラムのJaccard係数で求める
+// 禁止語の照合と同じく表記の揺れをそろえ、空白・記号を除いてから比較する
+func Similarity(a, b string) float64 {
+	x, y := bigrams(a), bigrams(b)
+	if len(x) == 0 || len(y) == 0 {
+		// 1文字以下の本文は正規化した文字列が一致する場合のみ同じとみなす
+		if string(compact(normalize(a)).runes) == string(compact(normalize(b)).runes) {
+			return 1
+		}
+		return 0
+	}
+	shared := 0
+	for bigram := range x {
+		if y[bigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(x)+len(y)-shared)
+}
+
+// bigrams - 正規化した本文の連続する2文字の集合
+func bigrams(text string) map[[2]rune]bool {
+	runes := compact(normalize(text)).runes
+	set := make(map[[2]rune]bool, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		set[[2]rune{runes[i], runes[i+1]}] = true
+	}
+	return set
+}
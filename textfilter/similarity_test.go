@@ -0,0 +1,35 @@
+package textfilter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "公園にベンチを増やしてほしい", "公園にベンチを増やしてほしい", 1},
+		{"notation differences are ignored", "ベンチを増やして！", "べんちを 増やして", 1},
+		{"fullwidth and case", "ＷｉＦｉがほしい", "wifiがほしい", 1},
+		{"nothing in common", "公園", "駅前", 0},
+		{"partial overlap", "abcd", "abce", 0.5}, // ab, bc が共通（ab bc cd / ab bc ce）
+		{"single characters that match", "あ", "ア", 1},
+		{"single characters that differ", "あ", "い", 0},
+		{"empty", "", "", 1},
+		{"empty and text", "", "公園", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if back := Similarity(tt.b, tt.a); back != got {
+				t.Errorf("Similarity is not symmetric: %v and %v", got, back)
+			}
+		})
+	}
+}